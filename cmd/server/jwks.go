@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/pkg/server"
+)
+
+// adminTokenEnv names the environment variable holding the access token used
+// when -token is not set.
+const adminTokenEnv = "MCP_ADMIN_TOKEN"
+
+// runJWKS implements the "jwks" subcommand. It asks a running server for
+// its per-issuer key set state through the /admin/jwks endpoint, so it shows
+// the keys that server trusts, and with -refresh has that server refetch the
+// key set of one issuer. The access token must carry the admin scope.
+// Returns the process exit code.
+func runJWKS(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("jwks", flag.ContinueOnError)
+	serverURL := flags.String("url", "", "URL of the running server (default: the origin of the configured base URL)")
+	token := flags.String("token", "", "access token with the admin scope (default: $"+adminTokenEnv+")")
+	issuer := flags.String("issuer", "", "only show this authorization server")
+	refresh := flags.Bool("refresh", false, "have the server refetch the key set of -issuer first")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	timeout := flags.Duration("timeout", 30*time.Second, "overall time limit for the request")
+	configFlags := server.RegisterFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// Keep stdout for the report
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	})))
+
+	if *refresh && *issuer == "" {
+		fmt.Fprintln(os.Stderr, "-refresh requires -issuer")
+		return 2
+	}
+	if *token == "" {
+		*token = os.Getenv(adminTokenEnv)
+	}
+	if *token == "" {
+		fmt.Fprintf(os.Stderr, "an access token with the admin scope is required: set -token or %s\n", adminTokenEnv)
+		return 2
+	}
+
+	if *serverURL == "" {
+		cfg, err := server.LoadConfigFlags(configFlags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+			return 1
+		}
+		*serverURL = origin(cfg.BaseURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	admin := &jwksAdminClient{baseURL: strings.TrimRight(*serverURL, "/"), token: *token}
+
+	var statuses []server.JWKSIssuerStatus
+	if *refresh {
+		status, err := admin.refresh(ctx, *issuer)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		statuses = []server.JWKSIssuerStatus{status}
+	} else {
+		all, err := admin.status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, status := range all {
+			if *issuer == "" || status.Issuer == *issuer {
+				statuses = append(statuses, status)
+			}
+		}
+		if *issuer != "" && len(statuses) == 0 {
+			fmt.Fprintf(os.Stderr, "%s is not a configured authorization server\n", *issuer)
+			return 2
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"issuers": statuses}); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode status: %v\n", err)
+			return 1
+		}
+	} else {
+		printJWKSStatus(stdout, statuses)
+	}
+
+	for _, status := range statuses {
+		if status.LastError != "" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// origin returns the scheme and host of rawURL, where the server mounts
+// its admin endpoints.
+func origin(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// jwksAdminClient calls the /admin/jwks endpoint of a running server.
+type jwksAdminClient struct {
+	baseURL string
+	token   string
+}
+
+// status lists the key set state of every authorization server.
+func (c *jwksAdminClient) status(ctx context.Context) ([]server.JWKSIssuerStatus, error) {
+	var body struct {
+		Issuers []server.JWKSIssuerStatus `json:"issuers"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/jwks", &body); err != nil {
+		return nil, err
+	}
+	return body.Issuers, nil
+}
+
+// refresh has the server refetch the key set of issuer and returns its
+// state afterwards. A failed fetch is reported in the state's LastError.
+func (c *jwksAdminClient) refresh(ctx context.Context, issuer string) (server.JWKSIssuerStatus, error) {
+	var body struct {
+		Issuer server.JWKSIssuerStatus `json:"issuer"`
+	}
+	err := c.do(ctx, http.MethodPost, "/admin/jwks?issuer="+url.QueryEscape(issuer), &body)
+	return body.Issuer, err
+}
+
+// do sends an authenticated request to path and decodes the JSON response
+// into v. A 502 from a refresh carries the issuer state and is decoded too.
+func (c *jwksAdminClient) do(ctx context.Context, method, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusBadGateway:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%s %s: %s: the token must be valid for the server and carry the admin scope", method, path, resp.Status)
+	default:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// printJWKSStatus writes a human-readable report of the issuer statuses.
+func printJWKSStatus(w io.Writer, statuses []server.JWKSIssuerStatus) {
+	for i, status := range statuses {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "issuer:      %s\n", status.Issuer)
+		fmt.Fprintf(w, "jwks_uri:    %s\n", orDash(status.JWKSURI))
+		fmt.Fprintf(w, "fetched_at:  %s\n", formatTime(status.FetchedAt))
+		fmt.Fprintf(w, "expires_at:  %s\n", formatTime(status.ExpiresAt))
+		if status.Stale {
+			fmt.Fprintln(w, "stale:       true")
+		}
+		if status.LastError != "" {
+			fmt.Fprintf(w, "last_error:  %s (%s)\n", status.LastError, formatTime(status.LastErrorAt))
+		}
+
+		if len(status.Keys) == 0 {
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  KID\tKTY\tALG\tSIZE\tUSE\tTRUSTED")
+		for _, key := range status.Keys {
+			trusted := "yes"
+			if !key.Trusted {
+				trusted = "no: " + key.Error
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\t%s\t%s\n",
+				orDash(key.KeyID), key.KeyType, orDash(key.Algorithm), key.Size, orDash(key.Use), trusted)
+		}
+		_ = tw.Flush()
+	}
+}
+
+// formatTime formats an optional timestamp for display.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// orDash substitutes "-" for empty values in the report.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
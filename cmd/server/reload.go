@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/pkg/server"
+)
+
+// configPollInterval is how often the configuration file is checked for changes.
+const configPollInterval = 2 * time.Second
+
+// watchConfig reloads the server configuration on SIGHUP and, when path is
+// set, whenever the configuration file changes. It returns when ctx is done.
+// Reload logs and counts rejected configurations, so errors are not
+// returned.
+func watchConfig(ctx context.Context, srv *server.Server, path string) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var poll <-chan time.Time
+	var last os.FileInfo
+	if path != "" {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+		last, _ = os.Stat(path)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			slog.Info("SIGHUP received, reloading configuration")
+			_ = srv.Reload(ctx)
+		case <-poll:
+			info, err := os.Stat(path)
+			if err != nil || !fileChanged(last, info) {
+				continue
+			}
+			last = info
+			slog.Info("configuration file changed, reloading configuration", "path", path)
+			_ = srv.Reload(ctx)
+		}
+	}
+}
+
+// fileChanged reports whether a file was modified between two stats.
+func fileChanged(before, after os.FileInfo) bool {
+	if before == nil {
+		return true
+	}
+	return !before.ModTime().Equal(after.ModTime()) || before.Size() != after.Size()
+}
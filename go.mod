@@ -2,4 +2,7 @@ module github.com/jamesprial/mcp-oauth-2.1
 
 go 1.23
 
-require github.com/golang-jwt/jwt/v5 v5.2.1
+require (
+	github.com/golang-jwt/jwt/v5 v5.2.1
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -1,10 +1,11 @@
 // Package config provides configuration management for the OAuth 2.1 MCP server.
-// Configuration is loaded from environment variables with sensible defaults.
+// Configuration is layered: defaults, then an optional YAML or JSON file, then
+// environment variables, then command-line flags.
 package config
 
 import (
 	"fmt"
-	"os"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -13,95 +14,284 @@ import (
 type Config struct {
 	// Server settings
 	// Addr is the address to bind the HTTP server (e.g., ":8080").
-	Addr string
+	Addr string `yaml:"addr"`
 
 	// BaseURL is the canonical base URL for this server (e.g., "https://example.com/mcp").
 	// This is used for OAuth audience validation and resource metadata.
-	BaseURL string
+	BaseURL string `yaml:"base_url"`
 
 	// ReadTimeout is the maximum duration for reading the entire request.
-	ReadTimeout time.Duration
+	ReadTimeout time.Duration `yaml:"read_timeout"`
 
 	// WriteTimeout is the maximum duration before timing out writes of the response.
-	WriteTimeout time.Duration
+	WriteTimeout time.Duration `yaml:"write_timeout"`
 
 	// IdleTimeout is the maximum duration to wait for the next request when keep-alives are enabled.
-	IdleTimeout time.Duration
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
 
 	// OAuth settings
 	// AuthorizationServers is a list of trusted authorization server URLs.
 	// These servers are listed in the protected resource metadata.
-	AuthorizationServers []string
+	AuthorizationServers []string `yaml:"authorization_servers"`
 
 	// Audience is the expected audience (aud) claim in access tokens.
 	// This should match the server's canonical URI.
-	Audience string
+	Audience string `yaml:"audience"`
 
 	// ScopesSupported is a list of OAuth scopes this server supports.
-	ScopesSupported []string
+	// Scopes declared by tools, resources and the auth middleware are
+	// advertised as well; this list adds scopes they do not declare.
+	ScopesSupported []string `yaml:"scopes_supported"`
 
 	// JWKSCacheTTL is how long to cache JWKS keys from authorization servers.
-	JWKSCacheTTL time.Duration
+	JWKSCacheTTL time.Duration `yaml:"jwks_cache_ttl"`
 
 	// ClockSkew is the allowed clock skew for token expiration validation.
-	ClockSkew time.Duration
+	ClockSkew time.Duration `yaml:"clock_skew"`
+
+	// JWKSX5CCAFile is the path to a PEM CA bundle used to verify x5c
+	// certificate chains on JWKS keys (optional). Setting it implies
+	// JWKSRequireX5C: keys without a chain cannot be verified and are dropped.
+	JWKSX5CCAFile string `yaml:"jwks_x5c_ca_file"`
+
+	// JWKSRequireX5C drops JWKS keys that do not carry an x5c certificate chain.
+	// It is implied by JWKSX5CCAFile.
+	JWKSRequireX5C bool `yaml:"jwks_require_x5c"`
+
+	// JWKSCacheFile is the path where the last good key sets are persisted so
+	// tokens can be validated immediately after a restart (optional).
+	JWKSCacheFile string `yaml:"jwks_cache_file"`
+
+	// JWKSCacheKey is the HMAC key protecting the persisted key sets (optional, secret).
+	JWKSCacheKey string `yaml:"jwks_cache_key"`
+
+	// JWKSCacheMaxStale is how long persisted keys remain usable after they were fetched.
+	JWKSCacheMaxStale time.Duration `yaml:"jwks_cache_max_stale"`
+
+	// OutboundHTTP configures the HTTP client used for authorization server
+	// discovery and JWKS fetches.
+	OutboundHTTP HTTPClientConfig `yaml:"outbound_http"`
+
+	// AuthorizationServerHTTP replaces OutboundHTTP for individual authorization
+	// servers, keyed by the server URL as listed in AuthorizationServers.
+	AuthorizationServerHTTP map[string]HTTPClientConfig `yaml:"authorization_server_http"`
+
+	// ResourceMetadata holds the optional RFC 9728 parameters advertised in
+	// the protected resource metadata document.
+	ResourceMetadata ResourceMetadataConfig `yaml:"resource_metadata"`
+
+	// ProtectedResources are additional protected resources hosted by this
+	// server, each served at the path of its identifier, accepting tokens
+	// issued for that identifier and described by its own metadata document.
+	ProtectedResources []ProtectedResourceConfig `yaml:"protected_resources"`
+
+	// DevServer configures the embedded development authorization server.
+	DevServer DevServerConfig `yaml:"dev_server"`
+
+	// ASMetadataPath is where the cached metadata of the trusted authorization
+	// servers is published (optional). Empty disables the endpoint.
+	ASMetadataPath string `yaml:"as_metadata_path"`
 
 	// MCP settings
-	// SessionTTL is the duration before an MCP session expires.
-	SessionTTL time.Duration
+	// SessionTTL is how long an MCP session may stay idle before it expires.
+	SessionTTL time.Duration `yaml:"session_ttl"`
+
+	// PageSize is the number of items returned per page by the MCP list
+	// methods.
+	PageSize int `yaml:"page_size"`
+}
+
+// HTTPClientConfig configures an outbound HTTP client.
+// Zero-valued numeric fields select the client's built-in defaults.
+type HTTPClientConfig struct {
+	// CAFile is a PEM CA bundle that replaces the system trust store (optional).
+	CAFile string `yaml:"ca_file"`
+
+	// ClientCertFile and ClientKeyFile are a PEM certificate and key presented
+	// for mutual TLS (optional, must be set together).
+	ClientCertFile string `yaml:"client_cert_file"`
+	ClientKeyFile  string `yaml:"client_key_file"`
+
+	// ProxyURL is the proxy to use. Empty honours HTTP_PROXY/HTTPS_PROXY,
+	// and "direct" disables proxying.
+	ProxyURL string `yaml:"proxy_url"`
+
+	// Timeout bounds each outbound request.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// MaxBodyBytes caps the size of a response body.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+
+	// MaxRedirects is the number of redirects to follow. Zero disables redirects.
+	MaxRedirects int `yaml:"max_redirects"`
+
+	// AllowPrivateNetworks permits requests to private, loopback and link-local addresses.
+	AllowPrivateNetworks bool `yaml:"allow_private_networks"`
+
+	// UserAgent is sent with every request.
+	UserAgent string `yaml:"user_agent"`
+}
+
+// ProtectedResourceConfig describes an additional protected resource hosted by
+// this server. Its endpoint only accepts tokens whose audience is Resource,
+// issued by its AuthorizationServers. Unset lists inherit the values of the
+// primary resource.
+type ProtectedResourceConfig struct {
+	// Resource is the resource identifier. It must share the origin of BaseURL
+	// and name the path the resource is served at.
+	Resource string `yaml:"resource"`
+
+	// AuthorizationServers lists the servers issuing tokens for this resource.
+	// Each must also be listed in AuthorizationServers.
+	AuthorizationServers []string `yaml:"authorization_servers"`
+
+	// ScopesSupported lists the scopes advertised for this resource.
+	ScopesSupported []string `yaml:"scopes_supported"`
+
+	// Name is the human-readable resource_name.
+	Name string `yaml:"resource_name"`
+}
+
+// DevServerConfig configures the embedded development authorization server.
+// It is meant for local testing only and requires a localhost SERVER_BASE_URL
+// and a loopback SERVER_ADDR.
+type DevServerConfig struct {
+	// Enabled turns the development authorization server on. Its issuer is
+	// added to AuthorizationServers.
+	Enabled bool `yaml:"enabled"`
+
+	// Path is where the server is mounted; the issuer is the origin of
+	// BaseURL followed by Path.
+	Path string `yaml:"path"`
+
+	// User is the subject every authorization request is approved for.
+	User string `yaml:"user"`
+
+	// TokenTTL is the lifetime of issued access tokens.
+	TokenTTL time.Duration `yaml:"token_ttl"`
+
+	// SigningKeyFile is a PEM or JWK private key that signs access tokens
+	// (optional). A new key is generated at every start when unset.
+	SigningKeyFile string `yaml:"signing_key_file"`
+}
+
+// ResourceMetadataConfig holds the optional RFC 9728 protected resource metadata parameters.
+type ResourceMetadataConfig struct {
+	// Name is the human-readable resource_name.
+	Name string `yaml:"resource_name"`
+
+	// DocumentationURL is the resource_documentation URL.
+	DocumentationURL string `yaml:"resource_documentation"`
+
+	// PolicyURI is the resource_policy_uri URL.
+	PolicyURI string `yaml:"resource_policy_uri"`
+
+	// TOSURI is the resource_tos_uri URL.
+	TOSURI string `yaml:"resource_tos_uri"`
+
+	// JWKSURI is the jwks_uri of the resource's own key set.
+	JWKSURI string `yaml:"jwks_uri"`
+
+	// SigningAlgValuesSupported is resource_signing_alg_values_supported.
+	SigningAlgValuesSupported []string `yaml:"resource_signing_alg_values_supported"`
+
+	// AuthorizationDetailsTypesSupported is authorization_details_types_supported.
+	AuthorizationDetailsTypesSupported []string `yaml:"authorization_details_types_supported"`
+
+	// TLSClientCertificateBoundAccessTokens is tls_client_certificate_bound_access_tokens.
+	TLSClientCertificateBoundAccessTokens bool `yaml:"tls_client_certificate_bound_access_tokens"`
+
+	// DPoPSigningAlgValuesSupported is dpop_signing_alg_values_supported.
+	DPoPSigningAlgValuesSupported []string `yaml:"dpop_signing_alg_values_supported"`
+
+	// DPoPBoundAccessTokensRequired is dpop_bound_access_tokens_required.
+	DPoPBoundAccessTokensRequired bool `yaml:"dpop_bound_access_tokens_required"`
+
+	// Extensions are additional top-level metadata parameters.
+	Extensions map[string]any `yaml:"extensions"`
+
+	// SigningKeyFile is a PEM or JWK private key used to sign the metadata
+	// into signed_metadata (optional).
+	SigningKeyFile string `yaml:"signing_key_file"`
+
+	// JWKSPath is where the resource serves its own public keys when a
+	// signing key is configured.
+	JWKSPath string `yaml:"jwks_path"`
+}
+
+// Default returns a configuration holding the defaults of every setting.
+// BaseURL, AuthorizationServers and Audience have no default and must be
+// set before the configuration validates.
+func Default() *Config {
+	return &Config{
+		Addr:              ":8080",
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		JWKSCacheTTL:      time.Hour,
+		ClockSkew:         time.Minute,
+		JWKSCacheMaxStale: 24 * time.Hour,
+		OutboundHTTP: HTTPClientConfig{
+			Timeout:      10 * time.Second,
+			MaxBodyBytes: 1 << 20,
+		},
+		ResourceMetadata: ResourceMetadataConfig{
+			JWKSPath: "/.well-known/jwks.json",
+		},
+		DevServer: DevServerConfig{
+			Path:     "/dev-as",
+			User:     "dev-user",
+			TokenTTL: time.Hour,
+		},
+		SessionTTL: time.Hour,
+		PageSize:   100,
+	}
 }
 
 // Load reads configuration from environment variables and returns a Config.
 // It sets default values for optional fields and validates the configuration.
 func Load() (*Config, error) {
-	// Parse durations with error handling
-	readTimeout, err := parseDurationWithDefault("SERVER_READ_TIMEOUT", "30s")
-	if err != nil {
-		return nil, fmt.Errorf("invalid SERVER_READ_TIMEOUT: %w", err)
-	}
+	return load("", nil)
+}
 
-	writeTimeout, err := parseDurationWithDefault("SERVER_WRITE_TIMEOUT", "30s")
-	if err != nil {
-		return nil, fmt.Errorf("invalid SERVER_WRITE_TIMEOUT: %w", err)
-	}
+// LoadFile reads configuration from a YAML or JSON file and environment
+// variables, which take precedence over the file.
+func LoadFile(path string) (*Config, error) {
+	return load(path, nil)
+}
 
-	idleTimeout, err := parseDurationWithDefault("SERVER_IDLE_TIMEOUT", "120s")
-	if err != nil {
-		return nil, fmt.Errorf("invalid SERVER_IDLE_TIMEOUT: %w", err)
+// LoadFlags reads configuration from the file named by the -config flag,
+// environment variables and command-line flags, each layer taking
+// precedence over the previous one.
+func LoadFlags(flags *Flags) (*Config, error) {
+	if flags == nil {
+		return load("", nil)
 	}
+	return load(flags.ConfigFile, flags)
+}
+
+// load layers the defaults, the configuration file, environment variables
+// and flags, then validates the merged result.
+func load(path string, flags *Flags) (*Config, error) {
+	cfg := Default()
 
-	jwksCacheTTL, err := parseDurationWithDefault("OAUTH_JWKS_CACHE_TTL", "1h")
-	if err != nil {
-		return nil, fmt.Errorf("invalid OAUTH_JWKS_CACHE_TTL: %w", err)
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
 	}
 
-	clockSkew, err := parseDurationWithDefault("OAUTH_CLOCK_SKEW", "1m")
-	if err != nil {
-		return nil, fmt.Errorf("invalid OAUTH_CLOCK_SKEW: %w", err)
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
 	}
 
-	sessionTTL, err := parseDurationWithDefault("MCP_SESSION_TTL", "1h")
-	if err != nil {
-		return nil, fmt.Errorf("invalid MCP_SESSION_TTL: %w", err)
+	if flags != nil {
+		flags.apply(cfg)
 	}
 
-	cfg := &Config{
-		// Server settings
-		Addr:         getEnvWithDefault("SERVER_ADDR", ":8080"),
-		BaseURL:      os.Getenv("SERVER_BASE_URL"),
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
-
-		// OAuth settings
-		AuthorizationServers: parseCommaSeparated("OAUTH_AUTHORIZATION_SERVERS"),
-		Audience:             os.Getenv("OAUTH_AUDIENCE"),
-		ScopesSupported:      parseCommaSeparated("OAUTH_SCOPES_SUPPORTED"),
-		JWKSCacheTTL:         jwksCacheTTL,
-		ClockSkew:            clockSkew,
-
-		// MCP settings
-		SessionTTL: sessionTTL,
+	// The development authorization server issues tokens like any trusted server
+	if issuer := cfg.DevServerIssuer(); issuer != "" && !contains(cfg.AuthorizationServers, issuer) {
+		cfg.AuthorizationServers = append(cfg.AuthorizationServers, issuer)
 	}
 
 	// Validate configuration
@@ -112,65 +302,46 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// getEnvWithDefault returns the environment variable value or the default if not set.
-func getEnvWithDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// DevServerIssuer returns the issuer of the development authorization server:
+// the origin of BaseURL followed by DevServer.Path. It returns an empty string
+// when the server is disabled or BaseURL is not an absolute URL.
+func (c *Config) DevServerIssuer() string {
+	if !c.DevServer.Enabled {
+		return ""
 	}
-	return defaultValue
-}
-
-// parseCommaSeparated parses a comma-separated environment variable into a string slice.
-// Empty values are filtered out. Returns nil if the environment variable is not set.
-func parseCommaSeparated(key string) []string {
-	value := os.Getenv(key)
-	if value == "" {
-		return nil
+	base, err := url.Parse(c.BaseURL)
+	if err != nil || !base.IsAbs() {
+		return ""
 	}
-
-	parts := strings.Split(value, ",")
-	var result []string
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			result = append(result, trimmed)
-		}
-	}
-
-	if len(result) == 0 {
-		return nil
-	}
-	return result
+	return base.Scheme + "://" + base.Host + strings.TrimRight(c.DevServer.Path, "/")
 }
 
-// parseDurationWithDefault parses a duration from an environment variable.
-// If the variable is not set, it uses the default value.
-// Returns an error if the value is set but cannot be parsed.
-func parseDurationWithDefault(key, defaultValue string) (time.Duration, error) {
-	value := os.Getenv(key)
-	if value == "" {
-		// Use default if not set
-		duration, err := time.ParseDuration(defaultValue)
-		if err != nil {
-			return 0, fmt.Errorf("invalid default duration %q: %w", defaultValue, err)
-		}
-		return duration, nil
+// ResourceJWKSURI returns the jwks_uri of the protected resource metadata:
+// ResourceMetadata.JWKSURI if set, else the origin of BaseURL followed by
+// ResourceMetadata.JWKSPath, where the key set is served. It returns an
+// empty string when neither applies.
+func (c *Config) ResourceJWKSURI() string {
+	if c.ResourceMetadata.JWKSURI != "" {
+		return c.ResourceMetadata.JWKSURI
 	}
-
-	// Parse the provided value
-	duration, err := time.ParseDuration(value)
-	if err != nil {
-		return 0, fmt.Errorf("cannot parse duration %q: %w", value, err)
+	base, err := url.Parse(c.BaseURL)
+	if err != nil || !base.IsAbs() {
+		return ""
 	}
-
-	return duration, nil
+	return base.Scheme + "://" + base.Host + c.ResourceMetadata.JWKSPath
 }
 
 // String returns a string representation of the configuration (for debugging).
 // Sensitive values are redacted.
 func (c *Config) String() string {
-	return fmt.Sprintf("Config{Addr: %s, BaseURL: %s, ReadTimeout: %v, WriteTimeout: %v, IdleTimeout: %v, AuthorizationServers: %v, Audience: %s, ScopesSupported: %v, JWKSCacheTTL: %v, ClockSkew: %v, SessionTTL: %v}",
+	cacheKey := ""
+	if c.JWKSCacheKey != "" {
+		cacheKey = "[REDACTED]"
+	}
+
+	return fmt.Sprintf("Config{Addr: %s, BaseURL: %s, ReadTimeout: %v, WriteTimeout: %v, IdleTimeout: %v, AuthorizationServers: %v, Audience: %s, ScopesSupported: %v, JWKSCacheTTL: %v, ClockSkew: %v, JWKSX5CCAFile: %s, JWKSRequireX5C: %v, JWKSCacheFile: %s, JWKSCacheKey: %s, JWKSCacheMaxStale: %v, SessionTTL: %v, PageSize: %d}",
 		c.Addr, c.BaseURL, c.ReadTimeout, c.WriteTimeout, c.IdleTimeout,
 		c.AuthorizationServers, c.Audience, c.ScopesSupported,
-		c.JWKSCacheTTL, c.ClockSkew, c.SessionTTL)
+		c.JWKSCacheTTL, c.ClockSkew, c.JWKSX5CCAFile, c.JWKSRequireX5C,
+		c.JWKSCacheFile, cacheKey, c.JWKSCacheMaxStale, c.SessionTTL, c.PageSize)
 }
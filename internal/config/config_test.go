@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -269,6 +270,81 @@ func TestLoad_AllTimeouts(t *testing.T) {
 	}
 }
 
+func TestLoad_JWKSX5C(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+	t.Setenv("OAUTH_JWKS_X5C_CA_FILE", "/etc/pki/corp-ca.pem")
+	t.Setenv("OAUTH_JWKS_REQUIRE_X5C", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if cfg.JWKSX5CCAFile != "/etc/pki/corp-ca.pem" {
+		t.Errorf("JWKSX5CCAFile = %q, want %q", cfg.JWKSX5CCAFile, "/etc/pki/corp-ca.pem")
+	}
+	if !cfg.JWKSRequireX5C {
+		t.Error("JWKSRequireX5C = false, want true")
+	}
+
+	t.Setenv("OAUTH_JWKS_REQUIRE_X5C", "sometimes")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_JWKS_REQUIRE_X5C") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_JWKS_REQUIRE_X5C", err)
+	}
+}
+
+func TestLoad_OutboundHTTP(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.OutboundHTTP.Timeout != 10*time.Second {
+		t.Errorf("OutboundHTTP.Timeout default = %v, want %v", cfg.OutboundHTTP.Timeout, 10*time.Second)
+	}
+	if cfg.OutboundHTTP.MaxBodyBytes != 1<<20 {
+		t.Errorf("OutboundHTTP.MaxBodyBytes default = %d, want %d", cfg.OutboundHTTP.MaxBodyBytes, 1<<20)
+	}
+	if cfg.OutboundHTTP.AllowPrivateNetworks {
+		t.Error("OutboundHTTP.AllowPrivateNetworks default = true, want false")
+	}
+
+	t.Setenv("OAUTH_HTTP_TIMEOUT", "3s")
+	t.Setenv("OAUTH_HTTP_MAX_BODY_BYTES", "65536")
+	t.Setenv("OAUTH_HTTP_MAX_REDIRECTS", "2")
+	t.Setenv("OAUTH_HTTP_ALLOW_PRIVATE_NETWORKS", "true")
+	t.Setenv("OAUTH_HTTP_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("OAUTH_HTTP_USER_AGENT", "corp-mcp/2.0")
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	want := HTTPClientConfig{
+		ProxyURL:             "http://proxy.example.com:3128",
+		Timeout:              3 * time.Second,
+		MaxBodyBytes:         65536,
+		MaxRedirects:         2,
+		AllowPrivateNetworks: true,
+		UserAgent:            "corp-mcp/2.0",
+	}
+	if cfg.OutboundHTTP != want {
+		t.Errorf("OutboundHTTP = %+v, want %+v", cfg.OutboundHTTP, want)
+	}
+
+	t.Setenv("OAUTH_HTTP_MAX_BODY_BYTES", "lots")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_HTTP_MAX_BODY_BYTES") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_HTTP_MAX_BODY_BYTES", err)
+	}
+}
+
 // clearConfigEnvVars clears all config-related environment variables
 func clearConfigEnvVars(t *testing.T) {
 	t.Helper()
@@ -280,12 +356,304 @@ func clearConfigEnvVars(t *testing.T) {
 		"SERVER_IDLE_TIMEOUT",
 		"OAUTH_AUTHORIZATION_SERVERS",
 		"OAUTH_AUDIENCE",
+		"OAUTH_JWKS_X5C_CA_FILE",
+		"OAUTH_JWKS_REQUIRE_X5C",
+		"OAUTH_JWKS_CACHE_FILE",
+		"OAUTH_JWKS_CACHE_KEY",
+		"OAUTH_JWKS_CACHE_MAX_STALE",
+		"OAUTH_HTTP_CA_FILE",
+		"OAUTH_HTTP_CLIENT_CERT_FILE",
+		"OAUTH_HTTP_CLIENT_KEY_FILE",
+		"OAUTH_HTTP_PROXY",
+		"OAUTH_HTTP_TIMEOUT",
+		"OAUTH_HTTP_MAX_BODY_BYTES",
+		"OAUTH_HTTP_MAX_REDIRECTS",
+		"OAUTH_HTTP_ALLOW_PRIVATE_NETWORKS",
+		"OAUTH_HTTP_USER_AGENT",
+		"OAUTH_RESOURCE_NAME",
+		"OAUTH_RESOURCE_DOCUMENTATION",
+		"OAUTH_RESOURCE_POLICY_URI",
+		"OAUTH_RESOURCE_TOS_URI",
+		"OAUTH_RESOURCE_JWKS_URI",
+		"OAUTH_RESOURCE_SIGNING_ALGS",
+		"OAUTH_AUTHORIZATION_DETAILS_TYPES",
+		"OAUTH_TLS_CLIENT_CERTIFICATE_BOUND_ACCESS_TOKENS",
+		"OAUTH_DPOP_SIGNING_ALGS",
+		"OAUTH_DPOP_BOUND_ACCESS_TOKENS_REQUIRED",
+		"OAUTH_RESOURCE_METADATA_EXTENSIONS",
+		"OAUTH_RESOURCE_SIGNING_KEY_FILE",
+		"OAUTH_RESOURCE_JWKS_PATH",
+		"OAUTH_PROTECTED_RESOURCES",
+		"OAUTH_AS_METADATA_PATH",
+		"OAUTH_DEV_SERVER",
+		"OAUTH_DEV_SERVER_PATH",
+		"OAUTH_DEV_SERVER_USER",
+		"OAUTH_DEV_SERVER_TOKEN_TTL",
+		"OAUTH_DEV_SERVER_SIGNING_KEY_FILE",
 	}
 	for _, env := range envVars {
 		t.Setenv(env, "")
 	}
 }
 
+func TestLoad_JWKSCachePersistence(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.JWKSCacheFile != "" {
+		t.Errorf("JWKSCacheFile default = %q, want empty", cfg.JWKSCacheFile)
+	}
+	if cfg.JWKSCacheMaxStale != 24*time.Hour {
+		t.Errorf("JWKSCacheMaxStale default = %v, want %v", cfg.JWKSCacheMaxStale, 24*time.Hour)
+	}
+
+	secret := "0123456789abcdef0123456789abcdef"
+	t.Setenv("OAUTH_JWKS_CACHE_FILE", "/var/lib/mcp/jwks.json")
+	t.Setenv("OAUTH_JWKS_CACHE_KEY", secret)
+	t.Setenv("OAUTH_JWKS_CACHE_MAX_STALE", "6h")
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.JWKSCacheFile != "/var/lib/mcp/jwks.json" {
+		t.Errorf("JWKSCacheFile = %q, want %q", cfg.JWKSCacheFile, "/var/lib/mcp/jwks.json")
+	}
+	if cfg.JWKSCacheKey != secret {
+		t.Errorf("JWKSCacheKey = %q, want %q", cfg.JWKSCacheKey, secret)
+	}
+	if cfg.JWKSCacheMaxStale != 6*time.Hour {
+		t.Errorf("JWKSCacheMaxStale = %v, want %v", cfg.JWKSCacheMaxStale, 6*time.Hour)
+	}
+	if containsString(cfg.String(), secret) {
+		t.Error("String() should redact JWKSCacheKey")
+	}
+
+	t.Setenv("OAUTH_JWKS_CACHE_MAX_STALE", "forever")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_JWKS_CACHE_MAX_STALE") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_JWKS_CACHE_MAX_STALE", err)
+	}
+}
+
+func TestLoad_ResourceMetadata(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+	t.Setenv("OAUTH_RESOURCE_NAME", "Example MCP")
+	t.Setenv("OAUTH_RESOURCE_DOCUMENTATION", "https://example.com/docs")
+	t.Setenv("OAUTH_RESOURCE_SIGNING_ALGS", "RS256, ES256")
+	t.Setenv("OAUTH_AUTHORIZATION_DETAILS_TYPES", "mcp_tool_invocation")
+	t.Setenv("OAUTH_TLS_CLIENT_CERTIFICATE_BOUND_ACCESS_TOKENS", "true")
+	t.Setenv("OAUTH_DPOP_SIGNING_ALGS", "ES256")
+	t.Setenv("OAUTH_DPOP_BOUND_ACCESS_TOKENS_REQUIRED", "true")
+	t.Setenv("OAUTH_RESOURCE_METADATA_EXTENSIONS", `{"x_region":"eu"}`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	rm := cfg.ResourceMetadata
+	if rm.Name != "Example MCP" {
+		t.Errorf("Name = %q, want %q", rm.Name, "Example MCP")
+	}
+	if rm.DocumentationURL != "https://example.com/docs" {
+		t.Errorf("DocumentationURL = %q, want %q", rm.DocumentationURL, "https://example.com/docs")
+	}
+	if len(rm.SigningAlgValuesSupported) != 2 || rm.SigningAlgValuesSupported[1] != "ES256" {
+		t.Errorf("SigningAlgValuesSupported = %v, want [RS256 ES256]", rm.SigningAlgValuesSupported)
+	}
+	if len(rm.AuthorizationDetailsTypesSupported) != 1 {
+		t.Errorf("AuthorizationDetailsTypesSupported = %v, want one type", rm.AuthorizationDetailsTypesSupported)
+	}
+	if !rm.TLSClientCertificateBoundAccessTokens {
+		t.Error("TLSClientCertificateBoundAccessTokens = false, want true")
+	}
+	if !rm.DPoPBoundAccessTokensRequired {
+		t.Error("DPoPBoundAccessTokensRequired = false, want true")
+	}
+	if rm.Extensions["x_region"] != "eu" {
+		t.Errorf("Extensions = %v, want x_region=eu", rm.Extensions)
+	}
+	if rm.SigningKeyFile != "" {
+		t.Errorf("SigningKeyFile default = %q, want empty", rm.SigningKeyFile)
+	}
+	if rm.JWKSPath != "/.well-known/jwks.json" {
+		t.Errorf("JWKSPath default = %q, want %q", rm.JWKSPath, "/.well-known/jwks.json")
+	}
+
+	t.Setenv("OAUTH_RESOURCE_SIGNING_KEY_FILE", "/etc/mcp/metadata-key.pem")
+	t.Setenv("OAUTH_RESOURCE_JWKS_PATH", "/keys")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.ResourceMetadata.SigningKeyFile != "/etc/mcp/metadata-key.pem" {
+		t.Errorf("SigningKeyFile = %q, want %q", cfg.ResourceMetadata.SigningKeyFile, "/etc/mcp/metadata-key.pem")
+	}
+	if cfg.ResourceMetadata.JWKSPath != "/keys" {
+		t.Errorf("JWKSPath = %q, want %q", cfg.ResourceMetadata.JWKSPath, "/keys")
+	}
+
+	// The key set is served at the root, whatever the resource's path
+	cfg.BaseURL = "https://example.com/mcp"
+	if uri := cfg.ResourceJWKSURI(); uri != "https://example.com/keys" {
+		t.Errorf("ResourceJWKSURI() = %q, want %q", uri, "https://example.com/keys")
+	}
+	cfg.ResourceMetadata.JWKSURI = "https://keys.example.com/jwks.json"
+	if uri := cfg.ResourceJWKSURI(); uri != "https://keys.example.com/jwks.json" {
+		t.Errorf("ResourceJWKSURI() = %q, want the configured jwks_uri", uri)
+	}
+
+	t.Setenv("OAUTH_RESOURCE_METADATA_EXTENSIONS", `["not", "an", "object"]`)
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_RESOURCE_METADATA_EXTENSIONS") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_RESOURCE_METADATA_EXTENSIONS", err)
+	}
+}
+
+func TestLoad_ASMetadataPath(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.ASMetadataPath != "" {
+		t.Errorf("ASMetadataPath default = %q, want empty (disabled)", cfg.ASMetadataPath)
+	}
+
+	t.Setenv("OAUTH_AS_METADATA_PATH", "/.well-known/oauth-authorization-servers")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.ASMetadataPath != "/.well-known/oauth-authorization-servers" {
+		t.Errorf("ASMetadataPath = %q, want %q", cfg.ASMetadataPath, "/.well-known/oauth-authorization-servers")
+	}
+
+	t.Setenv("OAUTH_AS_METADATA_PATH", "authorization-servers")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_AS_METADATA_PATH") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_AS_METADATA_PATH", err)
+	}
+}
+
+func TestLoad_DevServer(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "http://localhost:8080/mcp")
+	t.Setenv("OAUTH_AUDIENCE", "http://localhost:8080/mcp")
+
+	// Without the dev server an authorization server must be configured
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_AUTHORIZATION_SERVERS") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_AUTHORIZATION_SERVERS", err)
+	}
+
+	t.Setenv("OAUTH_DEV_SERVER", "true")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "SERVER_ADDR") {
+		t.Errorf("Load() error = %v, want error mentioning SERVER_ADDR", err)
+	}
+
+	t.Setenv("SERVER_ADDR", "127.0.0.1:8080")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	dev := cfg.DevServer
+	if !dev.Enabled || dev.Path != "/dev-as" || dev.User != "dev-user" || dev.TokenTTL != time.Hour {
+		t.Errorf("DevServer defaults = %+v", dev)
+	}
+	if issuer := cfg.DevServerIssuer(); issuer != "http://localhost:8080/dev-as" {
+		t.Errorf("DevServerIssuer() = %q, want %q", issuer, "http://localhost:8080/dev-as")
+	}
+	if len(cfg.AuthorizationServers) != 1 || cfg.AuthorizationServers[0] != "http://localhost:8080/dev-as" {
+		t.Errorf("AuthorizationServers = %v, want the dev server issuer", cfg.AuthorizationServers)
+	}
+
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_DEV_SERVER_PATH", "/local-as")
+	t.Setenv("OAUTH_DEV_SERVER_USER", "alice")
+	t.Setenv("OAUTH_DEV_SERVER_TOKEN_TTL", "5m")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	want := []string{"https://auth.example.com", "http://localhost:8080/local-as"}
+	if len(cfg.AuthorizationServers) != 2 || cfg.AuthorizationServers[0] != want[0] || cfg.AuthorizationServers[1] != want[1] {
+		t.Errorf("AuthorizationServers = %v, want %v", cfg.AuthorizationServers, want)
+	}
+	if cfg.DevServer.User != "alice" || cfg.DevServer.TokenTTL != 5*time.Minute {
+		t.Errorf("DevServer = %+v, want user alice and 5m TTL", cfg.DevServer)
+	}
+
+	t.Setenv("OAUTH_DEV_SERVER_TOKEN_TTL", "soon")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_DEV_SERVER_TOKEN_TTL") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_DEV_SERVER_TOKEN_TTL", err)
+	}
+}
+
+func TestLoad_ProtectedResources(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+	t.Setenv("OAUTH_PROTECTED_RESOURCES", `[
+		{"resource": "https://example.com/reports", "scopes_supported": ["reports:read"], "resource_name": "Reports"},
+		{"resource": "https://example.com/admin"}
+	]`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if len(cfg.ProtectedResources) != 2 {
+		t.Fatalf("ProtectedResources length = %d, want 2", len(cfg.ProtectedResources))
+	}
+	reports := cfg.ProtectedResources[0]
+	if reports.Resource != "https://example.com/reports" || reports.Name != "Reports" {
+		t.Errorf("ProtectedResources[0] = %+v, want reports resource", reports)
+	}
+	if len(reports.ScopesSupported) != 1 || reports.ScopesSupported[0] != "reports:read" {
+		t.Errorf("ProtectedResources[0].ScopesSupported = %v, want [reports:read]", reports.ScopesSupported)
+	}
+
+	t.Setenv("OAUTH_PROTECTED_RESOURCES", `{"resource": "https://example.com/reports"}`)
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_PROTECTED_RESOURCES") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_PROTECTED_RESOURCES", err)
+	}
+}
+
+func TestDefault_MatchesLoad(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := Default()
+	want.BaseURL = loaded.BaseURL
+	want.AuthorizationServers = loaded.AuthorizationServers
+	want.Audience = loaded.Audience
+
+	if !reflect.DeepEqual(want, loaded) {
+		t.Errorf("Default() = %+v, want the defaults of Load() %+v", want, loaded)
+	}
+}
+
 // containsString checks if s contains substr
 func containsString(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
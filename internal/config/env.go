@@ -0,0 +1,243 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyEnv overrides cfg with the environment variables that are set.
+func applyEnv(cfg *Config) error {
+	env := &envLoader{}
+
+	// Server settings
+	env.string("SERVER_ADDR", &cfg.Addr)
+	env.string("SERVER_BASE_URL", &cfg.BaseURL)
+	env.duration("SERVER_READ_TIMEOUT", &cfg.ReadTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &cfg.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &cfg.IdleTimeout)
+
+	// OAuth settings
+	env.list("OAUTH_AUTHORIZATION_SERVERS", &cfg.AuthorizationServers)
+	env.string("OAUTH_AUDIENCE", &cfg.Audience)
+	env.list("OAUTH_SCOPES_SUPPORTED", &cfg.ScopesSupported)
+	env.duration("OAUTH_JWKS_CACHE_TTL", &cfg.JWKSCacheTTL)
+	env.duration("OAUTH_CLOCK_SKEW", &cfg.ClockSkew)
+	env.string("OAUTH_JWKS_X5C_CA_FILE", &cfg.JWKSX5CCAFile)
+	env.bool("OAUTH_JWKS_REQUIRE_X5C", &cfg.JWKSRequireX5C)
+	env.string("OAUTH_JWKS_CACHE_FILE", &cfg.JWKSCacheFile)
+	env.string("OAUTH_JWKS_CACHE_KEY", &cfg.JWKSCacheKey)
+	env.duration("OAUTH_JWKS_CACHE_MAX_STALE", &cfg.JWKSCacheMaxStale)
+	env.string("OAUTH_AS_METADATA_PATH", &cfg.ASMetadataPath)
+
+	var protectedResources []ProtectedResourceConfig
+	if env.json("OAUTH_PROTECTED_RESOURCES", &protectedResources, "a JSON array of resources") {
+		cfg.ProtectedResources = protectedResources
+	}
+
+	applyHTTPClientEnv(env, &cfg.OutboundHTTP)
+	applyResourceMetadataEnv(env, &cfg.ResourceMetadata)
+	applyDevServerEnv(env, &cfg.DevServer)
+
+	// MCP settings
+	env.duration("MCP_SESSION_TTL", &cfg.SessionTTL)
+	env.int("MCP_PAGE_SIZE", &cfg.PageSize)
+
+	return env.err
+}
+
+// applyHTTPClientEnv reads the OAUTH_HTTP_* environment variables.
+func applyHTTPClientEnv(env *envLoader, c *HTTPClientConfig) {
+	env.string("OAUTH_HTTP_CA_FILE", &c.CAFile)
+	env.string("OAUTH_HTTP_CLIENT_CERT_FILE", &c.ClientCertFile)
+	env.string("OAUTH_HTTP_CLIENT_KEY_FILE", &c.ClientKeyFile)
+	env.string("OAUTH_HTTP_PROXY", &c.ProxyURL)
+	env.duration("OAUTH_HTTP_TIMEOUT", &c.Timeout)
+	env.int64("OAUTH_HTTP_MAX_BODY_BYTES", &c.MaxBodyBytes)
+	env.int("OAUTH_HTTP_MAX_REDIRECTS", &c.MaxRedirects)
+	env.bool("OAUTH_HTTP_ALLOW_PRIVATE_NETWORKS", &c.AllowPrivateNetworks)
+	env.string("OAUTH_HTTP_USER_AGENT", &c.UserAgent)
+}
+
+// applyResourceMetadataEnv reads the OAUTH_RESOURCE_* and related metadata
+// environment variables. OAUTH_RESOURCE_METADATA_EXTENSIONS is a JSON object.
+func applyResourceMetadataEnv(env *envLoader, c *ResourceMetadataConfig) {
+	env.string("OAUTH_RESOURCE_NAME", &c.Name)
+	env.string("OAUTH_RESOURCE_DOCUMENTATION", &c.DocumentationURL)
+	env.string("OAUTH_RESOURCE_POLICY_URI", &c.PolicyURI)
+	env.string("OAUTH_RESOURCE_TOS_URI", &c.TOSURI)
+	env.string("OAUTH_RESOURCE_JWKS_URI", &c.JWKSURI)
+	env.list("OAUTH_RESOURCE_SIGNING_ALGS", &c.SigningAlgValuesSupported)
+	env.list("OAUTH_AUTHORIZATION_DETAILS_TYPES", &c.AuthorizationDetailsTypesSupported)
+	env.bool("OAUTH_TLS_CLIENT_CERTIFICATE_BOUND_ACCESS_TOKENS", &c.TLSClientCertificateBoundAccessTokens)
+	env.list("OAUTH_DPOP_SIGNING_ALGS", &c.DPoPSigningAlgValuesSupported)
+	env.bool("OAUTH_DPOP_BOUND_ACCESS_TOKENS_REQUIRED", &c.DPoPBoundAccessTokensRequired)
+	env.string("OAUTH_RESOURCE_SIGNING_KEY_FILE", &c.SigningKeyFile)
+	env.string("OAUTH_RESOURCE_JWKS_PATH", &c.JWKSPath)
+
+	var extensions map[string]any
+	if env.json("OAUTH_RESOURCE_METADATA_EXTENSIONS", &extensions, "a JSON object") {
+		c.Extensions = extensions
+	}
+}
+
+// applyDevServerEnv reads the OAUTH_DEV_SERVER* environment variables.
+func applyDevServerEnv(env *envLoader, c *DevServerConfig) {
+	env.bool("OAUTH_DEV_SERVER", &c.Enabled)
+	env.string("OAUTH_DEV_SERVER_PATH", &c.Path)
+	env.string("OAUTH_DEV_SERVER_USER", &c.User)
+	env.duration("OAUTH_DEV_SERVER_TOKEN_TTL", &c.TokenTTL)
+	env.string("OAUTH_DEV_SERVER_SIGNING_KEY_FILE", &c.SigningKeyFile)
+}
+
+// envLoader parses environment variables into configuration fields. Unset
+// and empty variables leave a field unchanged. Every variable NAME can
+// instead be given as NAME_FILE, the path of a file holding the value, so
+// secrets need not be placed in the environment. After the first error,
+// further calls do nothing.
+type envLoader struct {
+	err error
+}
+
+// lookup returns the value of key, reading it from the file named by
+// key_FILE when that is set.
+func (l *envLoader) lookup(key string) (string, bool) {
+	if l.err != nil {
+		return "", false
+	}
+
+	value := os.Getenv(key)
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return value, value != ""
+	}
+	if value != "" {
+		l.err = fmt.Errorf("invalid %s: %s_FILE cannot be set as well", key, key)
+		return "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.err = fmt.Errorf("invalid %s_FILE: %w", key, err)
+		return "", false
+	}
+	value = strings.TrimRight(string(data), "\r\n")
+	return value, value != ""
+}
+
+// fail records a parse error for key.
+func (l *envLoader) fail(key string, err error) {
+	l.err = fmt.Errorf("invalid %s: %w", key, err)
+}
+
+// string sets target to the value of key.
+func (l *envLoader) string(key string, target *string) {
+	if value, ok := l.lookup(key); ok {
+		*target = value
+	}
+}
+
+// list sets target to the comma-separated values of key.
+func (l *envLoader) list(key string, target *[]string) {
+	if value, ok := l.lookup(key); ok {
+		*target = splitList(value)
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty values.
+// Returns nil if no value remains.
+func splitList(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// duration sets target to the duration value of key.
+func (l *envLoader) duration(key string, target *time.Duration) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		l.fail(key, fmt.Errorf("cannot parse duration %q: %w", value, err))
+		return
+	}
+	*target = duration
+}
+
+// bool sets target to the boolean value of key.
+func (l *envLoader) bool(key string, target *bool) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.fail(key, fmt.Errorf("cannot parse boolean %q: %w", value, err))
+		return
+	}
+	*target = parsed
+}
+
+// int64 sets target to the integer value of key.
+func (l *envLoader) int64(key string, target *int64) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		l.fail(key, fmt.Errorf("cannot parse integer %q: %w", value, err))
+		return
+	}
+	*target = parsed
+}
+
+// int sets target to the integer value of key.
+func (l *envLoader) int(key string, target *int) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		l.fail(key, fmt.Errorf("cannot parse integer %q: %w", value, err))
+		return
+	}
+	*target = parsed
+}
+
+// json decodes the JSON value of key into target, described as expected in
+// errors. It reports whether target was set. The value is decoded as YAML,
+// of which JSON is a subset, so fields are named by their yaml tags as in a
+// config file.
+func (l *envLoader) json(key string, target any, expected string) bool {
+	value, ok := l.lookup(key)
+	if !ok {
+		return false
+	}
+
+	if !json.Valid([]byte(value)) {
+		l.fail(key, fmt.Errorf("must be %s: invalid JSON", expected))
+		return false
+	}
+	if err := yaml.Unmarshal([]byte(value), target); err != nil {
+		l.fail(key, fmt.Errorf("must be %s: %w", expected, err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadFile layers a YAML or JSON configuration file over cfg. Keys are the
+// snake_case names in the yaml tags of Config and its nested types; unknown
+// keys are errors. Settings absent from the file keep their current value.
+//
+// ${NAME} in a value is replaced by the environment variable NAME, which
+// must be set; $$ stands for a literal $.
+func loadFile(path string, cfg *Config) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", ".json":
+		// JSON is a subset of YAML, so one decoder reads both
+	default:
+		return fmt.Errorf("unsupported config file format %q: use .yaml, .yml or .json", ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil // empty file
+	}
+
+	if err := interpolate(&root); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	// Decode the interpolated document strictly; yaml.Node.Decode cannot
+	// reject unknown keys
+	expanded, err := yaml.Marshal(&root)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(expanded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// envReference matches $$ and ${NAME} in configuration values.
+var envReference = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolate expands environment variable references in the scalar values
+// below node. Mapping keys are left unchanged.
+func interpolate(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		expanded, err := expandEnv(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if expanded != node.Value {
+			node.Value = expanded
+			// Let unquoted values resolve to the type of the expansion,
+			// e.g. a number or boolean
+			if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = ""
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolate(node.Content[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if err := interpolate(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandEnv replaces ${NAME} with the value of NAME and $$ with $.
+func expandEnv(value string) (string, error) {
+	var err error
+	expanded := envReference.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		name := match[2 : len(match)-1]
+		resolved, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved
+	})
+	return expanded, err
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a file named name in a temporary
+// directory and returns its path.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadFile_YAML(t *testing.T) {
+	clearConfigEnvVars(t)
+
+	path := writeConfigFile(t, "config.yaml", `
+addr: ":9090"
+base_url: https://example.com
+authorization_servers:
+  - https://auth.example.com
+  - https://login.example.com
+audience: https://example.com/mcp
+jwks_cache_ttl: 15m
+outbound_http:
+  timeout: 3s
+  user_agent: corp-mcp/2.0
+authorization_server_http:
+  https://login.example.com:
+    max_redirects: 1
+resource_metadata:
+  resource_name: Example
+protected_resources:
+  - resource: https://example.com/reports
+    scopes_supported: [reports:read]
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error: %v", err)
+	}
+
+	if cfg.Addr != ":9090" || cfg.BaseURL != "https://example.com" || cfg.Audience != "https://example.com/mcp" {
+		t.Errorf("server settings = %q %q %q", cfg.Addr, cfg.BaseURL, cfg.Audience)
+	}
+	if len(cfg.AuthorizationServers) != 2 || cfg.AuthorizationServers[1] != "https://login.example.com" {
+		t.Errorf("AuthorizationServers = %v", cfg.AuthorizationServers)
+	}
+	if cfg.JWKSCacheTTL != 15*time.Minute {
+		t.Errorf("JWKSCacheTTL = %v, want 15m", cfg.JWKSCacheTTL)
+	}
+	// Nested settings absent from the file keep their defaults
+	if cfg.OutboundHTTP.Timeout != 3*time.Second || cfg.OutboundHTTP.UserAgent != "corp-mcp/2.0" || cfg.OutboundHTTP.MaxBodyBytes != 1<<20 {
+		t.Errorf("OutboundHTTP = %+v", cfg.OutboundHTTP)
+	}
+	if cfg.ReadTimeout != 30*time.Second || cfg.ResourceMetadata.JWKSPath != "/.well-known/jwks.json" {
+		t.Errorf("defaults not preserved: ReadTimeout %v, JWKSPath %q", cfg.ReadTimeout, cfg.ResourceMetadata.JWKSPath)
+	}
+	if got := cfg.AuthorizationServerHTTP["https://login.example.com"].MaxRedirects; got != 1 {
+		t.Errorf("AuthorizationServerHTTP max_redirects = %d, want 1", got)
+	}
+	if cfg.ResourceMetadata.Name != "Example" {
+		t.Errorf("ResourceMetadata.Name = %q, want Example", cfg.ResourceMetadata.Name)
+	}
+	if len(cfg.ProtectedResources) != 1 || cfg.ProtectedResources[0].ScopesSupported[0] != "reports:read" {
+		t.Errorf("ProtectedResources = %+v", cfg.ProtectedResources)
+	}
+}
+
+func TestLoadFile_JSON(t *testing.T) {
+	clearConfigEnvVars(t)
+
+	path := writeConfigFile(t, "config.json", `{
+		"base_url": "https://example.com",
+		"authorization_servers": ["https://auth.example.com"],
+		"audience": "https://example.com/mcp",
+		"session_ttl": "10m",
+		"page_size": 25,
+		"dev_server": {"user": "alice"}
+	}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error: %v", err)
+	}
+	if cfg.SessionTTL != 10*time.Minute {
+		t.Errorf("SessionTTL = %v, want 10m", cfg.SessionTTL)
+	}
+	if cfg.PageSize != 25 {
+		t.Errorf("PageSize = %d, want 25", cfg.PageSize)
+	}
+	if cfg.DevServer.User != "alice" || cfg.DevServer.Path != "/dev-as" {
+		t.Errorf("DevServer = %+v, want user alice and the default path", cfg.DevServer)
+	}
+}
+
+func TestLoadFlags_Precedence(t *testing.T) {
+	clearConfigEnvVars(t)
+
+	path := writeConfigFile(t, "config.yaml", `
+addr: ":7000"
+base_url: https://file.example.com
+authorization_servers: [https://auth.example.com]
+audience: https://file.example.com/mcp
+scopes_supported: [file]
+`)
+	t.Setenv("SERVER_ADDR", ":7001")
+	t.Setenv("OAUTH_AUDIENCE", "https://env.example.com/mcp")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse([]string{"-config", path, "-addr", ":7002", "-scopes-supported", "a, b"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	cfg, err := LoadFlags(flags)
+	if err != nil {
+		t.Fatalf("LoadFlags() unexpected error: %v", err)
+	}
+
+	if cfg.BaseURL != "https://file.example.com" {
+		t.Errorf("BaseURL = %q, want the file value", cfg.BaseURL)
+	}
+	if cfg.Audience != "https://env.example.com/mcp" {
+		t.Errorf("Audience = %q, want the environment to override the file", cfg.Audience)
+	}
+	if cfg.Addr != ":7002" {
+		t.Errorf("Addr = %q, want the flag to override the environment", cfg.Addr)
+	}
+	if len(cfg.ScopesSupported) != 2 || cfg.ScopesSupported[0] != "a" || cfg.ScopesSupported[1] != "b" {
+		t.Errorf("ScopesSupported = %v, want [a b]", cfg.ScopesSupported)
+	}
+}
+
+func TestLoadFile_Interpolation(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("TEST_CONFIG_HOST", "example.com")
+	t.Setenv("TEST_CONFIG_REDIRECTS", "4")
+
+	path := writeConfigFile(t, "config.yaml", `
+base_url: https://${TEST_CONFIG_HOST}
+authorization_servers:
+  - https://auth.${TEST_CONFIG_HOST}
+audience: https://${TEST_CONFIG_HOST}/mcp
+jwks_cache_key: "pa$$word-0123456789abcdef0123456789"
+outbound_http:
+  max_redirects: ${TEST_CONFIG_REDIRECTS}
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error: %v", err)
+	}
+	if cfg.BaseURL != "https://example.com" || cfg.AuthorizationServers[0] != "https://auth.example.com" {
+		t.Errorf("BaseURL = %q, AuthorizationServers = %v", cfg.BaseURL, cfg.AuthorizationServers)
+	}
+	if cfg.JWKSCacheKey != "pa$word-0123456789abcdef0123456789" {
+		t.Errorf("JWKSCacheKey = %q, want $$ to become $", cfg.JWKSCacheKey)
+	}
+	if cfg.OutboundHTTP.MaxRedirects != 4 {
+		t.Errorf("OutboundHTTP.MaxRedirects = %d, want 4", cfg.OutboundHTTP.MaxRedirects)
+	}
+
+	path = writeConfigFile(t, "unset.yaml", "base_url: https://${TEST_CONFIG_UNSET}\n")
+	if _, err := LoadFile(path); err == nil || !containsString(err.Error(), "TEST_CONFIG_UNSET") {
+		t.Errorf("LoadFile() error = %v, want error mentioning TEST_CONFIG_UNSET", err)
+	}
+}
+
+func TestLoad_FileIndirection(t *testing.T) {
+	clearConfigEnvVars(t)
+	t.Setenv("SERVER_BASE_URL", "https://example.com")
+	t.Setenv("OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com")
+	t.Setenv("OAUTH_AUDIENCE", "https://example.com/mcp")
+
+	secret := writeConfigFile(t, "cache-key", "0123456789abcdef0123456789abcdef\n")
+	t.Setenv("OAUTH_JWKS_CACHE_KEY_FILE", secret)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.JWKSCacheKey != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("JWKSCacheKey = %q, want the trimmed file content", cfg.JWKSCacheKey)
+	}
+
+	t.Setenv("OAUTH_JWKS_CACHE_KEY", "inline")
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_JWKS_CACHE_KEY_FILE cannot be set as well") {
+		t.Errorf("Load() error = %v, want conflict error", err)
+	}
+
+	t.Setenv("OAUTH_JWKS_CACHE_KEY", "")
+	t.Setenv("OAUTH_JWKS_CACHE_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := Load(); err == nil || !containsString(err.Error(), "OAUTH_JWKS_CACHE_KEY_FILE") {
+		t.Errorf("Load() error = %v, want error mentioning OAUTH_JWKS_CACHE_KEY_FILE", err)
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	clearConfigEnvVars(t)
+
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		errContains string
+	}{
+		{
+			name:        "unknown key",
+			file:        "config.yaml",
+			content:     "base_url: https://example.com\nbase_uri: https://example.com\n",
+			errContains: "base_uri",
+		},
+		{
+			name:        "unknown nested key",
+			file:        "config.yaml",
+			content:     "outbound_http:\n  timeout_ms: 10\n",
+			errContains: "timeout_ms",
+		},
+		{
+			name:        "wrong type",
+			file:        "config.json",
+			content:     `{"authorization_servers": "https://auth.example.com"}`,
+			errContains: "invalid config file",
+		},
+		{
+			name:        "unsupported extension",
+			file:        "config.toml",
+			content:     "addr = \":8080\"\n",
+			errContains: "unsupported config file format",
+		},
+		{
+			name:        "validation applies to merged result",
+			file:        "config.yaml",
+			content:     "base_url: https://example.com\naudience: https://example.com/mcp\n",
+			errContains: "OAUTH_AUTHORIZATION_SERVERS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.file, tt.content)
+			if _, err := LoadFile(path); err == nil || !containsString(err.Error(), tt.errContains) {
+				t.Errorf("LoadFile() error = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadFile() of a missing file succeeded, want error")
+	}
+}
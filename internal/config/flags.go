@@ -0,0 +1,52 @@
+package config
+
+import "flag"
+
+// Flags are the command-line settings: the configuration file to read and
+// overrides that take precedence over the file and environment variables.
+type Flags struct {
+	// ConfigFile is the YAML or JSON configuration file (-config).
+	ConfigFile string
+
+	fs                   *flag.FlagSet
+	addr                 string
+	baseURL              string
+	authorizationServers string
+	audience             string
+	scopesSupported      string
+	devServer            bool
+}
+
+// RegisterFlags defines the configuration flags on fs. The overrides apply
+// only to flags set on the command line, after fs is parsed.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.ConfigFile, "config", "", "YAML or JSON configuration `file`")
+	fs.StringVar(&f.addr, "addr", "", "address to listen on (overrides SERVER_ADDR)")
+	fs.StringVar(&f.baseURL, "base-url", "", "canonical base `URL` of the server (overrides SERVER_BASE_URL)")
+	fs.StringVar(&f.authorizationServers, "authorization-servers", "", "comma-separated trusted authorization `servers` (overrides OAUTH_AUTHORIZATION_SERVERS)")
+	fs.StringVar(&f.audience, "audience", "", "expected access token audience (overrides OAUTH_AUDIENCE)")
+	fs.StringVar(&f.scopesSupported, "scopes-supported", "", "comma-separated advertised `scopes` (overrides OAUTH_SCOPES_SUPPORTED)")
+	fs.BoolVar(&f.devServer, "dev-server", false, "enable the development authorization server (overrides OAUTH_DEV_SERVER)")
+	return f
+}
+
+// apply overrides cfg with the flags set on the command line.
+func (f *Flags) apply(cfg *Config) {
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "addr":
+			cfg.Addr = f.addr
+		case "base-url":
+			cfg.BaseURL = f.baseURL
+		case "authorization-servers":
+			cfg.AuthorizationServers = splitList(f.authorizationServers)
+		case "audience":
+			cfg.Audience = f.audience
+		case "scopes-supported":
+			cfg.ScopesSupported = splitList(f.scopesSupported)
+		case "dev-server":
+			cfg.DevServer.Enabled = f.devServer
+		}
+	})
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"strings"
 )
 
 // Validate checks that the configuration is valid and complete.
@@ -156,9 +158,189 @@ func validateOAuth(cfg *Config) error {
 		return fmt.Errorf("OAUTH_CLOCK_SKEW must be positive")
 	}
 
+	// Validate JWKS persistence settings
+	if cfg.JWKSCacheFile != "" && cfg.JWKSCacheMaxStale <= 0 {
+		return fmt.Errorf("OAUTH_JWKS_CACHE_MAX_STALE must be positive")
+	}
+
+	if cfg.JWKSCacheKey != "" && len(cfg.JWKSCacheKey) < 32 {
+		return fmt.Errorf("OAUTH_JWKS_CACHE_KEY must be at least 32 characters")
+	}
+
+	// The resource key set is served from a path on this server
+	if cfg.ResourceMetadata.SigningKeyFile != "" && !strings.HasPrefix(cfg.ResourceMetadata.JWKSPath, "/") {
+		return fmt.Errorf("OAUTH_RESOURCE_JWKS_PATH must start with /")
+	}
+
+	if cfg.ASMetadataPath != "" && !strings.HasPrefix(cfg.ASMetadataPath, "/") {
+		return fmt.Errorf("OAUTH_AS_METADATA_PATH must start with /")
+	}
+
+	if err := validateProtectedResources(cfg); err != nil {
+		return err
+	}
+
+	if err := validateDevServer(cfg); err != nil {
+		return err
+	}
+
+	// Validate outbound HTTP client settings
+	if err := validateHTTPClient("OAUTH_HTTP", cfg.OutboundHTTP); err != nil {
+		return err
+	}
+
+	for serverURL, httpCfg := range cfg.AuthorizationServerHTTP {
+		if !contains(cfg.AuthorizationServers, serverURL) {
+			return fmt.Errorf("HTTP client override for %s does not match any OAUTH_AUTHORIZATION_SERVERS entry", serverURL)
+		}
+		if err := validateHTTPClient(fmt.Sprintf("HTTP client override for %s", serverURL), httpCfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateHTTPClient validates an outbound HTTP client configuration.
+// The prefix identifies the settings in error messages.
+func validateHTTPClient(prefix string, cfg HTTPClientConfig) error {
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("%s timeout must be non-negative", prefix)
+	}
+
+	if cfg.MaxBodyBytes < 0 {
+		return fmt.Errorf("%s max body bytes must be non-negative", prefix)
+	}
+
+	if cfg.MaxRedirects < 0 {
+		return fmt.Errorf("%s max redirects must be non-negative", prefix)
+	}
+
+	// Client certificate and key must be configured together
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return fmt.Errorf("%s client certificate and key files must be set together", prefix)
+	}
+
+	if cfg.ProxyURL != "" && cfg.ProxyURL != "direct" {
+		parsedProxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid %s proxy URL: %w", prefix, err)
+		}
+		switch parsedProxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("%s proxy URL must use http, https or socks5 scheme", prefix)
+		}
+		if parsedProxy.Host == "" {
+			return fmt.Errorf("%s proxy URL must include a host", prefix)
+		}
+	}
+
+	return nil
+}
+
+// validateProtectedResources checks that each additional protected resource
+// is hosted under a path of this server's origin, is distinct from the others
+// and only names trusted authorization servers.
+func validateProtectedResources(cfg *Config) error {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid SERVER_BASE_URL: %w", err)
+	}
+
+	seen := map[string]bool{strings.TrimRight(cfg.BaseURL, "/"): true}
+	for i, resource := range cfg.ProtectedResources {
+		parsed, err := url.Parse(resource.Resource)
+		if err != nil || !parsed.IsAbs() {
+			return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] resource must be an absolute URL", i)
+		}
+		if parsed.Scheme != base.Scheme || parsed.Host != base.Host {
+			return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] resource must share the origin of SERVER_BASE_URL", i)
+		}
+		if parsed.Fragment != "" {
+			return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] resource must not contain a fragment", i)
+		}
+
+		normalized := strings.TrimRight(resource.Resource, "/")
+		if seen[normalized] {
+			return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] duplicates resource %s", i, normalized)
+		}
+		seen[normalized] = true
+
+		if strings.Trim(parsed.Path, "/") == "" {
+			return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] resource must name a sub-path to be served at", i)
+		}
+		if path := strings.TrimRight(parsed.Path, "/"); path == "/mcp" {
+			return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] resource must not be served at the MCP endpoint %s", i, path)
+		}
+
+		for _, server := range resource.AuthorizationServers {
+			if !contains(cfg.AuthorizationServers, server) {
+				return fmt.Errorf("OAUTH_PROTECTED_RESOURCES[%d] authorization server %s is not listed in OAUTH_AUTHORIZATION_SERVERS", i, server)
+			}
+		}
+	}
+
 	return nil
 }
 
+// validateDevServer validates the development authorization server settings.
+// The server approves every request, so it may only run on localhost: the
+// listener must not be reachable from other hosts either.
+func validateDevServer(cfg *Config) error {
+	if !cfg.DevServer.Enabled {
+		return nil
+	}
+
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil || !isLocalhost(base.Host) {
+		return fmt.Errorf("OAUTH_DEV_SERVER requires a localhost SERVER_BASE_URL")
+	}
+
+	if !isLoopbackAddr(cfg.Addr) {
+		return fmt.Errorf("OAUTH_DEV_SERVER requires SERVER_ADDR to listen on a loopback address, such as 127.0.0.1:8080")
+	}
+
+	path := cfg.DevServer.Path
+	if !strings.HasPrefix(path, "/") || strings.TrimRight(path, "/") == "" {
+		return fmt.Errorf("OAUTH_DEV_SERVER_PATH must start with / and name a sub-path")
+	}
+
+	if cfg.DevServer.User == "" {
+		return fmt.Errorf("OAUTH_DEV_SERVER_USER is required")
+	}
+
+	if cfg.DevServer.TokenTTL <= 0 {
+		return fmt.Errorf("OAUTH_DEV_SERVER_TOKEN_TTL must be positive")
+	}
+
+	return nil
+}
+
+// isLoopbackAddr reports whether the listen address addr binds localhost or
+// a loopback IP. An empty host binds every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// contains reports whether values contains target.
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
 // validateMCP validates the MCP-related fields.
 func validateMCP(cfg *Config) error {
 	// Validate SessionTTL is positive
@@ -166,5 +348,10 @@ func validateMCP(cfg *Config) error {
 		return fmt.Errorf("MCP_SESSION_TTL must be positive")
 	}
 
+	// Validate PageSize is positive
+	if cfg.PageSize <= 0 {
+		return fmt.Errorf("MCP_PAGE_SIZE must be positive")
+	}
+
 	return nil
 }
@@ -20,6 +20,7 @@ func validConfig() *Config {
 		JWKSCacheTTL:         1 * time.Hour,
 		ClockSkew:            1 * time.Minute,
 		SessionTTL:           1 * time.Hour,
+		PageSize:             100,
 	}
 }
 
@@ -215,6 +216,16 @@ func TestValidate(t *testing.T) {
 			wantErr:     true,
 			errContains: "SESSION_TTL",
 		},
+		{
+			name: "zero PageSize is invalid",
+			config: func() *Config {
+				c := validConfig()
+				c.PageSize = 0
+				return c
+			}(),
+			wantErr:     true,
+			errContains: "MCP_PAGE_SIZE",
+		},
 	}
 
 	for _, tt := range tests {
@@ -371,3 +382,341 @@ func TestValidate_AudienceFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_OutboundHTTP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		modify      func(c *Config)
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:    "zero values use defaults",
+			modify:  func(c *Config) {},
+			wantErr: false,
+		},
+		{
+			name: "client cert without key",
+			modify: func(c *Config) {
+				c.OutboundHTTP.ClientCertFile = "/etc/pki/client.pem"
+			},
+			wantErr:     true,
+			errContains: "set together",
+		},
+		{
+			name: "negative max redirects",
+			modify: func(c *Config) {
+				c.OutboundHTTP.MaxRedirects = -1
+			},
+			wantErr:     true,
+			errContains: "max redirects",
+		},
+		{
+			name: "direct proxy",
+			modify: func(c *Config) {
+				c.OutboundHTTP.ProxyURL = "direct"
+			},
+			wantErr: false,
+		},
+		{
+			name: "proxy with unsupported scheme",
+			modify: func(c *Config) {
+				c.OutboundHTTP.ProxyURL = "ftp://proxy.example.com"
+			},
+			wantErr:     true,
+			errContains: "proxy URL",
+		},
+		{
+			name: "override for configured server",
+			modify: func(c *Config) {
+				c.AuthorizationServerHTTP = map[string]HTTPClientConfig{
+					"https://auth.example.com": {ProxyURL: "http://proxy.example.com:3128"},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "override for unknown server",
+			modify: func(c *Config) {
+				c.AuthorizationServerHTTP = map[string]HTTPClientConfig{
+					"https://other.example.com": {},
+				}
+			},
+			wantErr:     true,
+			errContains: "does not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := validConfig()
+			tt.modify(config)
+
+			err := Validate(config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Validate() error = nil, want error")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Validate() error = %q, want to contain %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_JWKSCachePersistence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		modify      func(c *Config)
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:    "persistence disabled",
+			modify:  func(c *Config) {},
+			wantErr: false,
+		},
+		{
+			name: "persistence with key",
+			modify: func(c *Config) {
+				c.JWKSCacheFile = "/var/lib/mcp/jwks.json"
+				c.JWKSCacheKey = "0123456789abcdef0123456789abcdef"
+				c.JWKSCacheMaxStale = time.Hour
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero max stale",
+			modify: func(c *Config) {
+				c.JWKSCacheFile = "/var/lib/mcp/jwks.json"
+				c.JWKSCacheMaxStale = 0
+			},
+			wantErr:     true,
+			errContains: "OAUTH_JWKS_CACHE_MAX_STALE",
+		},
+		{
+			name: "short key",
+			modify: func(c *Config) {
+				c.JWKSCacheFile = "/var/lib/mcp/jwks.json"
+				c.JWKSCacheKey = "too-short"
+				c.JWKSCacheMaxStale = time.Hour
+			},
+			wantErr:     true,
+			errContains: "OAUTH_JWKS_CACHE_KEY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := validConfig()
+			tt.modify(config)
+
+			err := Validate(config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Validate() error = nil, want error")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Validate() error = %q, want to contain %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_ResourceJWKSPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keyFile string
+		path    string
+		wantErr bool
+	}{
+		{name: "signing disabled", keyFile: "", path: "", wantErr: false},
+		{name: "absolute path", keyFile: "/etc/mcp/key.pem", path: "/.well-known/jwks.json", wantErr: false},
+		{name: "relative path", keyFile: "/etc/mcp/key.pem", path: "jwks.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := validConfig()
+			config.ResourceMetadata.SigningKeyFile = tt.keyFile
+			config.ResourceMetadata.JWKSPath = tt.path
+
+			err := Validate(config)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "OAUTH_RESOURCE_JWKS_PATH") {
+					t.Errorf("Validate() error = %v, want error mentioning OAUTH_RESOURCE_JWKS_PATH", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_DevServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		baseURL string
+		addr    string
+		modify  func(*DevServerConfig)
+		wantErr string
+	}{
+		{name: "disabled on public host", baseURL: "https://example.com", addr: ":8080", modify: func(d *DevServerConfig) { d.Enabled = false }},
+		{name: "localhost", baseURL: "http://localhost:8080"},
+		{name: "loopback IP", baseURL: "http://127.0.0.1:8080/mcp"},
+		{name: "localhost listener", baseURL: "http://localhost:8080", addr: "localhost:8080"},
+		{name: "IPv6 loopback listener", baseURL: "http://localhost:8080", addr: "[::1]:8080"},
+		{name: "public host", baseURL: "https://example.com", wantErr: "OAUTH_DEV_SERVER"},
+		{name: "all interfaces", baseURL: "http://localhost:8080", addr: ":8080", wantErr: "SERVER_ADDR"},
+		{name: "unspecified address", baseURL: "http://localhost:8080", addr: "0.0.0.0:8080", wantErr: "SERVER_ADDR"},
+		{name: "private address", baseURL: "http://localhost:8080", addr: "192.168.1.10:8080", wantErr: "SERVER_ADDR"},
+		{name: "root path", baseURL: "http://localhost:8080", modify: func(d *DevServerConfig) { d.Path = "/" }, wantErr: "OAUTH_DEV_SERVER_PATH"},
+		{name: "relative path", baseURL: "http://localhost:8080", modify: func(d *DevServerConfig) { d.Path = "dev-as" }, wantErr: "OAUTH_DEV_SERVER_PATH"},
+		{name: "empty user", baseURL: "http://localhost:8080", modify: func(d *DevServerConfig) { d.User = "" }, wantErr: "OAUTH_DEV_SERVER_USER"},
+		{name: "zero TTL", baseURL: "http://localhost:8080", modify: func(d *DevServerConfig) { d.TokenTTL = 0 }, wantErr: "OAUTH_DEV_SERVER_TOKEN_TTL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := validConfig()
+			config.BaseURL = tt.baseURL
+			config.Addr = "127.0.0.1:8080"
+			if tt.addr != "" {
+				config.Addr = tt.addr
+			}
+			config.DevServer = DevServerConfig{Enabled: true, Path: "/dev-as", User: "dev-user", TokenTTL: time.Hour}
+			if tt.modify != nil {
+				tt.modify(&config.DevServer)
+			}
+
+			err := Validate(config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Validate() error = %v, want error mentioning %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_ProtectedResources(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		resources   []ProtectedResourceConfig
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "resources on the same origin",
+			resources: []ProtectedResourceConfig{
+				{Resource: "https://example.com/billing"},
+				{Resource: "https://example.com/reports", AuthorizationServers: []string{"https://auth.example.com"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "relative resource",
+			resources:   []ProtectedResourceConfig{{Resource: "/reports"}},
+			wantErr:     true,
+			errContains: "absolute URL",
+		},
+		{
+			name:        "different origin",
+			resources:   []ProtectedResourceConfig{{Resource: "https://other.example.com/reports"}},
+			wantErr:     true,
+			errContains: "origin",
+		},
+		{
+			name:        "same as base URL",
+			resources:   []ProtectedResourceConfig{{Resource: "https://example.com/"}},
+			wantErr:     true,
+			errContains: "duplicates",
+		},
+		{
+			name:        "origin root",
+			resources:   []ProtectedResourceConfig{{Resource: "https://example.com/?tenant=a"}},
+			wantErr:     true,
+			errContains: "sub-path",
+		},
+		{
+			name:        "MCP endpoint",
+			resources:   []ProtectedResourceConfig{{Resource: "https://example.com/mcp"}},
+			wantErr:     true,
+			errContains: "MCP endpoint",
+		},
+		{
+			name: "duplicate resources",
+			resources: []ProtectedResourceConfig{
+				{Resource: "https://example.com/reports"},
+				{Resource: "https://example.com/reports/"},
+			},
+			wantErr:     true,
+			errContains: "duplicates",
+		},
+		{
+			name: "untrusted authorization server",
+			resources: []ProtectedResourceConfig{
+				{Resource: "https://example.com/reports", AuthorizationServers: []string{"https://evil.example.com"}},
+			},
+			wantErr:     true,
+			errContains: "OAUTH_AUTHORIZATION_SERVERS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := validConfig()
+			config.ProtectedResources = tt.resources
+
+			err := Validate(config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Validate() error = nil, want error")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Validate() error = %q, want to contain %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -102,7 +102,7 @@ func setupTestFixture(t *testing.T) *testFixture {
 		ServerName:    testServerInfo.Name,
 		ServerVersion: testServerInfo.Version,
 	}
-	mcpHandler, _, _ := mcp.NewMCPServices(mcpCfg)
+	mcpHandler, _, _, _ := mcp.NewMCPServices(mcpCfg)
 
 	// Create server configuration
 	serverCfg := &config.Config{
@@ -192,6 +192,32 @@ func (f *testFixture) createToken(t *testing.T, claims jwt.MapClaims) string {
 	return tokenString
 }
 
+// initializeSession sends initialize with token and returns the ID of the
+// session it starts.
+func (f *testFixture) initializeSession(t *testing.T, token string) string {
+	t.Helper()
+
+	body := []byte(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test-client","version":"1.0.0"}}}`)
+	req, err := http.NewRequest(http.MethodPost, f.baseURL+"/mcp", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize status = %d, Mcp-Session-Id = %q", resp.StatusCode, sessionID)
+	}
+	return sessionID
+}
+
 // createExpiredToken creates an expired JWT token for testing.
 func (f *testFixture) createExpiredToken(t *testing.T) string {
 	t.Helper()
@@ -801,6 +827,7 @@ func TestIntegration_MCPEndpoint_ToolsList(t *testing.T) {
 	defer fixture.teardown()
 
 	token := fixture.createToken(t, nil)
+	sessionID := fixture.initializeSession(t, token)
 
 	jsonRPCReq := map[string]any{
 		"jsonrpc": "2.0",
@@ -819,6 +846,7 @@ func TestIntegration_MCPEndpoint_ToolsList(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Mcp-Session-Id", sessionID)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -866,6 +894,7 @@ func TestIntegration_MCPEndpoint_ResourcesList(t *testing.T) {
 	defer fixture.teardown()
 
 	token := fixture.createToken(t, nil)
+	sessionID := fixture.initializeSession(t, token)
 
 	jsonRPCReq := map[string]any{
 		"jsonrpc": "2.0",
@@ -884,6 +913,7 @@ func TestIntegration_MCPEndpoint_ResourcesList(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Mcp-Session-Id", sessionID)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -931,6 +961,7 @@ func TestIntegration_MCPEndpoint_MethodNotFound(t *testing.T) {
 	defer fixture.teardown()
 
 	token := fixture.createToken(t, nil)
+	sessionID := fixture.initializeSession(t, token)
 
 	jsonRPCReq := map[string]any{
 		"jsonrpc": "2.0",
@@ -949,6 +980,7 @@ func TestIntegration_MCPEndpoint_MethodNotFound(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Mcp-Session-Id", sessionID)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -994,8 +1026,8 @@ func TestIntegration_MCPEndpoint_MethodNotFound(t *testing.T) {
 // MCP Endpoint Tests - HTTP Method
 // ============================================================================
 
-func TestIntegration_MCPEndpoint_OnlyAllowsPost(t *testing.T) {
-	methods := []string{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPatch}
+func TestIntegration_MCPEndpoint_MethodNotAllowed(t *testing.T) {
+	methods := []string{http.MethodPut, http.MethodPatch}
 
 	for _, method := range methods {
 		t.Run(method+" returns 405", func(t *testing.T) {
@@ -1023,6 +1055,72 @@ func TestIntegration_MCPEndpoint_OnlyAllowsPost(t *testing.T) {
 	}
 }
 
+func TestIntegration_MCPEndpoint_SessionStreams(t *testing.T) {
+	fixture := setupTestFixture(t)
+	defer fixture.teardown()
+
+	token := fixture.createToken(t, nil)
+
+	do := func(method, sessionID, accept string, authorized bool, body []byte) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, fixture.baseURL+"/mcp", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		if authorized {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		return resp
+	}
+
+	sessionID := fixture.initializeSession(t, token)
+
+	// GET and DELETE stay behind authentication
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		resp := do(method, sessionID, "text/event-stream", false, nil)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("unauthenticated %s status = %d, want %d", method, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+
+	stream := do(http.MethodGet, sessionID, "text/event-stream", true, nil)
+	defer func() { _ = stream.Body.Close() }()
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d", stream.StatusCode, http.StatusOK)
+	}
+	if contentType := stream.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("GET Content-Type = %q, want text/event-stream", contentType)
+	}
+
+	resp := do(http.MethodDelete, sessionID, "", true, nil)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	// Terminating the session ends its stream
+	if _, err := io.ReadAll(stream.Body); err != nil {
+		t.Errorf("failed to read stream until the session ended: %v", err)
+	}
+
+	resp = do(http.MethodPost, sessionID, "", true, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST to terminated session status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
 // ============================================================================
 // JSON-RPC Protocol Tests
 // ============================================================================
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Content types.
+const (
+	ContentText         = "text"
+	ContentImage        = "image"
+	ContentAudio        = "audio"
+	ContentResource     = "resource"
+	ContentResourceLink = "resource_link"
+)
+
+// NewTextContent returns a text content block.
+func NewTextContent(text string) Content {
+	return Content{Type: ContentText, Text: text}
+}
+
+// NewImageContent returns an image content block holding data, an image
+// of the given MIME type such as "image/png".
+func NewImageContent(data []byte, mimeType string) Content {
+	return Content{Type: ContentImage, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// NewAudioContent returns an audio content block holding data, audio of
+// the given MIME type such as "audio/wav".
+func NewAudioContent(data []byte, mimeType string) Content {
+	return Content{Type: ContentAudio, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// NewResourceContent returns a content block embedding the content of a
+// resource.
+func NewResourceContent(resource ResourceContent) Content {
+	return Content{Type: ContentResource, Resource: &resource}
+}
+
+// NewResourceLinkContent returns a content block linking to the resource
+// described by definition, which the client may read with resources/read.
+func NewResourceLinkContent(definition ResourceDefinition) Content {
+	return Content{
+		Type:        ContentResourceLink,
+		URI:         definition.URI,
+		Name:        definition.Name,
+		Description: definition.Description,
+		MimeType:    definition.MimeType,
+	}
+}
+
+// newToolsCallResult converts the value returned by a tool's Execute into
+// the tools/call result, as documented on Tool.
+func newToolsCallResult(value any) *ToolsCallResult {
+	switch v := value.(type) {
+	case *ToolsCallResult:
+		if v == nil {
+			return &ToolsCallResult{Content: []Content{}}
+		}
+		return v
+	case ToolsCallResult:
+		return &v
+	case Content:
+		return &ToolsCallResult{Content: []Content{v}}
+	case []Content:
+		return &ToolsCallResult{Content: v}
+	case string:
+		return &ToolsCallResult{Content: []Content{NewTextContent(v)}}
+	case nil:
+		return &ToolsCallResult{Content: []Content{}}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		// Not representable as JSON, such as a channel: keep it readable
+		return &ToolsCallResult{Content: []Content{NewTextContent(fmt.Sprintf("%v", value))}}
+	}
+
+	result := &ToolsCallResult{Content: []Content{NewTextContent(string(data))}}
+
+	// Only JSON objects can be structured content
+	var object map[string]any
+	if json.Unmarshal(data, &object) == nil && object != nil {
+		result.StructuredContent = value
+	}
+	return result
+}
+
+// checkOutputSchema verifies the structured content of a successful result
+// against the tool's output schema, if it declares one.
+func checkOutputSchema(definition ToolDefinition, result *ToolsCallResult) error {
+	if definition.OutputSchema == nil || result.IsError {
+		return nil
+	}
+	if result.StructuredContent == nil {
+		return fmt.Errorf("tool %q declares an output schema but returned no structured content", definition.Name)
+	}
+	if err := validateSchema(definition.OutputSchema, result.StructuredContent); err != nil {
+		return fmt.Errorf("tool %q structured content does not match its output schema: %w", definition.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestContentBuilders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content Content
+		want    string
+	}{
+		{
+			name:    "text",
+			content: NewTextContent("hello"),
+			want:    `{"type":"text","text":"hello"}`,
+		},
+		{
+			name:    "image",
+			content: NewImageContent([]byte("png"), "image/png"),
+			want:    `{"type":"image","data":"cG5n","mimeType":"image/png"}`,
+		},
+		{
+			name:    "audio",
+			content: NewAudioContent([]byte("wav"), "audio/wav"),
+			want:    `{"type":"audio","data":"d2F2","mimeType":"audio/wav"}`,
+		},
+		{
+			name:    "embedded resource",
+			content: NewResourceContent(ResourceContent{URI: "file:///notes.txt", MimeType: "text/plain", Text: "notes"}),
+			want:    `{"type":"resource","resource":{"uri":"file:///notes.txt","mimeType":"text/plain","text":"notes"}}`,
+		},
+		{
+			name:    "resource link",
+			content: NewResourceLinkContent(ResourceDefinition{URI: "file:///report.pdf", Name: "report", MimeType: "application/pdf"}),
+			want:    `{"type":"resource_link","mimeType":"application/pdf","uri":"file:///report.pdf","name":"report"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Marshal(tt.content)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("content = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToolsCallResult(t *testing.T) {
+	t.Parallel()
+
+	type forecast struct {
+		City string `json:"city"`
+	}
+
+	tests := []struct {
+		name  string
+		value any
+		want  *ToolsCallResult
+	}{
+		{
+			name:  "string",
+			value: "alice",
+			want:  &ToolsCallResult{Content: []Content{NewTextContent("alice")}},
+		},
+		{
+			name:  "nil",
+			value: nil,
+			want:  &ToolsCallResult{Content: []Content{}},
+		},
+		{
+			name:  "result",
+			value: &ToolsCallResult{Content: []Content{NewTextContent("failed")}, IsError: true},
+			want:  &ToolsCallResult{Content: []Content{NewTextContent("failed")}, IsError: true},
+		},
+		{
+			name:  "content blocks",
+			value: []Content{NewTextContent("a"), NewImageContent([]byte("b"), "image/png")},
+			want:  &ToolsCallResult{Content: []Content{NewTextContent("a"), NewImageContent([]byte("b"), "image/png")}},
+		},
+		{
+			name:  "map",
+			value: map[string]any{"a": 1},
+			want:  &ToolsCallResult{Content: []Content{NewTextContent(`{"a":1}`)}, StructuredContent: map[string]any{"a": 1}},
+		},
+		{
+			name:  "struct",
+			value: forecast{City: "Oslo"},
+			want:  &ToolsCallResult{Content: []Content{NewTextContent(`{"city":"Oslo"}`)}, StructuredContent: forecast{City: "Oslo"}},
+		},
+		{
+			name:  "number",
+			value: 42,
+			want:  &ToolsCallResult{Content: []Content{NewTextContent("42")}},
+		},
+		{
+			name:  "array",
+			value: []int{1, 2},
+			want:  &ToolsCallResult{Content: []Content{NewTextContent("[1,2]")}},
+		},
+		{
+			name:  "not JSON",
+			value: complex(1, 2),
+			want:  &ToolsCallResult{Content: []Content{NewTextContent("(1+2i)")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := newToolsCallResult(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newToolsCallResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// weatherTool returns a fixed forecast, declaring an output schema.
+type weatherTool struct {
+	result any
+}
+
+func (w *weatherTool) Execute(ctx context.Context, args map[string]any) (any, error) {
+	return w.result, nil
+}
+
+func (w *weatherTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        "weather",
+		InputSchema: map[string]any{"type": "object"},
+		OutputSchema: map[string]any{
+			"type":       "object",
+			"required":   []string{"temperature"},
+			"properties": map[string]any{"temperature": map[string]any{"type": "number"}},
+		},
+	}
+}
+
+func TestHandler_StructuredToolResults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		version        string
+		result         any
+		wantStructured bool
+		wantSchema     bool
+		wantErr        bool
+	}{
+		{
+			name:           "structured content",
+			version:        ProtocolVersion20250618,
+			result:         map[string]any{"temperature": 21.5},
+			wantStructured: true,
+			wantSchema:     true,
+		},
+		{
+			name:       "structured content outside the schema",
+			version:    ProtocolVersion20250618,
+			result:     map[string]any{"temperature": "warm"},
+			wantSchema: true,
+			wantErr:    true,
+		},
+		{
+			name:       "missing structured content",
+			version:    ProtocolVersion20250618,
+			result:     "21.5",
+			wantSchema: true,
+			wantErr:    true,
+		},
+		{
+			name:    "earlier protocol version",
+			version: ProtocolVersion20250326,
+			result:  map[string]any{"temperature": 21.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler, tools, _, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+			if err := tools.RegisterTool("weather", &weatherTool{result: tt.result}); err != nil {
+				t.Fatalf("RegisterTool() error = %v", err)
+			}
+
+			session, err := NewSessionManager(0).Create(SessionOwner{Subject: "alice"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			ctx := ContextWithSession(context.Background(), session)
+
+			call := func(method, params string) *Response {
+				t.Helper()
+
+				resp, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 1, Method: method, Params: json.RawMessage(params)})
+				if err != nil {
+					t.Fatalf("%s error = %v", method, err)
+				}
+				return resp
+			}
+
+			if resp := call("initialize", `{"protocolVersion":"`+tt.version+`"}`); resp.Error != nil {
+				t.Fatalf("initialize error = %v", resp.Error)
+			}
+
+			listed := call("tools/list", `{}`).Result.(ToolsListResult).Tools
+			if hasSchema := len(listed) == 1 && listed[0].OutputSchema != nil; hasSchema != tt.wantSchema {
+				t.Errorf("tools/list outputSchema listed = %v, want %v", hasSchema, tt.wantSchema)
+			}
+
+			resp := call("tools/call", `{"name":"weather"}`)
+			if tt.wantErr {
+				if resp.Error == nil || resp.Error.Code != CodeInternalError {
+					t.Errorf("tools/call error = %v, want internal error", resp.Error)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("tools/call error = %v", resp.Error)
+			}
+
+			result := resp.Result.(*ToolsCallResult)
+			if len(result.Content) != 1 || result.Content[0].Text != `{"temperature":21.5}` {
+				t.Errorf("tools/call content = %+v, want the JSON text", result.Content)
+			}
+			if (result.StructuredContent != nil) != tt.wantStructured {
+				t.Errorf("tools/call structuredContent = %v, want present %v", result.StructuredContent, tt.wantStructured)
+			}
+		})
+	}
+}
@@ -35,9 +35,31 @@ var (
 	// ErrResourceReadFailed indicates reading the resource content failed.
 	ErrResourceReadFailed = errors.New("resource read failed")
 
+	// ErrPromptNotFound indicates the requested prompt does not exist.
+	ErrPromptNotFound = errors.New("prompt not found")
+
+	// ErrPromptAlreadyRegistered indicates a prompt with the same name is already registered.
+	ErrPromptAlreadyRegistered = errors.New("prompt already registered")
+
 	// ErrParseError indicates the JSON-RPC request could not be parsed.
 	ErrParseError = errors.New("parse error")
 
 	// ErrInternalError indicates an internal server error occurred.
 	ErrInternalError = errors.New("internal error")
+
+	// ErrSessionClosed indicates the session was terminated.
+	ErrSessionClosed = errors.New("session closed")
+
+	// ErrStreamOpen indicates the session already has a standalone stream.
+	ErrStreamOpen = errors.New("stream already open")
+
+	// ErrNoStream indicates no stream to the client is open.
+	ErrNoStream = errors.New("no stream open")
+
+	// ErrStreamClosed indicates the stream to the client has ended.
+	ErrStreamClosed = errors.New("stream closed")
+
+	// ErrNoSession indicates a server-initiated request outside of a
+	// session, where the client's response could not be routed back.
+	ErrNoSession = errors.New("no session")
 )
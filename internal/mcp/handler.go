@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	internalerrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
 )
@@ -15,8 +17,15 @@ import (
 type handler struct {
 	toolRegistry     ToolRegistry
 	resourceRegistry ResourceRegistry
+	promptRegistry   PromptRegistry
 	serverInfo       serverInfo
-	initialized      bool
+	updateWindow     time.Duration
+	pageSize         int
+
+	// sessions holds the initialized sessions, to fan out list changes
+	// and resource updates
+	mu       sync.Mutex
+	sessions map[*Session]struct{}
 }
 
 // serverInfo contains metadata about the MCP server.
@@ -27,20 +36,33 @@ type serverInfo struct {
 
 // newHandler creates a new MCP protocol handler.
 // The handler processes JSON-RPC 2.0 requests and routes them to the
-// appropriate tool or resource registries.
-func newHandler(toolRegistry ToolRegistry, resourceRegistry ResourceRegistry, info serverInfo) Handler {
+// appropriate tool, resource or prompt registries. Resource updates are
+// coalesced per session over updateWindow, and the list methods return
+// pageSize items per page.
+func newHandler(toolRegistry ToolRegistry, resourceRegistry ResourceRegistry, promptRegistry PromptRegistry, info serverInfo, updateWindow time.Duration, pageSize int) Handler {
 	if toolRegistry == nil {
 		panic("toolRegistry cannot be nil")
 	}
 	if resourceRegistry == nil {
 		panic("resourceRegistry cannot be nil")
 	}
-	return &handler{
+	if promptRegistry == nil {
+		panic("promptRegistry cannot be nil")
+	}
+	h := &handler{
 		toolRegistry:     toolRegistry,
 		resourceRegistry: resourceRegistry,
+		promptRegistry:   promptRegistry,
 		serverInfo:       info,
-		initialized:      false,
+		updateWindow:     updateWindow,
+		pageSize:         pageSize,
+		sessions:         make(map[*Session]struct{}),
 	}
+	resourceRegistry.ObserveUpdates(h.resourceUpdated)
+	toolRegistry.ObserveChanges(func() { h.listChanged(MethodToolsListChanged) })
+	resourceRegistry.ObserveChanges(func() { h.listChanged(MethodResourcesListChanged) })
+	promptRegistry.ObserveChanges(func() { h.listChanged(MethodPromptsListChanged) })
+	return h
 }
 
 // HandleRequest processes an MCP JSON-RPC request.
@@ -59,6 +81,22 @@ func (h *handler) HandleRequest(ctx context.Context, req *Request) (*Response, e
 		return h.errorResponse(req.ID, CodeInvalidRequest, "method is required", nil), nil
 	}
 
+	// Every method but initialize needs an initialized session. The HTTP
+	// transport answers requests without a known session with 400 or 404
+	// before they get here; this covers a session still initializing.
+	if req.Method != "initialize" {
+		session, ok := SessionFromContext(ctx)
+		if !ok || !session.Initialized() {
+			return h.errorResponse(req.ID, CodeInvalidRequest, "session not initialized", nil), nil
+		}
+
+		// The client may cancel the request with notifications/cancelled
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		defer session.trackRequest(req.ID, cancel)()
+	}
+
 	// Route to appropriate handler
 	switch req.Method {
 	case "initialize":
@@ -71,11 +109,42 @@ func (h *handler) HandleRequest(ctx context.Context, req *Request) (*Response, e
 		return h.handleResourcesList(ctx, req)
 	case "resources/read":
 		return h.handleResourcesRead(ctx, req)
+	case "resources/templates/list":
+		return h.handleResourceTemplatesList(ctx, req)
+	case "resources/subscribe":
+		return h.handleResourcesSubscribe(ctx, req)
+	case "resources/unsubscribe":
+		return h.handleResourcesUnsubscribe(ctx, req)
+	case "prompts/list":
+		return h.handlePromptsList(ctx, req)
+	case "prompts/get":
+		return h.handlePromptsGet(ctx, req)
 	default:
 		return h.errorResponse(req.ID, CodeMethodNotFound, fmt.Sprintf("method not found: %s", req.Method), nil), nil
 	}
 }
 
+// HandleNotification processes a notification from the client.
+// Unknown notifications are ignored, as JSON-RPC requires.
+func (h *handler) HandleNotification(ctx context.Context, notification *Request) error {
+	switch notification.Method {
+	case MethodInitialized:
+		// The session is usable as soon as initialize succeeded
+		return nil
+	case MethodCancelled:
+		var params CancelledParams
+		if err := json.Unmarshal(notification.Params, &params); err != nil {
+			return fmt.Errorf("invalid %s params: %w", MethodCancelled, err)
+		}
+		if session, ok := SessionFromContext(ctx); ok {
+			session.cancelRequest(params.RequestID)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
 // handleInitialize handles the initialize method.
 func (h *handler) handleInitialize(ctx context.Context, req *Request) (*Response, error) {
 	var params InitializeParams
@@ -85,20 +154,32 @@ func (h *handler) handleInitialize(ctx context.Context, req *Request) (*Response
 		}
 	}
 
-	h.initialized = true
+	version := NegotiateProtocolVersion(params.ProtocolVersion)
+	if session, ok := SessionFromContext(ctx); ok {
+		session.initialize(version, params)
+
+		h.mu.Lock()
+		h.sessions[session] = struct{}{}
+		h.mu.Unlock()
+	}
 
 	result := InitializeResult{
-		ProtocolVersion: ProtocolVersion,
+		ProtocolVersion: version,
 		ServerInfo: ServerInfoResponse{
 			Name:    h.serverInfo.Name,
 			Version: h.serverInfo.Version,
 		},
 		Capabilities: Capabilities{
-			Tools:     &ToolsCapability{},
-			Resources: &ResourcesCapability{},
+			Tools:     &ToolsCapability{ListChanged: true},
+			Resources: &ResourcesCapability{Subscribe: true, ListChanged: true},
 		},
 	}
 
+	// Prompts are only advertised when there are some to offer
+	if len(h.promptRegistry.ListPrompts()) > 0 {
+		result.Capabilities.Prompts = &PromptsCapability{ListChanged: true}
+	}
+
 	return &Response{
 		JSONRPC: JSONRPCVersion,
 		ID:      req.ID,
@@ -107,11 +188,41 @@ func (h *handler) handleInitialize(ctx context.Context, req *Request) (*Response
 }
 
 // handleToolsList handles the tools/list method.
+// Tools the caller lacks the scopes for are left out.
 func (h *handler) handleToolsList(ctx context.Context, req *Request) (*Response, error) {
-	tools := h.toolRegistry.ListTools()
+	cursor, errResp := h.listCursor(req)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	granted := ScopesFromContext(ctx)
+
+	tools := make([]ToolDefinition, 0)
+	for _, definition := range h.toolRegistry.ListTools() {
+		tool, err := h.toolRegistry.GetTool(definition.Name)
+		if err != nil || !toolAllowed(tool, granted) {
+			continue
+		}
+		tools = append(tools, definition)
+	}
+
+	tools, next, err := paginate(tools, func(tool ToolDefinition) string {
+		return tool.Name
+	}, cursor, h.pageSize)
+	if err != nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "invalid cursor", err.Error()), nil
+	}
+
+	// Output schemas are only listed to clients that read structured content
+	if !supportsStructuredContent(ctx) {
+		for i := range tools {
+			tools[i].OutputSchema = nil
+		}
+	}
 
 	result := ToolsListResult{
-		Tools: tools,
+		Tools:      tools,
+		NextCursor: next,
 	}
 
 	return &Response{
@@ -122,6 +233,8 @@ func (h *handler) handleToolsList(ctx context.Context, req *Request) (*Response,
 }
 
 // handleToolsCall handles the tools/call method.
+// A tool the caller lacks the scopes for is reported as not found, so its
+// existence is not disclosed.
 func (h *handler) handleToolsCall(ctx context.Context, req *Request) (*Response, error) {
 	if req.Params == nil {
 		return h.errorResponse(req.ID, CodeInvalidParams, "params required", nil), nil
@@ -144,6 +257,13 @@ func (h *handler) handleToolsCall(ctx context.Context, req *Request) (*Response,
 		domainErr := internalerrors.New("mcp", "HandleRequest", internalerrors.ErrInternal, err)
 		return h.errorResponse(req.ID, CodeInternalError, "failed to get tool", domainErr.Error()), nil
 	}
+	if !toolAllowed(tool, ScopesFromContext(ctx)) {
+		return h.errorResponse(req.ID, CodeToolNotFound, fmt.Sprintf("tool not found: %s", params.Name), nil), nil
+	}
+
+	if params.Meta != nil && params.Meta.ProgressToken != nil {
+		ctx = ContextWithProgressToken(ctx, params.Meta.ProgressToken)
+	}
 
 	// Execute the tool
 	toolResult, err := tool.Execute(ctx, params.Arguments)
@@ -152,13 +272,17 @@ func (h *handler) handleToolsCall(ctx context.Context, req *Request) (*Response,
 		return h.errorResponse(req.ID, CodeInternalError, "tool execution failed", domainErr.Error()), nil
 	}
 
-	result := ToolsCallResult{
-		Content: []Content{
-			{
-				Type: "text",
-				Text: fmt.Sprintf("%v", toolResult),
-			},
-		},
+	result := newToolsCallResult(toolResult)
+	if err := checkOutputSchema(tool.Definition(), result); err != nil {
+		domainErr := internalerrors.New("mcp", "HandleRequest", internalerrors.ErrInternal, err)
+		return h.errorResponse(req.ID, CodeInternalError, "invalid tool result", domainErr.Error()), nil
+	}
+
+	// Clients of earlier protocol versions only read the content blocks
+	if result.StructuredContent != nil && !supportsStructuredContent(ctx) {
+		stripped := *result
+		stripped.StructuredContent = nil
+		result = &stripped
 	}
 
 	return &Response{
@@ -169,11 +293,68 @@ func (h *handler) handleToolsCall(ctx context.Context, req *Request) (*Response,
 }
 
 // handleResourcesList handles the resources/list method.
+// Resources the caller lacks the scopes for are left out.
 func (h *handler) handleResourcesList(ctx context.Context, req *Request) (*Response, error) {
-	resources := h.resourceRegistry.ListResources()
+	cursor, errResp := h.listCursor(req)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	granted := ScopesFromContext(ctx)
+
+	resources := make([]ResourceDefinition, 0)
+	for _, definition := range h.resourceRegistry.ListResources() {
+		if hasAllScopes(granted, h.resourceRegistry.ResourceScopes(definition.URI)) {
+			resources = append(resources, definition)
+		}
+	}
+
+	resources, next, err := paginate(resources, func(resource ResourceDefinition) string {
+		return resource.URI
+	}, cursor, h.pageSize)
+	if err != nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "invalid cursor", err.Error()), nil
+	}
 
 	result := ResourcesListResult{
-		Resources: resources,
+		Resources:  resources,
+		NextCursor: next,
+	}
+
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      req.ID,
+		Result:  result,
+	}, nil
+}
+
+// handleResourceTemplatesList handles the resources/templates/list method.
+// Templates the caller lacks the scopes for are left out.
+func (h *handler) handleResourceTemplatesList(ctx context.Context, req *Request) (*Response, error) {
+	cursor, errResp := h.listCursor(req)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	granted := ScopesFromContext(ctx)
+
+	templates := make([]ResourceTemplateDefinition, 0)
+	for _, definition := range h.resourceRegistry.ListResourceTemplates() {
+		if hasAllScopes(granted, h.resourceRegistry.ResourceTemplateScopes(definition.URITemplate)) {
+			templates = append(templates, definition)
+		}
+	}
+
+	templates, next, err := paginate(templates, func(template ResourceTemplateDefinition) string {
+		return template.URITemplate
+	}, cursor, h.pageSize)
+	if err != nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "invalid cursor", err.Error()), nil
+	}
+
+	result := ResourceTemplatesListResult{
+		ResourceTemplates: templates,
+		NextCursor:        next,
 	}
 
 	return &Response{
@@ -184,6 +365,8 @@ func (h *handler) handleResourcesList(ctx context.Context, req *Request) (*Respo
 }
 
 // handleResourcesRead handles the resources/read method.
+// A resource the caller lacks the scopes for is reported as not found, so
+// its existence is not disclosed.
 func (h *handler) handleResourcesRead(ctx context.Context, req *Request) (*Response, error) {
 	if req.Params == nil {
 		return h.errorResponse(req.ID, CodeInvalidParams, "params required", nil), nil
@@ -197,6 +380,9 @@ func (h *handler) handleResourcesRead(ctx context.Context, req *Request) (*Respo
 	if params.URI == "" {
 		return h.errorResponse(req.ID, CodeInvalidParams, "resource uri is required", nil), nil
 	}
+	if !hasAllScopes(ScopesFromContext(ctx), h.resourceRegistry.ResourceScopes(params.URI)) {
+		return h.errorResponse(req.ID, CodeResourceNotFound, fmt.Sprintf("resource not found: %s", params.URI), nil), nil
+	}
 
 	resource, err := h.resourceRegistry.GetResource(ctx, params.URI)
 	if err != nil {
@@ -224,6 +410,223 @@ func (h *handler) handleResourcesRead(ctx context.Context, req *Request) (*Respo
 	}, nil
 }
 
+// handleResourcesSubscribe handles the resources/subscribe method.
+// A resource the caller lacks the scopes for is reported as not found.
+func (h *handler) handleResourcesSubscribe(ctx context.Context, req *Request) (*Response, error) {
+	session, uri, errResp := h.subscriptionParams(ctx, req)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if !hasAllScopes(ScopesFromContext(ctx), h.resourceRegistry.ResourceScopes(uri)) {
+		return h.errorResponse(req.ID, CodeResourceNotFound, fmt.Sprintf("resource not found: %s", uri), nil), nil
+	}
+
+	session.subscribe(uri)
+
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      req.ID,
+		Result:  EmptyResult{},
+	}, nil
+}
+
+// handleResourcesUnsubscribe handles the resources/unsubscribe method.
+func (h *handler) handleResourcesUnsubscribe(ctx context.Context, req *Request) (*Response, error) {
+	session, uri, errResp := h.subscriptionParams(ctx, req)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	session.unsubscribe(uri)
+
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      req.ID,
+		Result:  EmptyResult{},
+	}, nil
+}
+
+// subscriptionParams returns the session and resource URI of a
+// resources/subscribe or resources/unsubscribe request, or the error
+// response to send.
+func (h *handler) subscriptionParams(ctx context.Context, req *Request) (*Session, string, *Response) {
+	if req.Params == nil {
+		return nil, "", h.errorResponse(req.ID, CodeInvalidParams, "params required", nil)
+	}
+
+	var params ResourcesSubscribeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, "", h.errorResponse(req.ID, CodeInvalidParams, fmt.Sprintf("invalid %s params", req.Method), err.Error())
+	}
+
+	if params.URI == "" {
+		return nil, "", h.errorResponse(req.ID, CodeInvalidParams, "resource uri is required", nil)
+	}
+
+	// HandleRequest only lets requests in an initialized session through
+	session, _ := SessionFromContext(ctx)
+	return session, params.URI, nil
+}
+
+// resourceUpdated fans a resource update out to the sessions, which
+// notify it if they subscribed to the resource.
+func (h *handler) resourceUpdated(uri string) {
+	for _, session := range h.openSessions() {
+		session.resourceUpdated(uri, h.updateWindow)
+	}
+}
+
+// listChanged notifies every session that a list changed.
+func (h *handler) listChanged(method string) {
+	for _, session := range h.openSessions() {
+		session.listChanged(method, h.updateWindow)
+	}
+}
+
+// openSessions returns the initialized sessions, forgetting sessions that
+// were terminated.
+func (h *handler) openSessions() []*Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions := make([]*Session, 0, len(h.sessions))
+	for session := range h.sessions {
+		select {
+		case <-session.Done():
+			delete(h.sessions, session)
+		default:
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// handlePromptsList handles the prompts/list method.
+// Prompts the caller lacks the scopes for are left out.
+func (h *handler) handlePromptsList(ctx context.Context, req *Request) (*Response, error) {
+	cursor, errResp := h.listCursor(req)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	granted := ScopesFromContext(ctx)
+
+	prompts := make([]PromptDefinition, 0)
+	for _, definition := range h.promptRegistry.ListPrompts() {
+		prompt, err := h.promptRegistry.GetPrompt(definition.Name)
+		if err != nil || !promptAllowed(prompt, granted) {
+			continue
+		}
+		prompts = append(prompts, definition)
+	}
+
+	prompts, next, err := paginate(prompts, func(prompt PromptDefinition) string {
+		return prompt.Name
+	}, cursor, h.pageSize)
+	if err != nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "invalid cursor", err.Error()), nil
+	}
+
+	result := PromptsListResult{
+		Prompts:    prompts,
+		NextCursor: next,
+	}
+
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      req.ID,
+		Result:  result,
+	}, nil
+}
+
+// handlePromptsGet handles the prompts/get method.
+// A prompt the caller lacks the scopes for is reported as not found, so
+// its existence is not disclosed.
+func (h *handler) handlePromptsGet(ctx context.Context, req *Request) (*Response, error) {
+	if req.Params == nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "params required", nil), nil
+	}
+
+	var params PromptsGetParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "invalid prompts/get params", err.Error()), nil
+	}
+
+	if params.Name == "" {
+		return h.errorResponse(req.ID, CodeInvalidParams, "prompt name is required", nil), nil
+	}
+
+	prompt, err := h.promptRegistry.GetPrompt(params.Name)
+	if err != nil {
+		if errors.Is(err, ErrPromptNotFound) {
+			return h.errorResponse(req.ID, CodePromptNotFound, fmt.Sprintf("prompt not found: %s", params.Name), nil), nil
+		}
+		domainErr := internalerrors.New("mcp", "HandleRequest", internalerrors.ErrInternal, err)
+		return h.errorResponse(req.ID, CodeInternalError, "failed to get prompt", domainErr.Error()), nil
+	}
+	if !promptAllowed(prompt, ScopesFromContext(ctx)) {
+		return h.errorResponse(req.ID, CodePromptNotFound, fmt.Sprintf("prompt not found: %s", params.Name), nil), nil
+	}
+
+	args, err := convertArguments(prompt.Definition(), params.Arguments)
+	if err != nil {
+		return h.errorResponse(req.ID, CodeInvalidParams, "invalid prompt arguments", err.Error()), nil
+	}
+
+	result, err := prompt.Render(ctx, args)
+	if err != nil {
+		domainErr := internalerrors.New("mcp", "HandleRequest", internalerrors.ErrInternal, err)
+		return h.errorResponse(req.ID, CodeInternalError, "prompt rendering failed", domainErr.Error()), nil
+	}
+
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      req.ID,
+		Result:  result,
+	}, nil
+}
+
+// toolAllowed reports whether a caller granted the given scopes may call
+// tool.
+func toolAllowed(tool Tool, granted []string) bool {
+	scoped, ok := tool.(ScopedTool)
+	if !ok {
+		return true
+	}
+	return hasAllScopes(granted, scoped.RequiredScopes())
+}
+
+// promptAllowed reports whether a caller granted the given scopes may use
+// prompt.
+func promptAllowed(prompt Prompt, granted []string) bool {
+	scoped, ok := prompt.(ScopedPrompt)
+	if !ok {
+		return true
+	}
+	return hasAllScopes(granted, scoped.RequiredScopes())
+}
+
+// supportsStructuredContent reports whether the protocol version of the
+// request's session supports structured tool results.
+func supportsStructuredContent(ctx context.Context) bool {
+	session, ok := SessionFromContext(ctx)
+	return ok && session.Features().StructuredContent
+}
+
+// listCursor returns the cursor of a list request, or the error response
+// to send if its params are invalid.
+func (h *handler) listCursor(req *Request) (string, *Response) {
+	if req.Params == nil {
+		return "", nil
+	}
+
+	var params PaginatedParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return "", h.errorResponse(req.ID, CodeInvalidParams, fmt.Sprintf("invalid %s params", req.Method), err.Error())
+	}
+	return params.Cursor, nil
+}
+
 // errorResponse creates a JSON-RPC error response.
 func (h *handler) errorResponse(id any, code int, message string, data any) *Response {
 	return &Response{
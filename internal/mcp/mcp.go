@@ -20,6 +20,17 @@ type Handler interface {
 	HandleRequest(ctx context.Context, req *Request) (*Response, error)
 }
 
+// NotificationHandler is a Handler that also processes notifications from
+// the client. Transports type-assert for it; notifications sent to a
+// Handler without it are dropped.
+type NotificationHandler interface {
+	Handler
+
+	// HandleNotification processes a notification. Notifications receive
+	// no response, so errors are only logged by the transport.
+	HandleNotification(ctx context.Context, notification *Request) error
+}
+
 // Request represents an MCP JSON-RPC 2.0 request.
 type Request struct {
 	// JSONRPC is the JSON-RPC version, must be "2.0".
@@ -53,6 +64,79 @@ type Response struct {
 	Error *Error `json:"error,omitempty"`
 }
 
+// Notification represents a JSON-RPC 2.0 notification: a message without an
+// ID that expects no response.
+type Notification struct {
+	// JSONRPC is the JSON-RPC version, always "2.0".
+	JSONRPC string `json:"jsonrpc"`
+
+	// Method is the notification method name.
+	Method string `json:"method"`
+
+	// Params contains method-specific parameters.
+	Params any `json:"params,omitempty"`
+}
+
+// NewNotification creates a notification with the given method and params.
+func NewNotification(method string, params any) *Notification {
+	return &Notification{JSONRPC: JSONRPCVersion, Method: method, Params: params}
+}
+
+// Message is a JSON-RPC 2.0 message received from the client: a request,
+// a notification, or a response to a request the server sent. The ID is
+// kept raw so that a null ID can be told apart from a missing one.
+type Message struct {
+	// JSONRPC is the JSON-RPC version, must be "2.0".
+	JSONRPC string `json:"jsonrpc"`
+
+	// ID identifies a request or the request a response answers.
+	// Notifications have no ID.
+	ID json.RawMessage `json:"id,omitempty"`
+
+	// Method is the method of a request or notification.
+	Method string `json:"method,omitempty"`
+
+	// Params contains method-specific parameters as raw JSON.
+	Params json.RawMessage `json:"params,omitempty"`
+
+	// Result is the result of a successful response.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error is the error of a failed response.
+	Error *Error `json:"error,omitempty"`
+}
+
+// IsRequest reports whether the message is a request expecting a response.
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && m.ID != nil
+}
+
+// IsNotification reports whether the message is a notification, which
+// receives no response.
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && m.ID == nil
+}
+
+// IsResponse reports whether the message is a response to a request the
+// server sent.
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && m.ID != nil && (m.Result != nil || m.Error != nil)
+}
+
+// Request returns the message as a Request, decoding its ID.
+func (m *Message) Request() *Request {
+	var id any
+	if m.ID != nil {
+		_ = json.Unmarshal(m.ID, &id)
+	}
+	return &Request{
+		JSONRPC: m.JSONRPC,
+		ID:      id,
+		Method:  m.Method,
+		Params:  m.Params,
+	}
+}
+
 // Error represents a JSON-RPC 2.0 error object.
 type Error struct {
 	// Code is the error code indicating the error type.
@@ -70,8 +154,9 @@ type Error struct {
 
 // Protocol constants
 const (
-	// ProtocolVersion is the MCP protocol version this implementation supports.
-	ProtocolVersion = "2024-11-05"
+	// ProtocolVersion is the latest MCP protocol version this implementation
+	// supports, offered to clients requesting an unsupported version.
+	ProtocolVersion = ProtocolVersion20250618
 
 	// JSONRPCVersion is the JSON-RPC version used by MCP.
 	JSONRPCVersion = "2.0"
@@ -102,6 +187,9 @@ const (
 
 	// CodeToolNotFound indicates the requested tool was not found.
 	CodeToolNotFound = -32003
+
+	// CodePromptNotFound indicates the requested prompt was not found.
+	CodePromptNotFound = -32004
 )
 
 // ToolRegistry manages MCP tools.
@@ -112,6 +200,10 @@ type ToolRegistry interface {
 	// Returns an error if a tool with the same name is already registered.
 	RegisterTool(name string, tool Tool) error
 
+	// UnregisterTool removes the tool with the given name.
+	// Returns an error if the tool is not found.
+	UnregisterTool(name string) error
+
 	// GetTool retrieves a tool by name.
 	// Returns an error if the tool is not found.
 	GetTool(name string) (Tool, error)
@@ -119,6 +211,14 @@ type ToolRegistry interface {
 	// ListTools returns definitions for all registered tools.
 	// The returned slice should not be modified by the caller.
 	ListTools() []ToolDefinition
+
+	// ObserveChanges registers observer to be called after a tool is
+	// registered or unregistered. Observers must not block.
+	ObserveChanges(observer func())
+
+	// RequiredScopes returns the sorted union of the OAuth scopes declared
+	// by registered tools that implement ScopedTool.
+	RequiredScopes() []string
 }
 
 // Tool represents an executable MCP tool.
@@ -128,7 +228,11 @@ type Tool interface {
 	// The context can be used for cancellation and deadline propagation.
 	// Arguments are provided as a map of parameter names to values.
 	//
-	// Returns the tool result or an error if execution fails.
+	// Returns the tool result or an error if execution fails. The result
+	// may be a *ToolsCallResult, a Content or []Content built with the
+	// content builders, or a string returned as text. Any other value is
+	// returned as JSON text and, if it is a JSON object, as structured
+	// content.
 	Execute(ctx context.Context, args map[string]any) (any, error)
 
 	// Definition returns the tool's metadata including name, description,
@@ -136,6 +240,16 @@ type Tool interface {
 	Definition() ToolDefinition
 }
 
+// ScopedTool is a Tool that declares the OAuth scopes a caller needs.
+// Declared scopes are advertised in the protected resource metadata, and
+// the tool is hidden from callers not granted all of them.
+type ScopedTool interface {
+	Tool
+
+	// RequiredScopes returns the scopes required to call the tool.
+	RequiredScopes() []string
+}
+
 // ToolDefinition describes a tool's interface for client discovery.
 type ToolDefinition struct {
 	// Name is the unique identifier for this tool.
@@ -147,6 +261,13 @@ type ToolDefinition struct {
 	// InputSchema is a JSON Schema describing the tool's expected parameters.
 	// Should follow JSON Schema Draft 7 or later.
 	InputSchema map[string]any `json:"inputSchema"`
+
+	// OutputSchema is a JSON Schema the tool's structured content must
+	// match (optional). Results are validated against its type, enum,
+	// const, properties, required, additionalProperties, items and
+	// min/max keywords. It is only listed to sessions supporting
+	// structured content.
+	OutputSchema map[string]any `json:"outputSchema,omitempty"`
 }
 
 // ResourceRegistry manages MCP resources.
@@ -157,6 +278,10 @@ type ResourceRegistry interface {
 	// Returns an error if a resource with the same URI is already registered.
 	RegisterResource(uri string, provider ResourceProvider) error
 
+	// UnregisterResource removes the resource registered for the given URI.
+	// Returns an error if the resource is not found.
+	UnregisterResource(uri string) error
+
 	// GetResource retrieves a resource by URI and reads its content.
 	// The context can be used for cancellation and deadline propagation.
 	//
@@ -166,6 +291,47 @@ type ResourceRegistry interface {
 	// ListResources returns definitions for all registered resources.
 	// The returned slice should not be modified by the caller.
 	ListResources() []ResourceDefinition
+
+	// RegisterResourceTemplate registers a provider for the resources whose
+	// URIs match an RFC 6570 URI template.
+	// Returns an error if the template is invalid or already registered.
+	RegisterResourceTemplate(uriTemplate string, provider ResourceTemplateProvider) error
+
+	// UnregisterResourceTemplate removes the provider registered for the
+	// given URI template.
+	// Returns an error if the template is not found.
+	UnregisterResourceTemplate(uriTemplate string) error
+
+	// ListResourceTemplates returns definitions for all registered templates.
+	// The returned slice should not be modified by the caller.
+	ListResourceTemplates() []ResourceTemplateDefinition
+
+	// NotifyUpdated signals that the content of the resource at uri
+	// changed, so that subscribed clients are told to read it again.
+	NotifyUpdated(uri string)
+
+	// ObserveUpdates registers observer to be called with the URI of every
+	// changed resource. Observers must not block.
+	ObserveUpdates(observer func(uri string))
+
+	// ObserveChanges registers observer to be called after a resource or
+	// template is registered or unregistered. Observers must not block.
+	ObserveChanges(observer func())
+
+	// RequiredScopes returns the sorted union of the OAuth scopes declared
+	// by registered providers that implement ScopedResourceProvider or
+	// ScopedResourceTemplateProvider.
+	RequiredScopes() []string
+
+	// ResourceScopes returns the scopes required to read uri, declared by
+	// the provider GetResource would read it from.
+	// Returns nil if that provider declares none or no provider serves uri.
+	ResourceScopes(uri string) []string
+
+	// ResourceTemplateScopes returns the scopes declared by the provider
+	// registered for uriTemplate.
+	// Returns nil if that provider declares none or the template is not found.
+	ResourceTemplateScopes(uriTemplate string) []string
 }
 
 // ResourceProvider provides access to a specific resource.
@@ -181,6 +347,57 @@ type ResourceProvider interface {
 	Definition() ResourceDefinition
 }
 
+// ScopedResourceProvider is a ResourceProvider that declares the OAuth scopes
+// a caller needs. Declared scopes are advertised in the protected resource metadata,
+// and the resource is hidden from callers not granted all of them.
+type ScopedResourceProvider interface {
+	ResourceProvider
+
+	// RequiredScopes returns the scopes required to read the resource.
+	RequiredScopes() []string
+}
+
+// ResourceTemplateProvider provides access to the resources matching a URI
+// template, such as the files under file:///{+path}.
+//
+// GetResource reads a URI from a template provider when no resource is
+// registered under that exact URI. If several templates match, the one with
+// the most literal text is used, then the one with fewer {+var} expressions,
+// then the one registered first.
+type ResourceTemplateProvider interface {
+	// Read retrieves the current content of the resource at uri. Vars holds
+	// the percent-decoded values of the template's variables. No value holds
+	// "?", "#" or a "." or ".." path segment, and only a {+var} value may
+	// hold "/".
+	//
+	// Returns the resource content, or an error wrapping ErrResourceNotFound
+	// if no resource exists at uri.
+	Read(ctx context.Context, uri string, vars map[string]string) (*Resource, error)
+
+	// Definition returns the template's metadata for client discovery.
+	Definition() ResourceTemplateDefinition
+}
+
+// ScopedResourceTemplateProvider is a ResourceTemplateProvider that declares
+// the OAuth scopes a caller needs. The template and the resources read from
+// it are hidden from callers not granted all of them.
+type ScopedResourceTemplateProvider interface {
+	ResourceTemplateProvider
+
+	// RequiredScopes returns the scopes required to read the resources.
+	RequiredScopes() []string
+}
+
+// ResourceUpdateSource is implemented by resource providers and template
+// providers whose content changes, to signal the changes themselves.
+type ResourceUpdateSource interface {
+	// WatchUpdates is called each time the provider is registered. The
+	// provider calls updated with the URI of a resource whenever its content
+	// changes, from any goroutine. Calls made after the registration is
+	// removed are ignored.
+	WatchUpdates(updated func(uri string))
+}
+
 // Resource represents MCP resource content.
 type Resource struct {
 	// URI is the unique identifier for this resource.
@@ -209,6 +426,124 @@ type ResourceDefinition struct {
 	MimeType string `json:"mimeType,omitempty"`
 }
 
+// ResourceTemplateDefinition describes a resource template for client discovery.
+type ResourceTemplateDefinition struct {
+	// URITemplate is the RFC 6570 template of the resource URIs. Listings
+	// report the template the provider was registered for.
+	URITemplate string `json:"uriTemplate"`
+
+	// Name is a human-readable name for the resources.
+	Name string `json:"name"`
+
+	// Description explains what the resources provide (optional).
+	Description string `json:"description,omitempty"`
+
+	// MimeType indicates the content type of every matching resource (optional).
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// PromptRegistry manages MCP prompts.
+// Implementations must be thread-safe as prompts may be registered and
+// rendered concurrently.
+type PromptRegistry interface {
+	// RegisterPrompt registers a prompt with the given name.
+	// Returns an error if a prompt with the same name is already registered.
+	RegisterPrompt(name string, prompt Prompt) error
+
+	// UnregisterPrompt removes the prompt with the given name.
+	// Returns an error if the prompt is not found.
+	UnregisterPrompt(name string) error
+
+	// GetPrompt retrieves a prompt by name.
+	// Returns an error if the prompt is not found.
+	GetPrompt(name string) (Prompt, error)
+
+	// ListPrompts returns definitions for all registered prompts.
+	// The returned slice should not be modified by the caller.
+	ListPrompts() []PromptDefinition
+
+	// ObserveChanges registers observer to be called after a prompt is
+	// registered or unregistered. Observers must not block.
+	ObserveChanges(observer func())
+
+	// RequiredScopes returns the sorted union of the OAuth scopes declared
+	// by registered prompts that implement ScopedPrompt.
+	RequiredScopes() []string
+}
+
+// Prompt is a reusable prompt template that clients can fill in and send
+// to a model.
+type Prompt interface {
+	// Render produces the prompt's messages. Arguments have been checked
+	// against the definition and converted to their declared types: string,
+	// float64, int64 or bool. Optional arguments the client left out are
+	// absent.
+	Render(ctx context.Context, args map[string]any) (*PromptResult, error)
+
+	// Definition returns the prompt's metadata including name, description,
+	// and arguments for client discovery.
+	Definition() PromptDefinition
+}
+
+// ScopedPrompt is a Prompt that declares the OAuth scopes a caller needs.
+// Prompts are only listed to and rendered for callers holding all of them.
+type ScopedPrompt interface {
+	Prompt
+
+	// RequiredScopes returns the scopes required to use the prompt.
+	RequiredScopes() []string
+}
+
+// PromptDefinition describes a prompt for client discovery.
+type PromptDefinition struct {
+	// Name is the unique identifier for this prompt.
+	Name string `json:"name"`
+
+	// Description explains what the prompt is for (optional).
+	Description string `json:"description,omitempty"`
+
+	// Arguments are the values the client fills in (optional).
+	Arguments []PromptArgument `json:"arguments,omitempty"`
+}
+
+// ArgumentType is the type a prompt argument is converted to. Clients
+// always send arguments as strings.
+type ArgumentType string
+
+// Prompt argument types.
+const (
+	// ArgumentString passes the argument as a string. It is the default.
+	ArgumentString ArgumentType = "string"
+
+	// ArgumentNumber parses the argument as a float64.
+	ArgumentNumber ArgumentType = "number"
+
+	// ArgumentInteger parses the argument as an int64.
+	ArgumentInteger ArgumentType = "integer"
+
+	// ArgumentBoolean parses the argument as a bool.
+	ArgumentBoolean ArgumentType = "boolean"
+)
+
+// PromptArgument describes an argument of a prompt.
+type PromptArgument struct {
+	// Name is the argument name.
+	Name string `json:"name"`
+
+	// Description explains the argument (optional).
+	Description string `json:"description,omitempty"`
+
+	// Required indicates the argument must be provided.
+	Required bool `json:"required,omitempty"`
+
+	// Type is the type the argument is converted to before rendering.
+	// It is checked by the server and not sent to clients.
+	Type ArgumentType `json:"-"`
+
+	// Enum restricts the argument to the listed values (optional).
+	Enum []string `json:"-"`
+}
+
 // NewError creates a new Error with the given code, message, and optional data.
 func NewError(code int, message string, data any) *Error {
 	return &Error{Code: code, Message: message, Data: data}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Notification methods.
+const (
+	// MethodProgress is the method of progress notifications.
+	MethodProgress = "notifications/progress"
+
+	// MethodInitialized is sent by the client once initialization completes.
+	MethodInitialized = "notifications/initialized"
+
+	// MethodCancelled is sent by the client to cancel a request in flight.
+	MethodCancelled = "notifications/cancelled"
+
+	// MethodResourceUpdated tells a subscribed client that a resource changed.
+	MethodResourceUpdated = "notifications/resources/updated"
+
+	// MethodToolsListChanged tells the client that tools were added or removed.
+	MethodToolsListChanged = "notifications/tools/list_changed"
+
+	// MethodResourcesListChanged tells the client that resources or resource
+	// templates were added or removed.
+	MethodResourcesListChanged = "notifications/resources/list_changed"
+
+	// MethodPromptsListChanged tells the client that prompts were added or removed.
+	MethodPromptsListChanged = "notifications/prompts/list_changed"
+)
+
+// ContextWithSender returns a context whose server-to-client messages are
+// sent on sender, the stream answering the request being handled.
+func ContextWithSender(ctx context.Context, sender Sender) context.Context {
+	return context.WithValue(ctx, senderKey, sender)
+}
+
+// ContextWithProgressToken returns a context carrying the progress token
+// of the request being handled.
+func ContextWithProgressToken(ctx context.Context, token any) context.Context {
+	return context.WithValue(ctx, progressTokenKey, token)
+}
+
+// Notify sends a notification to the client while a request is handled.
+// It is delivered on the stream answering the request when the transport
+// provides one, and otherwise on the session's standalone stream.
+// Returns ErrNoStream if neither is open.
+func Notify(ctx context.Context, method string, params any) error {
+	notification := NewNotification(method, params)
+
+	if sender, ok := ctx.Value(senderKey).(Sender); ok && sender != nil {
+		return sender.Send(notification)
+	}
+	if session, ok := SessionFromContext(ctx); ok {
+		return session.Send(notification)
+	}
+	return ErrNoStream
+}
+
+// Call sends a request to the client while a request is handled and waits
+// for its response, for example to ask for sampling or elicitation. It is
+// sent like Notify, and the client POSTs the response back in the session.
+// Returns ErrNoSession outside of a session, as the response could not be
+// routed back.
+func Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	if sender, ok := ctx.Value(senderKey).(Sender); ok && sender != nil {
+		return session.call(ctx, sender.Send, method, params)
+	}
+	return session.Call(ctx, method, params)
+}
+
+// ReportProgress sends a progress notification for the request being
+// handled. It does nothing if the client did not ask for progress by
+// setting a progress token. Total is zero when unknown.
+func ReportProgress(ctx context.Context, progress, total float64, message string) error {
+	token := ctx.Value(progressTokenKey)
+	if token == nil {
+		return nil
+	}
+
+	return Notify(ctx, MethodProgress, ProgressParams{
+		ProgressToken: token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+)
+
+// DefaultPageSize is the default number of items per page of the list
+// methods.
+const DefaultPageSize = 100
+
+// paginate returns the page of items following cursor and the cursor of
+// the next page, empty on the last page. Items must be sorted by key.
+//
+// A cursor holds the key of the last item of its page rather than an
+// offset, so it stays valid while items are added or removed: the next
+// page starts at the first item sorting after that key.
+func paginate[T any](items []T, key func(T) string, cursor string, pageSize int) ([]T, string, error) {
+	start := 0
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			return key(items[i]) > after
+		})
+	}
+
+	end := start + pageSize
+	if end >= len(items) {
+		return items[start:], "", nil
+	}
+	return items[start:end], encodeCursor(key(items[end-1])), nil
+}
+
+// encodeCursor returns the opaque cursor of the page following key.
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeCursor returns the key held by cursor.
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(key) == 0 {
+		return "", fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return string(key), nil
+}
@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"a", "b", "c", "d", "e"}
+	identity := func(s string) string { return s }
+
+	var pages [][]string
+	cursor := ""
+	for {
+		page, next, err := paginate(items, identity, cursor, 2)
+		if err != nil {
+			t.Fatalf("paginate(%q) error = %v", cursor, err)
+		}
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	want := [][]string{{"a", "b"}, {"c", "d"}, {"e"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+
+	// A cursor stays valid when items around it are added or removed
+	_, next, err := paginate(items, identity, "", 2)
+	if err != nil {
+		t.Fatalf("paginate() error = %v", err)
+	}
+	mutated := []string{"a", "aa", "c", "cc", "d"}
+	page, _, err := paginate(mutated, identity, next, 2)
+	if err != nil {
+		t.Fatalf("paginate() after mutation error = %v", err)
+	}
+	if !reflect.DeepEqual(page, []string{"c", "cc"}) {
+		t.Errorf("page after mutation = %v, want [c cc]", page)
+	}
+
+	// An exact page ends without a next cursor
+	if _, next, _ := paginate(items[:4], identity, "", 2); next == "" {
+		t.Error("first of two full pages has no next cursor")
+	}
+	if _, next, _ := paginate(items[:2], identity, "", 2); next != "" {
+		t.Errorf("single full page next cursor = %q, want none", next)
+	}
+
+	for _, cursor := range []string{"!!!", "a b"} {
+		if _, _, err := paginate(items, identity, cursor, 2); err == nil {
+			t.Errorf("paginate(%q) error = nil, want an error", cursor)
+		}
+	}
+}
+
+func TestHandler_ListPagination(t *testing.T) {
+	t.Parallel()
+
+	handler, tools, _, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0", PageSize: 2})
+	for i := 4; i >= 0; i-- {
+		name := fmt.Sprintf("tool-%d", i)
+		if err := tools.RegisterTool(name, &scopedTool{name: name}); err != nil {
+			t.Fatalf("RegisterTool() error = %v", err)
+		}
+	}
+
+	session, err := NewSessionManager(0).Create(SessionOwner{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ctx := ContextWithSession(context.Background(), session)
+	if resp, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 1, Method: "initialize"}); err != nil || resp.Error != nil {
+		t.Fatalf("initialize = %v, %v", resp, err)
+	}
+
+	listTools := func(cursor string) *Response {
+		t.Helper()
+
+		params, _ := json.Marshal(PaginatedParams{Cursor: cursor})
+		resp, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 2, Method: "tools/list", Params: params})
+		if err != nil {
+			t.Fatalf("tools/list error = %v", err)
+		}
+		return resp
+	}
+
+	var names []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("tools/list did not reach the last page")
+		}
+		resp := listTools(cursor)
+		if resp.Error != nil {
+			t.Fatalf("tools/list error = %v", resp.Error)
+		}
+		result := resp.Result.(ToolsListResult)
+		if len(result.Tools) > 2 {
+			t.Errorf("page of %d tools, want at most 2", len(result.Tools))
+		}
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	want := []string{"tool-0", "tool-1", "tool-2", "tool-3", "tool-4"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("listed tools = %v, want %v", names, want)
+	}
+
+	if resp := listTools("not a cursor"); resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("tools/list with an invalid cursor error = %v, want invalid params", resp.Error)
+	}
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// PromptMessageTemplate is a message of a template prompt. Text is a
+// text/template executed with the prompt's arguments, so an argument named
+// topic is written {{.topic}}. Optional arguments the client left out are
+// empty strings; referring to an undeclared argument fails rendering.
+type PromptMessageTemplate struct {
+	// Role is the speaker of the message, "user" or "assistant".
+	Role string
+
+	// Text is the template of the message text.
+	Text string
+}
+
+// templatePrompt is a ScopedPrompt rendered from message templates.
+type templatePrompt struct {
+	definition PromptDefinition
+	scopes     []string
+	roles      []string
+	templates  []*template.Template
+}
+
+// NewTemplatePrompt creates a prompt that renders each message template
+// with the prompt's arguments, in order. Callers need all of scopes to use
+// the prompt; nil scopes leave it unrestricted. Returns an error if a
+// template does not parse or a role is not "user" or "assistant".
+func NewTemplatePrompt(definition PromptDefinition, scopes []string, messages ...PromptMessageTemplate) (ScopedPrompt, error) {
+	if definition.Name == "" {
+		return nil, fmt.Errorf("prompt name cannot be empty")
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("prompt %q has no messages", definition.Name)
+	}
+
+	prompt := &templatePrompt{
+		definition: definition,
+		scopes:     scopes,
+	}
+	for i, message := range messages {
+		if message.Role != "user" && message.Role != "assistant" {
+			return nil, fmt.Errorf("prompt %q message %d: invalid role %q", definition.Name, i, message.Role)
+		}
+		tmpl, err := template.New(fmt.Sprintf("%s[%d]", definition.Name, i)).
+			Option("missingkey=error").
+			Parse(message.Text)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %q message %d: %w", definition.Name, i, err)
+		}
+		prompt.roles = append(prompt.roles, message.Role)
+		prompt.templates = append(prompt.templates, tmpl)
+	}
+
+	return prompt, nil
+}
+
+// Render executes the message templates with args.
+func (p *templatePrompt) Render(ctx context.Context, args map[string]any) (*PromptResult, error) {
+	data := make(map[string]any, len(p.definition.Arguments))
+	for _, argument := range p.definition.Arguments {
+		data[argument.Name] = ""
+	}
+	for name, value := range args {
+		data[name] = value
+	}
+
+	result := &PromptResult{
+		Description: p.definition.Description,
+		Messages:    make([]PromptMessage, 0, len(p.templates)),
+	}
+	for i, tmpl := range p.templates {
+		var text strings.Builder
+		if err := tmpl.Execute(&text, data); err != nil {
+			return nil, fmt.Errorf("failed to render prompt %q: %w", p.definition.Name, err)
+		}
+		result.Messages = append(result.Messages, PromptMessage{
+			Role:    p.roles[i],
+			Content: NewTextContent(text.String()),
+		})
+	}
+	return result, nil
+}
+
+// Definition returns the prompt's definition.
+func (p *templatePrompt) Definition() PromptDefinition {
+	return p.definition
+}
+
+// RequiredScopes returns the scopes required to use the prompt.
+func (p *templatePrompt) RequiredScopes() []string {
+	return p.scopes
+}
+
+// convertArguments checks the string arguments sent by the client against
+// the prompt's definition and converts them to their declared types.
+// Returns an error naming the first missing, unknown or invalid argument.
+func convertArguments(definition PromptDefinition, raw map[string]string) (map[string]any, error) {
+	args := make(map[string]any, len(raw))
+	declared := make(map[string]bool, len(definition.Arguments))
+
+	for _, argument := range definition.Arguments {
+		declared[argument.Name] = true
+
+		value, ok := raw[argument.Name]
+		if !ok {
+			if argument.Required {
+				return nil, fmt.Errorf("missing required argument %q", argument.Name)
+			}
+			continue
+		}
+
+		if len(argument.Enum) > 0 && !slices.Contains(argument.Enum, value) {
+			return nil, fmt.Errorf("argument %q must be one of %s", argument.Name, strings.Join(argument.Enum, ", "))
+		}
+
+		converted, err := convertArgument(argument.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", argument.Name, err)
+		}
+		args[argument.Name] = converted
+	}
+
+	for name := range raw {
+		if !declared[name] {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+
+	return args, nil
+}
+
+// convertArgument converts a string argument to typ.
+func convertArgument(typ ArgumentType, value string) (any, error) {
+	switch typ {
+	case "", ArgumentString:
+		return value, nil
+	case ArgumentNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return number, nil
+	case ArgumentInteger:
+		integer, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return integer, nil
+	case ArgumentBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return boolean, nil
+	default:
+		return nil, fmt.Errorf("unsupported argument type %q", typ)
+	}
+}
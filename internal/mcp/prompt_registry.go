@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	internalerrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
+)
+
+// promptRegistry implements PromptRegistry with thread-safe access.
+type promptRegistry struct {
+	mu      sync.RWMutex
+	prompts map[string]Prompt
+	changes changeObservers
+}
+
+// NewPromptRegistry creates a new thread-safe prompt registry.
+func NewPromptRegistry() PromptRegistry {
+	return &promptRegistry{
+		prompts: make(map[string]Prompt),
+	}
+}
+
+// RegisterPrompt registers a prompt with the given name.
+// Returns an error if a prompt with the same name is already registered
+// or if the prompt or name is invalid.
+func (r *promptRegistry) RegisterPrompt(name string, prompt Prompt) error {
+	if name == "" {
+		return internalerrors.New("mcp", "RegisterPrompt", internalerrors.ErrBadRequest, fmt.Errorf("prompt name cannot be empty"))
+	}
+	if prompt == nil {
+		return internalerrors.New("mcp", "RegisterPrompt", internalerrors.ErrBadRequest, fmt.Errorf("prompt cannot be nil"))
+	}
+
+	r.mu.Lock()
+	if _, exists := r.prompts[name]; exists {
+		r.mu.Unlock()
+		return internalerrors.New("mcp", "RegisterPrompt", internalerrors.ErrBadRequest, ErrPromptAlreadyRegistered).
+			WithContext("prompt_name", name)
+	}
+	r.prompts[name] = prompt
+	r.mu.Unlock()
+
+	r.changes.notify()
+	return nil
+}
+
+// UnregisterPrompt removes the prompt with the given name.
+// Returns ErrPromptNotFound if the prompt does not exist.
+func (r *promptRegistry) UnregisterPrompt(name string) error {
+	r.mu.Lock()
+	if _, exists := r.prompts[name]; !exists {
+		r.mu.Unlock()
+		return internalerrors.New("mcp", "UnregisterPrompt", internalerrors.ErrNotFound, ErrPromptNotFound).
+			WithContext("prompt_name", name)
+	}
+	delete(r.prompts, name)
+	r.mu.Unlock()
+
+	r.changes.notify()
+	return nil
+}
+
+// GetPrompt retrieves a prompt by name.
+// Returns ErrPromptNotFound if the prompt does not exist.
+func (r *promptRegistry) GetPrompt(name string) (Prompt, error) {
+	if name == "" {
+		return nil, internalerrors.New("mcp", "GetPrompt", internalerrors.ErrBadRequest, fmt.Errorf("prompt name cannot be empty"))
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prompt, exists := r.prompts[name]
+	if !exists {
+		return nil, internalerrors.New("mcp", "GetPrompt", internalerrors.ErrNotFound, ErrPromptNotFound).
+			WithContext("prompt_name", name)
+	}
+
+	return prompt, nil
+}
+
+// ListPrompts returns definitions for all registered prompts, sorted by
+// name. The returned slice is a snapshot and safe for concurrent access.
+func (r *promptRegistry) ListPrompts() []PromptDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]PromptDefinition, 0, len(r.prompts))
+	for _, prompt := range r.prompts {
+		definitions = append(definitions, prompt.Definition())
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
+
+// ObserveChanges registers an observer of prompt registrations and removals.
+func (r *promptRegistry) ObserveChanges(observer func()) {
+	r.changes.add(observer)
+}
+
+// RequiredScopes returns the sorted union of the scopes declared by registered prompts.
+func (r *promptRegistry) RequiredScopes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var declared [][]string
+	for _, prompt := range r.prompts {
+		if scoped, ok := prompt.(ScopedPrompt); ok {
+			declared = append(declared, scoped.RequiredScopes())
+		}
+	}
+
+	return unionScopes(declared...)
+}
@@ -0,0 +1,321 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reviewPrompt returns a template prompt with typed arguments for testing.
+func reviewPrompt(t *testing.T, scopes ...string) ScopedPrompt {
+	t.Helper()
+
+	prompt, err := NewTemplatePrompt(PromptDefinition{
+		Name:        "review",
+		Description: "Review a change",
+		Arguments: []PromptArgument{
+			{Name: "language", Required: true, Enum: []string{"go", "rust"}},
+			{Name: "lines", Type: ArgumentInteger},
+			{Name: "strict", Type: ArgumentBoolean},
+		},
+	}, scopes,
+		PromptMessageTemplate{Role: "user", Text: "Review this {{.language}} change of {{.lines}} lines."},
+		PromptMessageTemplate{Role: "assistant", Text: "{{if .strict}}Strictly.{{else}}Gently.{{end}}"},
+	)
+	if err != nil {
+		t.Fatalf("NewTemplatePrompt() error = %v", err)
+	}
+	return prompt
+}
+
+func TestNewTemplatePrompt_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		definition PromptDefinition
+		messages   []PromptMessageTemplate
+	}{
+		{
+			name:       "empty name",
+			definition: PromptDefinition{},
+			messages:   []PromptMessageTemplate{{Role: "user", Text: "hi"}},
+		},
+		{
+			name:       "no messages",
+			definition: PromptDefinition{Name: "empty"},
+		},
+		{
+			name:       "invalid role",
+			definition: PromptDefinition{Name: "system"},
+			messages:   []PromptMessageTemplate{{Role: "system", Text: "hi"}},
+		},
+		{
+			name:       "invalid template",
+			definition: PromptDefinition{Name: "broken"},
+			messages:   []PromptMessageTemplate{{Role: "user", Text: "{{.topic"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := NewTemplatePrompt(tt.definition, nil, tt.messages...); err == nil {
+				t.Error("NewTemplatePrompt() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestTemplatePrompt_Render(t *testing.T) {
+	t.Parallel()
+
+	prompt := reviewPrompt(t)
+
+	result, err := prompt.Render(context.Background(), map[string]any{"language": "go", "strict": true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := &PromptResult{
+		Description: "Review a change",
+		Messages: []PromptMessage{
+			{Role: "user", Content: Content{Type: "text", Text: "Review this go change of  lines."}},
+			{Role: "assistant", Content: Content{Type: "text", Text: "Strictly."}},
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Render() = %+v, want %+v", result, want)
+	}
+
+	// Templates may only refer to declared arguments
+	undeclared, err := NewTemplatePrompt(PromptDefinition{Name: "typo"}, nil,
+		PromptMessageTemplate{Role: "user", Text: "{{.topik}}"})
+	if err != nil {
+		t.Fatalf("NewTemplatePrompt() error = %v", err)
+	}
+	if _, err := undeclared.Render(context.Background(), nil); err == nil {
+		t.Error("Render() with an undeclared argument error = nil, want an error")
+	}
+}
+
+func TestConvertArguments(t *testing.T) {
+	t.Parallel()
+
+	definition := reviewPrompt(t).Definition()
+
+	tests := []struct {
+		name    string
+		raw     map[string]string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "typed values",
+			raw:  map[string]string{"language": "rust", "lines": "42", "strict": "true"},
+			want: map[string]any{"language": "rust", "lines": int64(42), "strict": true},
+		},
+		{
+			name: "optional arguments omitted",
+			raw:  map[string]string{"language": "go"},
+			want: map[string]any{"language": "go"},
+		},
+		{
+			name:    "missing required argument",
+			raw:     map[string]string{"lines": "1"},
+			wantErr: `missing required argument "language"`,
+		},
+		{
+			name:    "value outside enum",
+			raw:     map[string]string{"language": "cobol"},
+			wantErr: `argument "language" must be one of go, rust`,
+		},
+		{
+			name:    "invalid integer",
+			raw:     map[string]string{"language": "go", "lines": "many"},
+			wantErr: `"many" is not an integer`,
+		},
+		{
+			name:    "invalid boolean",
+			raw:     map[string]string{"language": "go", "strict": "sometimes"},
+			wantErr: `"sometimes" is not a boolean`,
+		},
+		{
+			name:    "unknown argument",
+			raw:     map[string]string{"language": "go", "color": "blue"},
+			wantErr: `unknown argument "color"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := convertArguments(definition, tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("convertArguments() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convertArguments() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertArguments() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewPromptRegistry()
+	if err := registry.RegisterPrompt("review", reviewPrompt(t, "code:read")); err != nil {
+		t.Fatalf("RegisterPrompt() error = %v", err)
+	}
+
+	if err := registry.RegisterPrompt("review", reviewPrompt(t)); !errors.Is(err, ErrPromptAlreadyRegistered) {
+		t.Errorf("RegisterPrompt() duplicate error = %v, want %v", err, ErrPromptAlreadyRegistered)
+	}
+	if err := registry.RegisterPrompt("", reviewPrompt(t)); err == nil {
+		t.Error("RegisterPrompt() with empty name error = nil, want an error")
+	}
+	if err := registry.RegisterPrompt("nil", nil); err == nil {
+		t.Error("RegisterPrompt() with nil prompt error = nil, want an error")
+	}
+
+	if _, err := registry.GetPrompt("missing"); !errors.Is(err, ErrPromptNotFound) {
+		t.Errorf("GetPrompt() error = %v, want %v", err, ErrPromptNotFound)
+	}
+	if definitions := registry.ListPrompts(); len(definitions) != 1 || definitions[0].Name != "review" {
+		t.Errorf("ListPrompts() = %+v, want the review prompt", definitions)
+	}
+	if scopes := registry.RequiredScopes(); !reflect.DeepEqual(scopes, []string{"code:read"}) {
+		t.Errorf("RequiredScopes() = %v, want [code:read]", scopes)
+	}
+}
+
+func TestHandler_Prompts(t *testing.T) {
+	t.Parallel()
+
+	handler, _, _, prompts := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+	manager := NewSessionManager(time.Hour)
+
+	// initialize returns the capabilities known when it runs
+	initialize := func(t *testing.T) (context.Context, InitializeResult) {
+		t.Helper()
+
+		session, err := manager.Create(SessionOwner{Subject: "alice"})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ctx := ContextWithSession(context.Background(), session)
+
+		resp, err := handler.HandleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      1,
+			Method:  "initialize",
+			Params:  json.RawMessage(`{"protocolVersion":"2025-06-18"}`),
+		})
+		if err != nil || resp.Error != nil {
+			t.Fatalf("initialize = %v, %v", resp, err)
+		}
+		return ctx, resp.Result.(InitializeResult)
+	}
+
+	// The capability is only advertised with prompts registered
+	if _, result := initialize(t); result.Capabilities.Prompts != nil {
+		t.Errorf("Capabilities.Prompts = %+v without prompts, want nil", result.Capabilities.Prompts)
+	}
+
+	if err := prompts.RegisterPrompt("review", reviewPrompt(t)); err != nil {
+		t.Fatalf("RegisterPrompt() error = %v", err)
+	}
+	admin, err := NewTemplatePrompt(PromptDefinition{Name: "audit"}, []string{"mcp:admin"},
+		PromptMessageTemplate{Role: "user", Text: "Audit the logs."})
+	if err != nil {
+		t.Fatalf("NewTemplatePrompt() error = %v", err)
+	}
+	if err := prompts.RegisterPrompt("audit", admin); err != nil {
+		t.Fatalf("RegisterPrompt() error = %v", err)
+	}
+
+	ctx, result := initialize(t)
+	if result.Capabilities.Prompts == nil {
+		t.Error("Capabilities.Prompts = nil with prompts registered")
+	}
+
+	call := func(ctx context.Context, method, params string) *Response {
+		t.Helper()
+
+		resp, err := handler.HandleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      2,
+			Method:  method,
+			Params:  json.RawMessage(params),
+		})
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		return resp
+	}
+
+	listed := func(ctx context.Context) []string {
+		t.Helper()
+
+		resp := call(ctx, "prompts/list", `{}`)
+		if resp.Error != nil {
+			t.Fatalf("prompts/list error = %v", resp.Error)
+		}
+		var names []string
+		for _, definition := range resp.Result.(PromptsListResult).Prompts {
+			names = append(names, definition.Name)
+		}
+		return names
+	}
+
+	// Prompts are filtered by the caller's scopes
+	if names := listed(ContextWithScopes(ctx, []string{"mcp:read"})); !reflect.DeepEqual(names, []string{"review"}) {
+		t.Errorf("prompts/list without mcp:admin = %v, want [review]", names)
+	}
+	if names := listed(ContextWithScopes(ctx, []string{"mcp:admin"})); len(names) != 2 {
+		t.Errorf("prompts/list with mcp:admin = %v, want both prompts", names)
+	}
+	if resp := call(ctx, "prompts/get", `{"name":"audit"}`); resp.Error == nil || resp.Error.Code != CodePromptNotFound {
+		t.Errorf("prompts/get without scopes error = %v, want prompt not found", resp.Error)
+	}
+	if resp := call(ContextWithScopes(ctx, []string{"mcp:admin"}), "prompts/get", `{"name":"audit"}`); resp.Error != nil {
+		t.Errorf("prompts/get with mcp:admin error = %v", resp.Error)
+	}
+
+	resp := call(ctx, "prompts/get", `{"name":"review","arguments":{"language":"go","lines":"3"}}`)
+	if resp.Error != nil {
+		t.Fatalf("prompts/get error = %v", resp.Error)
+	}
+	rendered := resp.Result.(*PromptResult)
+	if len(rendered.Messages) != 2 || rendered.Messages[0].Content.Text != "Review this go change of 3 lines." {
+		t.Errorf("prompts/get messages = %+v", rendered.Messages)
+	}
+
+	tests := []struct {
+		name   string
+		params string
+		code   int
+	}{
+		{"unknown prompt", `{"name":"missing"}`, CodePromptNotFound},
+		{"missing name", `{}`, CodeInvalidParams},
+		{"invalid arguments", `{"name":"review","arguments":{"language":"cobol"}}`, CodeInvalidParams},
+	}
+	for _, tt := range tests {
+		if resp := call(ctx, "prompts/get", tt.params); resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s: prompts/get error = %v, want code %d", tt.name, resp.Error, tt.code)
+		}
+	}
+}
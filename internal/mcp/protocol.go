@@ -28,6 +28,10 @@ type ClientCapabilities struct {
 
 	// Sampling indicates if the client supports sampling.
 	Sampling *SamplingCapability `json:"sampling,omitempty"`
+
+	// Elicitation indicates if the client supports elicitation requests
+	// (protocol version 2025-06-18 and later).
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
 }
 
 // RootsCapability indicates roots support.
@@ -38,6 +42,9 @@ type RootsCapability struct {
 // SamplingCapability indicates sampling support.
 type SamplingCapability struct{}
 
+// ElicitationCapability indicates elicitation support.
+type ElicitationCapability struct{}
+
 // InitializeResult is the result of the initialize method.
 type InitializeResult struct {
 	// ProtocolVersion is the MCP protocol version the server supports.
@@ -93,10 +100,19 @@ type PromptsCapability struct {
 // LoggingCapability indicates logging support.
 type LoggingCapability struct{}
 
+// PaginatedParams contains the parameters of the list methods.
+type PaginatedParams struct {
+	// Cursor is the nextCursor of the previous page; empty for the first page.
+	Cursor string `json:"cursor,omitempty"`
+}
+
 // ToolsListResult is the result of the tools/list method.
 type ToolsListResult struct {
 	// Tools is the list of available tools.
 	Tools []ToolDefinition `json:"tools"`
+
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // ToolsCallParams contains parameters for the tools/call method.
@@ -106,39 +122,94 @@ type ToolsCallParams struct {
 
 	// Arguments contains the tool-specific arguments.
 	Arguments map[string]any `json:"arguments,omitempty"`
+
+	// Meta carries request metadata such as the progress token.
+	Meta *RequestMeta `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the _meta object of request params.
+type RequestMeta struct {
+	// ProgressToken is the token the client wants progress notifications
+	// for the request to carry. A string or number.
+	ProgressToken any `json:"progressToken,omitempty"`
+}
+
+// ProgressParams are the params of a notifications/progress notification.
+type ProgressParams struct {
+	// ProgressToken is the token from the request's _meta.
+	ProgressToken any `json:"progressToken"`
+
+	// Progress is the progress so far. It increases with every notification.
+	Progress float64 `json:"progress"`
+
+	// Total is the total amount of work, if known.
+	Total float64 `json:"total,omitempty"`
+
+	// Message describes the current progress (optional).
+	Message string `json:"message,omitempty"`
 }
 
-// ToolsCallResult is the result of the tools/call method.
+// ToolsCallResult is the result of the tools/call method. Tools may return
+// it from Execute to control the result completely.
 type ToolsCallResult struct {
 	// Content contains the tool execution results.
 	Content []Content `json:"content"`
 
+	// StructuredContent is the result as a JSON object, matching the
+	// tool's output schema if it declares one (protocol version
+	// 2025-06-18 and later).
+	StructuredContent any `json:"structuredContent,omitempty"`
+
 	// IsError indicates if the tool execution failed.
 	IsError bool `json:"isError,omitempty"`
 }
 
-// Content represents a piece of content in a tool result.
+// Content represents a piece of content in a tool result or prompt
+// message. Use the NewTextContent, NewImageContent, NewAudioContent,
+// NewResourceContent and NewResourceLinkContent builders.
 type Content struct {
-	// Type is the content type (e.g., "text", "image", "resource").
+	// Type is the content type: "text", "image", "audio", "resource" or
+	// "resource_link".
 	Type string `json:"type"`
 
 	// Text contains text content (for type "text").
 	Text string `json:"text,omitempty"`
 
-	// Data contains base64-encoded binary data (for type "image", "blob").
+	// Data contains base64-encoded binary data (for types "image" and "audio").
 	Data string `json:"data,omitempty"`
 
-	// MimeType indicates the MIME type (for binary content).
+	// MimeType indicates the MIME type (for binary content and resource links).
 	MimeType string `json:"mimeType,omitempty"`
 
-	// URI references a resource (for type "resource").
+	// URI references a resource (for type "resource_link").
 	URI string `json:"uri,omitempty"`
+
+	// Name is the name of a linked resource (for type "resource_link").
+	Name string `json:"name,omitempty"`
+
+	// Description describes a linked resource (for type "resource_link").
+	Description string `json:"description,omitempty"`
+
+	// Resource is an embedded resource (for type "resource").
+	Resource *ResourceContent `json:"resource,omitempty"`
 }
 
 // ResourcesListResult is the result of the resources/list method.
 type ResourcesListResult struct {
 	// Resources is the list of available resources.
 	Resources []ResourceDefinition `json:"resources"`
+
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ResourceTemplatesListResult is the result of the resources/templates/list method.
+type ResourceTemplatesListResult struct {
+	// ResourceTemplates is the list of available resource templates.
+	ResourceTemplates []ResourceTemplateDefinition `json:"resourceTemplates"`
+
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // ResourcesReadParams contains parameters for the resources/read method.
@@ -147,6 +218,23 @@ type ResourcesReadParams struct {
 	URI string `json:"uri"`
 }
 
+// ResourcesSubscribeParams contains parameters for the resources/subscribe
+// and resources/unsubscribe methods.
+type ResourcesSubscribeParams struct {
+	// URI is the resource URI to (un)subscribe to.
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams are the params of a notifications/resources/updated
+// notification.
+type ResourceUpdatedParams struct {
+	// URI is the URI of the resource whose content changed.
+	URI string `json:"uri"`
+}
+
+// EmptyResult is the result of methods that return nothing.
+type EmptyResult struct{}
+
 // ResourcesReadResult is the result of the resources/read method.
 type ResourcesReadResult struct {
 	// Contents contains the resource content.
@@ -167,3 +255,49 @@ type ResourceContent struct {
 	// Blob contains base64-encoded binary content.
 	Blob string `json:"blob,omitempty"`
 }
+
+// PromptsListResult is the result of the prompts/list method.
+type PromptsListResult struct {
+	// Prompts is the list of available prompts.
+	Prompts []PromptDefinition `json:"prompts"`
+
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// PromptsGetParams contains parameters for the prompts/get method.
+type PromptsGetParams struct {
+	// Name is the name of the prompt to render.
+	Name string `json:"name"`
+
+	// Arguments are the argument values, always strings on the wire.
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptResult is the result of the prompts/get method: the rendered
+// messages of a prompt.
+type PromptResult struct {
+	// Description describes the rendered prompt (optional).
+	Description string `json:"description,omitempty"`
+
+	// Messages are the prompt's messages in order.
+	Messages []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a message of a rendered prompt.
+type PromptMessage struct {
+	// Role is the speaker of the message, "user" or "assistant".
+	Role string `json:"role"`
+
+	// Content is the content of the message.
+	Content Content `json:"content"`
+}
+
+// CancelledParams are the params of a notifications/cancelled notification.
+type CancelledParams struct {
+	// RequestID is the ID of the request to cancel.
+	RequestID any `json:"requestId"`
+
+	// Reason optionally explains why the request was cancelled.
+	Reason string `json:"reason,omitempty"`
+}
@@ -1,4 +1,68 @@
 package mcp
 
-// This file is intentionally empty.
-// Registry implementations are in tool_registry.go and resource_registry.go
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Registry implementations are in tool_registry.go, resource_registry.go and
+// prompt_registry.go.
+
+// unionScopes merges scope lists into a sorted list without duplicates or empty values.
+func unionScopes(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, list := range lists {
+		for _, scope := range list {
+			if scope == "" || seen[scope] {
+				continue
+			}
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	sort.Strings(scopes)
+	return scopes
+}
+
+// hasAllScopes reports whether granted contains every scope in required.
+func hasAllScopes(granted, required []string) bool {
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// changeObservers holds the observers a registry calls after its list of
+// registered items changed.
+type changeObservers struct {
+	mu        sync.Mutex
+	observers []func()
+}
+
+// add registers an observer. Nil observers are ignored.
+func (o *changeObservers) add(observer func()) {
+	if observer == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observers = append(o.observers, observer)
+}
+
+// notify calls every observer. Registries call it without holding their
+// own lock, so that observers may read the registry.
+func (o *changeObservers) notify() {
+	o.mu.Lock()
+	observers := o.observers
+	o.mu.Unlock()
+
+	for _, observer := range observers {
+		observer()
+	}
+}
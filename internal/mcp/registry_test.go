@@ -0,0 +1,468 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// scopedTool is a ScopedTool for testing.
+type scopedTool struct {
+	name   string
+	scopes []string
+}
+
+func (t *scopedTool) Execute(ctx context.Context, args map[string]any) (any, error) {
+	return nil, nil
+}
+
+func (t *scopedTool) Definition() ToolDefinition {
+	return ToolDefinition{Name: t.name}
+}
+
+func (t *scopedTool) RequiredScopes() []string {
+	return t.scopes
+}
+
+// plainTool is a Tool that declares no scopes.
+type plainTool struct{}
+
+func (plainTool) Execute(ctx context.Context, args map[string]any) (any, error) {
+	return nil, nil
+}
+
+func (plainTool) Definition() ToolDefinition {
+	return ToolDefinition{Name: "plain"}
+}
+
+// scopedProvider is a ScopedResourceProvider for testing.
+type scopedProvider struct {
+	uri    string
+	scopes []string
+}
+
+func (p *scopedProvider) Read(ctx context.Context) (*Resource, error) {
+	return &Resource{URI: p.uri}, nil
+}
+
+func (p *scopedProvider) Definition() ResourceDefinition {
+	return ResourceDefinition{URI: p.uri}
+}
+
+func (p *scopedProvider) RequiredScopes() []string {
+	return p.scopes
+}
+
+func TestToolRegistry_RequiredScopes(t *testing.T) {
+	t.Parallel()
+
+	registry := NewToolRegistry()
+	if got := registry.RequiredScopes(); len(got) != 0 {
+		t.Errorf("RequiredScopes() on empty registry = %v, want none", got)
+	}
+
+	tools := map[string]Tool{
+		"write":  &scopedTool{name: "write", scopes: []string{"mcp:write", "mcp:read"}},
+		"delete": &scopedTool{name: "delete", scopes: []string{"mcp:admin", "mcp:write", ""}},
+		"plain":  plainTool{},
+	}
+	for name, tool := range tools {
+		if err := registry.RegisterTool(name, tool); err != nil {
+			t.Fatalf("RegisterTool(%q) unexpected error: %v", name, err)
+		}
+	}
+
+	want := []string{"mcp:admin", "mcp:read", "mcp:write"}
+	if got := registry.RequiredScopes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredScopes() = %v, want %v", got, want)
+	}
+}
+
+func TestResourceRegistry_RequiredScopes(t *testing.T) {
+	t.Parallel()
+
+	registry := NewResourceRegistry()
+	providers := []*scopedProvider{
+		{uri: "file:///reports", scopes: []string{"reports:read"}},
+		{uri: "file:///audit", scopes: []string{"audit:read", "reports:read"}},
+	}
+	for _, provider := range providers {
+		if err := registry.RegisterResource(provider.uri, provider); err != nil {
+			t.Fatalf("RegisterResource(%q) unexpected error: %v", provider.uri, err)
+		}
+	}
+
+	want := []string{"audit:read", "reports:read"}
+	if got := registry.RequiredScopes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredScopes() = %v, want %v", got, want)
+	}
+}
+
+// templateProvider is a ScopedResourceTemplateProvider for testing that
+// reads as its template and the matched variables.
+type templateProvider struct {
+	template string
+	scopes   []string
+	missing  string
+}
+
+func (p *templateProvider) Read(ctx context.Context, uri string, vars map[string]string) (*Resource, error) {
+	if uri == p.missing {
+		return nil, fmt.Errorf("no row at %s: %w", uri, ErrResourceNotFound)
+	}
+	return &Resource{Text: fmt.Sprintf("%s %v", p.template, vars)}, nil
+}
+
+func (p *templateProvider) Definition() ResourceTemplateDefinition {
+	return ResourceTemplateDefinition{URITemplate: p.template, Name: p.template}
+}
+
+func (p *templateProvider) RequiredScopes() []string {
+	return p.scopes
+}
+
+func TestResourceRegistry_Templates(t *testing.T) {
+	t.Parallel()
+
+	registry := NewResourceRegistry()
+	if err := registry.RegisterResource("file:///etc/motd", &scopedProvider{uri: "file:///etc/motd"}); err != nil {
+		t.Fatalf("RegisterResource() error = %v", err)
+	}
+
+	templates := []*templateProvider{
+		{template: "file:///{+path}", scopes: []string{"files:read"}},
+		{template: "file:///etc/{name}"},
+		{template: "file:///{dir}/{name}"},
+		{template: "db://tables/{table}/rows/{id}", scopes: []string{"db:read"}, missing: "db://tables/users/rows/0"},
+		{template: "db://tables/{table}/rows/{+id}"},
+	}
+	for _, provider := range templates {
+		if err := registry.RegisterResourceTemplate(provider.template, provider); err != nil {
+			t.Fatalf("RegisterResourceTemplate(%q) error = %v", provider.template, err)
+		}
+	}
+
+	if err := registry.RegisterResourceTemplate("file:///{+path}", templates[0]); !errors.Is(err, ErrResourceAlreadyRegistered) {
+		t.Errorf("RegisterResourceTemplate() duplicate error = %v, want %v", err, ErrResourceAlreadyRegistered)
+	}
+	if err := registry.RegisterResourceTemplate("file:///{path*}", templates[0]); err == nil {
+		t.Error("RegisterResourceTemplate() with unsupported template error = nil, want an error")
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantText string
+		wantErr  error
+	}{
+		{
+			name:     "exact resource before templates",
+			uri:      "file:///etc/motd",
+			wantText: "",
+		},
+		{
+			name:     "most literal text wins",
+			uri:      "file:///etc/hosts",
+			wantText: "file:///etc/{name} map[name:hosts]",
+		},
+		{
+			name:     "simple variables before reserved",
+			uri:      "file:///var/log",
+			wantText: "file:///{dir}/{name} map[dir:var name:log]",
+		},
+		{
+			name:     "reserved variable spans segments",
+			uri:      "file:///var/log/syslog",
+			wantText: "file:///{+path} map[path:var/log/syslog]",
+		},
+		{
+			name:     "equal specificity goes to the first registered",
+			uri:      "db://tables/users/rows/7",
+			wantText: "db://tables/{table}/rows/{id} map[id:7 table:users]",
+		},
+		{
+			name:    "provider reports not found",
+			uri:     "db://tables/users/rows/0",
+			wantErr: ErrResourceNotFound,
+		},
+		{
+			name:    "no match",
+			uri:     "http://example.com/",
+			wantErr: ErrResourceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resource, err := registry.GetResource(context.Background(), tt.uri)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetResource(%q) error = %v, want %v", tt.uri, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetResource(%q) error = %v", tt.uri, err)
+			}
+			if resource.URI != tt.uri {
+				t.Errorf("GetResource(%q) URI = %q", tt.uri, resource.URI)
+			}
+			if resource.Text != tt.wantText {
+				t.Errorf("GetResource(%q) text = %q, want %q", tt.uri, resource.Text, tt.wantText)
+			}
+		})
+	}
+
+	definitions := registry.ListResourceTemplates()
+	if len(definitions) != len(templates) || definitions[0].URITemplate != "db://tables/{table}/rows/{+id}" {
+		t.Errorf("ListResourceTemplates() = %+v, want templates sorted by URI template", definitions)
+	}
+
+	want := []string{"db:read", "files:read"}
+	if got := registry.RequiredScopes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredScopes() = %v, want %v", got, want)
+	}
+}
+
+func TestHandler_ResourceTemplates(t *testing.T) {
+	t.Parallel()
+
+	handler, _, resources, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+	if err := resources.RegisterResourceTemplate("db://tables/{table}", &templateProvider{template: "db://tables/{table}"}); err != nil {
+		t.Fatalf("RegisterResourceTemplate() error = %v", err)
+	}
+
+	session, err := NewSessionManager(0).Create(SessionOwner{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ctx := ContextWithSession(context.Background(), session)
+	if resp, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 1, Method: "initialize"}); err != nil || resp.Error != nil {
+		t.Fatalf("initialize = %v, %v", resp, err)
+	}
+
+	resp, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 2, Method: "resources/templates/list"})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("resources/templates/list = %v, %v", resp, err)
+	}
+	encoded, _ := json.Marshal(resp.Result)
+	if want := `{"resourceTemplates":[{"uriTemplate":"db://tables/{table}","name":"db://tables/{table}"}]}`; string(encoded) != want {
+		t.Errorf("resources/templates/list result = %s, want %s", encoded, want)
+	}
+
+	resp, err = handler.HandleRequest(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      3,
+		Method:  "resources/read",
+		Params:  json.RawMessage(`{"uri":"db://tables/users"}`),
+	})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("resources/read = %v, %v", resp, err)
+	}
+	contents := resp.Result.(ResourcesReadResult).Contents
+	if len(contents) != 1 || contents[0].URI != "db://tables/users" || contents[0].Text != "db://tables/{table} map[table:users]" {
+		t.Errorf("resources/read contents = %+v", contents)
+	}
+}
+
+func TestHandler_ScopedToolsAndResources(t *testing.T) {
+	t.Parallel()
+
+	handler, tools, resources, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+	if err := tools.RegisterTool("delete", &scopedTool{name: "delete", scopes: []string{"mcp:admin"}}); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := tools.RegisterTool("plain", plainTool{}); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := resources.RegisterResource("file:///audit", &scopedProvider{uri: "file:///audit", scopes: []string{"mcp:admin"}}); err != nil {
+		t.Fatalf("RegisterResource() error = %v", err)
+	}
+	if err := resources.RegisterResourceTemplate("db://tables/{table}", &templateProvider{template: "db://tables/{table}", scopes: []string{"db:read"}}); err != nil {
+		t.Fatalf("RegisterResourceTemplate() error = %v", err)
+	}
+
+	session, err := NewSessionManager(0).Create(SessionOwner{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ctx := ContextWithSession(context.Background(), session)
+	if resp, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 1, Method: "initialize"}); err != nil || resp.Error != nil {
+		t.Fatalf("initialize = %v, %v", resp, err)
+	}
+
+	call := func(scopes []string, method, params string) *Response {
+		t.Helper()
+
+		resp, err := handler.HandleRequest(ContextWithScopes(ctx, scopes), &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      2,
+			Method:  method,
+			Params:  json.RawMessage(params),
+		})
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		return resp
+	}
+
+	// listed returns the number of items listed by method
+	listed := func(scopes []string, method string) int {
+		t.Helper()
+
+		resp := call(scopes, method, `{}`)
+		if resp.Error != nil {
+			t.Fatalf("%s error = %v", method, resp.Error)
+		}
+		switch result := resp.Result.(type) {
+		case ToolsListResult:
+			return len(result.Tools)
+		case ResourcesListResult:
+			return len(result.Resources)
+		case ResourceTemplatesListResult:
+			return len(result.ResourceTemplates)
+		}
+		t.Fatalf("%s result = %T", method, resp.Result)
+		return 0
+	}
+
+	missing := []string{"mcp:read"}
+	granted := []string{"mcp:admin", "db:read"}
+
+	lists := []struct {
+		method    string
+		withScope int
+		without   int
+	}{
+		{"tools/list", 2, 1},
+		{"resources/list", 1, 0},
+		{"resources/templates/list", 1, 0},
+	}
+	for _, tt := range lists {
+		if n := listed(granted, tt.method); n != tt.withScope {
+			t.Errorf("%s with scopes listed %d, want %d", tt.method, n, tt.withScope)
+		}
+		if n := listed(missing, tt.method); n != tt.without {
+			t.Errorf("%s without scopes listed %d, want %d", tt.method, n, tt.without)
+		}
+	}
+
+	requests := []struct {
+		name   string
+		method string
+		params string
+		code   int
+	}{
+		{"call scoped tool", "tools/call", `{"name":"delete"}`, CodeToolNotFound},
+		{"read scoped resource", "resources/read", `{"uri":"file:///audit"}`, CodeResourceNotFound},
+		{"read scoped template", "resources/read", `{"uri":"db://tables/users"}`, CodeResourceNotFound},
+		{"subscribe to scoped resource", "resources/subscribe", `{"uri":"file:///audit"}`, CodeResourceNotFound},
+	}
+	for _, tt := range requests {
+		if resp := call(missing, tt.method, tt.params); resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s without scopes error = %v, want code %d", tt.name, resp.Error, tt.code)
+		}
+		if resp := call(granted, tt.method, tt.params); resp.Error != nil {
+			t.Errorf("%s with scopes error = %v", tt.name, resp.Error)
+		}
+	}
+	if resp := call(missing, "tools/call", `{"name":"plain"}`); resp.Error != nil {
+		t.Errorf("tools/call of an unscoped tool error = %v", resp.Error)
+	}
+	if !session.Subscribed("file:///audit") {
+		t.Error("Subscribed() = false after subscribing with scopes")
+	}
+}
+
+func TestRegistries_UnregisterAndObserve(t *testing.T) {
+	t.Parallel()
+
+	tools := NewToolRegistry()
+	resources := NewResourceRegistry()
+	prompts := NewPromptRegistry()
+
+	var changes sync.Map
+	count := func(name string) func() {
+		return func() {
+			n, _ := changes.LoadOrStore(name, new(atomic.Int32))
+			n.(*atomic.Int32).Add(1)
+		}
+	}
+	tools.ObserveChanges(count("tools"))
+	resources.ObserveChanges(count("resources"))
+	prompts.ObserveChanges(count("prompts"))
+
+	prompt, err := NewTemplatePrompt(PromptDefinition{Name: "greet"}, nil, PromptMessageTemplate{Role: "user", Text: "Hello"})
+	if err != nil {
+		t.Fatalf("NewTemplatePrompt() error = %v", err)
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+	}{
+		{"RegisterTool", func() error { return tools.RegisterTool("plain", plainTool{}) }},
+		{"UnregisterTool", func() error { return tools.UnregisterTool("plain") }},
+		{"RegisterResource", func() error { return resources.RegisterResource("file:///a", &scopedProvider{uri: "file:///a"}) }},
+		{"UnregisterResource", func() error { return resources.UnregisterResource("file:///a") }},
+		{"RegisterResourceTemplate", func() error {
+			return resources.RegisterResourceTemplate("file:///{name}", &templateProvider{template: "file:///{name}"})
+		}},
+		{"UnregisterResourceTemplate", func() error { return resources.UnregisterResourceTemplate("file:///{name}") }},
+		{"RegisterPrompt", func() error { return prompts.RegisterPrompt("greet", prompt) }},
+		{"UnregisterPrompt", func() error { return prompts.UnregisterPrompt("greet") }},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s() error = %v", step.name, err)
+		}
+	}
+
+	for name, want := range map[string]int32{"tools": 2, "resources": 4, "prompts": 2} {
+		n, _ := changes.Load(name)
+		if got := n.(*atomic.Int32).Load(); got != want {
+			t.Errorf("%s observer called %d times, want %d", name, got, want)
+		}
+	}
+
+	// The registries are empty again
+	if got := tools.ListTools(); len(got) != 0 {
+		t.Errorf("ListTools() = %v, want none", got)
+	}
+	if got := resources.ListResources(); len(got) != 0 {
+		t.Errorf("ListResources() = %v, want none", got)
+	}
+	if got := resources.ListResourceTemplates(); len(got) != 0 {
+		t.Errorf("ListResourceTemplates() = %v, want none", got)
+	}
+	if got := prompts.ListPrompts(); len(got) != 0 {
+		t.Errorf("ListPrompts() = %v, want none", got)
+	}
+
+	// Removing what is not registered fails without notifying observers
+	notFound := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"UnregisterTool", tools.UnregisterTool("plain"), ErrToolNotFound},
+		{"UnregisterResource", resources.UnregisterResource("file:///a"), ErrResourceNotFound},
+		{"UnregisterResourceTemplate", resources.UnregisterResourceTemplate("file:///{name}"), ErrResourceNotFound},
+		{"UnregisterPrompt", prompts.UnregisterPrompt("greet"), ErrPromptNotFound},
+	}
+	for _, tt := range notFound {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s() of a missing item error = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+	if n, _ := changes.Load("tools"); n.(*atomic.Int32).Load() != 2 {
+		t.Error("tools observer called for a failed UnregisterTool")
+	}
+}
@@ -2,8 +2,12 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	internalerrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
 )
@@ -12,12 +16,25 @@ import (
 type resourceRegistry struct {
 	mu        sync.RWMutex
 	providers map[string]ResourceProvider
+	watching  map[string]*atomic.Bool // by URI, cleared when the resource is unregistered
+	templates []*registeredTemplate
+	observers []func(uri string)
+	changes   changeObservers
+}
+
+// registeredTemplate is a template provider with its parsed URI template.
+// Templates are kept in registration order, which breaks precedence ties.
+type registeredTemplate struct {
+	template *uriTemplate
+	provider ResourceTemplateProvider
+	watching *atomic.Bool // cleared when the template is unregistered
 }
 
 // NewResourceRegistry creates a new thread-safe resource registry.
 func NewResourceRegistry() ResourceRegistry {
 	return &resourceRegistry{
 		providers: make(map[string]ResourceProvider),
+		watching:  make(map[string]*atomic.Bool),
 	}
 }
 
@@ -33,18 +50,145 @@ func (r *resourceRegistry) RegisterResource(uri string, provider ResourceProvide
 	}
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if _, exists := r.providers[uri]; exists {
+		r.mu.Unlock()
 		return internalerrors.New("mcp", "RegisterResource", internalerrors.ErrBadRequest, ErrResourceAlreadyRegistered).
 			WithContext("resource_uri", uri)
 	}
-
 	r.providers[uri] = provider
+	watching := newWatching()
+	r.watching[uri] = watching
+	r.mu.Unlock()
+
+	r.watch(provider, watching)
+	r.changes.notify()
+	return nil
+}
+
+// UnregisterResource removes the resource registered for the given URI.
+// Returns ErrResourceNotFound if no resource is registered for it.
+func (r *resourceRegistry) UnregisterResource(uri string) error {
+	r.mu.Lock()
+	if _, exists := r.providers[uri]; !exists {
+		r.mu.Unlock()
+		return internalerrors.New("mcp", "UnregisterResource", internalerrors.ErrNotFound, ErrResourceNotFound).
+			WithContext("resource_uri", uri)
+	}
+	delete(r.providers, uri)
+	r.watching[uri].Store(false)
+	delete(r.watching, uri)
+	r.mu.Unlock()
+
+	r.changes.notify()
+	return nil
+}
+
+// RegisterResourceTemplate registers a template provider for the URIs
+// matching uriTemplate.
+// Returns an error if the same template is already registered or if the
+// template or provider is invalid.
+func (r *resourceRegistry) RegisterResourceTemplate(uriTemplate string, provider ResourceTemplateProvider) error {
+	if provider == nil {
+		return internalerrors.New("mcp", "RegisterResourceTemplate", internalerrors.ErrBadRequest, fmt.Errorf("resource template provider cannot be nil"))
+	}
+	template, err := parseURITemplate(uriTemplate)
+	if err != nil {
+		return internalerrors.New("mcp", "RegisterResourceTemplate", internalerrors.ErrBadRequest, err)
+	}
+
+	r.mu.Lock()
+	for _, registered := range r.templates {
+		if registered.template.raw == uriTemplate {
+			r.mu.Unlock()
+			return internalerrors.New("mcp", "RegisterResourceTemplate", internalerrors.ErrBadRequest, ErrResourceAlreadyRegistered).
+				WithContext("uri_template", uriTemplate)
+		}
+	}
+	watching := newWatching()
+	r.templates = append(r.templates, &registeredTemplate{template: template, provider: provider, watching: watching})
+	r.mu.Unlock()
+
+	r.watch(provider, watching)
+	r.changes.notify()
 	return nil
 }
 
-// GetResource retrieves a resource by URI and reads its content.
+// UnregisterResourceTemplate removes the provider registered for the given
+// URI template. Later templates keep their registration order.
+// Returns ErrResourceNotFound if the template is not registered.
+func (r *resourceRegistry) UnregisterResourceTemplate(uriTemplate string) error {
+	r.mu.Lock()
+	index := slices.IndexFunc(r.templates, func(registered *registeredTemplate) bool {
+		return registered.template.raw == uriTemplate
+	})
+	if index < 0 {
+		r.mu.Unlock()
+		return internalerrors.New("mcp", "UnregisterResourceTemplate", internalerrors.ErrNotFound, ErrResourceNotFound).
+			WithContext("uri_template", uriTemplate)
+	}
+	r.templates[index].watching.Store(false)
+	r.templates = slices.Delete(r.templates, index, index+1)
+	r.mu.Unlock()
+
+	r.changes.notify()
+	return nil
+}
+
+// newWatching returns the flag passing the updates of a new registration on,
+// until it is cleared by unregistering.
+func newWatching() *atomic.Bool {
+	watching := new(atomic.Bool)
+	watching.Store(true)
+	return watching
+}
+
+// watch lets a newly registered provider that implements
+// ResourceUpdateSource signal its changes, for as long as watching is set.
+// It is called without r.mu held, as the provider may report a change right
+// away.
+func (r *resourceRegistry) watch(provider any, watching *atomic.Bool) {
+	source, ok := provider.(ResourceUpdateSource)
+	if !ok {
+		return
+	}
+	source.WatchUpdates(func(uri string) {
+		// An unregistered provider no longer serves uri
+		if watching.Load() {
+			r.NotifyUpdated(uri)
+		}
+	})
+}
+
+// NotifyUpdated passes uri to every observer.
+func (r *resourceRegistry) NotifyUpdated(uri string) {
+	r.mu.RLock()
+	observers := r.observers
+	r.mu.RUnlock()
+
+	for _, observer := range observers {
+		observer(uri)
+	}
+}
+
+// ObserveChanges registers an observer of resource and template
+// registrations and removals.
+func (r *resourceRegistry) ObserveChanges(observer func()) {
+	r.changes.add(observer)
+}
+
+// ObserveUpdates registers an observer of resource content updates.
+func (r *resourceRegistry) ObserveUpdates(observer func(uri string)) {
+	if observer == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
+// GetResource retrieves a resource by URI and reads its content. A resource
+// registered under the exact URI is read before any matching template.
 // Returns ErrResourceNotFound if the resource does not exist.
 func (r *resourceRegistry) GetResource(ctx context.Context, uri string) (*Resource, error) {
 	if uri == "" {
@@ -53,8 +197,16 @@ func (r *resourceRegistry) GetResource(ctx context.Context, uri string) (*Resour
 
 	r.mu.RLock()
 	provider, exists := r.providers[uri]
+	var match *registeredTemplate
+	var vars map[string]string
+	if !exists {
+		match, vars = r.matchTemplate(uri)
+	}
 	r.mu.RUnlock()
 
+	if match != nil {
+		return r.readTemplate(ctx, match, uri, vars)
+	}
 	if !exists {
 		return nil, internalerrors.New("mcp", "GetResource", internalerrors.ErrNotFound, ErrResourceNotFound).
 			WithContext("resource_uri", uri)
@@ -70,8 +222,44 @@ func (r *resourceRegistry) GetResource(ctx context.Context, uri string) (*Resour
 	return resource, nil
 }
 
-// ListResources returns definitions for all registered resources.
-// The returned slice is a snapshot and safe for concurrent access.
+// matchTemplate returns the most specific template matching uri and the
+// values of its variables, or nil if none matches. r.mu must be held.
+func (r *resourceRegistry) matchTemplate(uri string) (*registeredTemplate, map[string]string) {
+	var best *registeredTemplate
+	var bestVars map[string]string
+	for _, registered := range r.templates {
+		if best != nil && !registered.template.moreSpecific(best.template) {
+			continue
+		}
+		if vars, ok := registered.template.match(uri); ok {
+			best, bestVars = registered, vars
+		}
+	}
+	return best, bestVars
+}
+
+// readTemplate reads uri from a matching template provider.
+func (r *resourceRegistry) readTemplate(ctx context.Context, match *registeredTemplate, uri string, vars map[string]string) (*Resource, error) {
+	resource, err := match.provider.Read(ctx, uri, vars)
+	if errors.Is(err, ErrResourceNotFound) {
+		return nil, internalerrors.New("mcp", "GetResource", internalerrors.ErrNotFound, err).
+			WithContext("resource_uri", uri).
+			WithContext("uri_template", match.template.raw)
+	}
+	if err != nil {
+		return nil, internalerrors.New("mcp", "GetResource", internalerrors.ErrInternal, fmt.Errorf("failed to read resource: %w", err)).
+			WithContext("resource_uri", uri).
+			WithContext("uri_template", match.template.raw)
+	}
+
+	if resource != nil && resource.URI == "" {
+		resource.URI = uri
+	}
+	return resource, nil
+}
+
+// ListResources returns definitions for all registered resources, sorted
+// by URI. The returned slice is a snapshot and safe for concurrent access.
 func (r *resourceRegistry) ListResources() []ResourceDefinition {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -81,5 +269,87 @@ func (r *resourceRegistry) ListResources() []ResourceDefinition {
 		definitions = append(definitions, provider.Definition())
 	}
 
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].URI < definitions[j].URI
+	})
+	return definitions
+}
+
+// ListResourceTemplates returns definitions for all registered templates,
+// sorted by URI template.
+func (r *resourceRegistry) ListResourceTemplates() []ResourceTemplateDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]ResourceTemplateDefinition, 0, len(r.templates))
+	for _, registered := range r.templates {
+		// The registered template is the one URIs are matched against
+		definition := registered.provider.Definition()
+		definition.URITemplate = registered.template.raw
+		definitions = append(definitions, definition)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].URITemplate < definitions[j].URITemplate
+	})
 	return definitions
 }
+
+// RequiredScopes returns the sorted union of the scopes declared by registered
+// providers and template providers.
+func (r *resourceRegistry) RequiredScopes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var declared [][]string
+	for _, provider := range r.providers {
+		if scoped, ok := provider.(ScopedResourceProvider); ok {
+			declared = append(declared, scoped.RequiredScopes())
+		}
+	}
+	for _, registered := range r.templates {
+		if scoped, ok := registered.provider.(ScopedResourceTemplateProvider); ok {
+			declared = append(declared, scoped.RequiredScopes())
+		}
+	}
+
+	return unionScopes(declared...)
+}
+
+// ResourceScopes returns the scopes declared by the provider serving uri:
+// the resource registered under uri, else the most specific matching template.
+func (r *resourceRegistry) ResourceScopes(uri string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if provider, exists := r.providers[uri]; exists {
+		if scoped, ok := provider.(ScopedResourceProvider); ok {
+			return scoped.RequiredScopes()
+		}
+		return nil
+	}
+	if match, _ := r.matchTemplate(uri); match != nil {
+		if scoped, ok := match.provider.(ScopedResourceTemplateProvider); ok {
+			return scoped.RequiredScopes()
+		}
+	}
+	return nil
+}
+
+// ResourceTemplateScopes returns the scopes declared by the provider
+// registered for uriTemplate.
+func (r *resourceRegistry) ResourceTemplateScopes(uriTemplate string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, registered := range r.templates {
+		if registered.template.raw != uriTemplate {
+			continue
+		}
+		if scoped, ok := registered.provider.(ScopedResourceTemplateProvider); ok {
+			return scoped.RequiredScopes()
+		}
+		return nil
+	}
+	return nil
+}
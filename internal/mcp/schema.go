@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// validateSchema checks value against a JSON Schema. Both are compared in
+// their JSON form, so schemas may be written as Go literals such as
+// []string{"name"} and values may be any JSON-serializable Go value.
+//
+// It implements the keywords tools need to describe their output: type,
+// enum, const, properties, required, additionalProperties, items,
+// minimum, maximum, minLength, maxLength, minItems and maxItems. Other
+// keywords are ignored, so a schema using them is only partially enforced.
+func validateSchema(schema map[string]any, value any) error {
+	var decodedSchema map[string]any
+	if err := roundTripJSON(schema, &decodedSchema); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	var decodedValue any
+	if err := roundTripJSON(value, &decodedValue); err != nil {
+		return err
+	}
+	return validateAt("$", decodedSchema, decodedValue)
+}
+
+// roundTripJSON encodes value as JSON and decodes it into target.
+func roundTripJSON(value any, target any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// validateAt validates value at the JSON path of the error messages.
+func validateAt(path string, schema map[string]any, value any) error {
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		return fmt.Errorf("%s: expected type %v, got %s", path, types, jsonType(value))
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(allowed any) bool { return reflect.DeepEqual(allowed, value) }) {
+			return fmt.Errorf("%s: value is not one of the enum values", path)
+		}
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		return fmt.Errorf("%s: value does not equal the const value", path)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateObject(path, schema, v)
+	case []any:
+		return validateArray(path, schema, v)
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if limit, ok := schemaNumber(schema, "minLength"); ok && length < limit {
+			return fmt.Errorf("%s: string shorter than %v", path, limit)
+		}
+		if limit, ok := schemaNumber(schema, "maxLength"); ok && length > limit {
+			return fmt.Errorf("%s: string longer than %v", path, limit)
+		}
+	case float64:
+		if limit, ok := schemaNumber(schema, "minimum"); ok && v < limit {
+			return fmt.Errorf("%s: %v is less than %v", path, v, limit)
+		}
+		if limit, ok := schemaNumber(schema, "maximum"); ok && v > limit {
+			return fmt.Errorf("%s: %v is greater than %v", path, v, limit)
+		}
+	}
+
+	return nil
+}
+
+// validateObject applies the object keywords to value.
+func validateObject(path string, schema map[string]any, value map[string]any) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, present := value[name]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+
+	// Validate in a fixed order so that errors are reproducible
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, ok := properties[name].(map[string]any); ok {
+			if err := validateAt(propertyPath, property, value[name]); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, declared := properties[name]; declared {
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+		case map[string]any:
+			if err := validateAt(propertyPath, additional, value[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateArray applies the array keywords to value.
+func validateArray(path string, schema map[string]any, value []any) error {
+	length := float64(len(value))
+	if limit, ok := schemaNumber(schema, "minItems"); ok && length < limit {
+		return fmt.Errorf("%s: fewer than %v items", path, limit)
+	}
+	if limit, ok := schemaNumber(schema, "maxItems"); ok && length > limit {
+		return fmt.Errorf("%s: more than %v items", path, limit)
+	}
+
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range value {
+			if err := validateAt(fmt.Sprintf("%s[%d]", path, i), items, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesType reports whether value has the type named by types, a type
+// name or a list of them.
+func matchesType(types any, value any) bool {
+	switch types := types.(type) {
+	case string:
+		return matchesTypeName(types, value)
+	case []any:
+		return slices.ContainsFunc(types, func(name any) bool {
+			typeName, ok := name.(string)
+			return ok && matchesTypeName(typeName, value)
+		})
+	default:
+		return true
+	}
+}
+
+// matchesTypeName reports whether value has the JSON Schema type name.
+func matchesTypeName(name string, value any) bool {
+	actual := jsonType(value)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value. Numbers
+// without a fractional part are integers.
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaNumber returns the numeric value of a schema keyword.
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	n, ok := schema[keyword].(float64)
+	return n, ok
+}
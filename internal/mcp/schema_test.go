@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := map[string]any{
+		"type":     "object",
+		"required": []string{"city", "temperature"},
+		"properties": map[string]any{
+			"city":        map[string]any{"type": "string", "minLength": 1},
+			"temperature": map[string]any{"type": "number", "minimum": -90, "maximum": 60},
+			"unit":        map[string]any{"enum": []string{"C", "F"}},
+			"readings": map[string]any{
+				"type":     "array",
+				"maxItems": 3,
+				"items":    map[string]any{"type": "integer"},
+			},
+			"station": map[string]any{"type": []string{"string", "null"}},
+		},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			value: map[string]any{"city": "Oslo", "temperature": -3.5, "unit": "C", "readings": []int{1, 2}, "station": nil},
+		},
+		{
+			name: "valid struct",
+			value: struct {
+				City        string  `json:"city"`
+				Temperature float64 `json:"temperature"`
+			}{"Oslo", 4},
+		},
+		{
+			name:    "not an object",
+			value:   []string{"Oslo"},
+			wantErr: "$: expected type object, got array",
+		},
+		{
+			name:    "missing required property",
+			value:   map[string]any{"city": "Oslo"},
+			wantErr: `missing required property "temperature"`,
+		},
+		{
+			name:    "wrong property type",
+			value:   map[string]any{"city": 7, "temperature": 1},
+			wantErr: "$.city: expected type string, got integer",
+		},
+		{
+			name:    "below minimum",
+			value:   map[string]any{"city": "Vostok", "temperature": -91},
+			wantErr: "$.temperature: -91 is less than -90",
+		},
+		{
+			name:    "empty string",
+			value:   map[string]any{"city": "", "temperature": 1},
+			wantErr: "$.city: string shorter than 1",
+		},
+		{
+			name:    "outside enum",
+			value:   map[string]any{"city": "Oslo", "temperature": 1, "unit": "K"},
+			wantErr: "$.unit: value is not one of the enum values",
+		},
+		{
+			name:    "too many items",
+			value:   map[string]any{"city": "Oslo", "temperature": 1, "readings": []int{1, 2, 3, 4}},
+			wantErr: "$.readings: more than 3 items",
+		},
+		{
+			name:    "non-integer item",
+			value:   map[string]any{"city": "Oslo", "temperature": 1, "readings": []float64{1.5}},
+			wantErr: "$.readings[0]: expected type integer, got number",
+		},
+		{
+			name:    "unexpected property",
+			value:   map[string]any{"city": "Oslo", "temperature": 1, "humidity": 80},
+			wantErr: `unexpected property "humidity"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSchema(schema, tt.value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSchema() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSchema() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
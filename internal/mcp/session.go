@@ -0,0 +1,624 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sender delivers JSON-RPC messages from the server to the client, such as
+// a server-sent event stream. Implementations must be safe for concurrent use.
+type Sender interface {
+	// Send writes msg to the client. Returns ErrStreamClosed once the
+	// stream has ended.
+	Send(msg any) error
+}
+
+// SessionManager creates and tracks MCP sessions. Implementations must be
+// thread-safe as sessions are used by concurrent requests.
+type SessionManager interface {
+	// Create starts a new session for owner with a unique, unguessable ID.
+	Create(owner SessionOwner) (*Session, error)
+
+	// Get returns the session with the given ID and marks it as used.
+	// Returns false if the session does not exist, was deleted or expired.
+	Get(id string) (*Session, bool)
+
+	// Delete terminates the session with the given ID, ending its stream.
+	// Returns false if the session does not exist.
+	Delete(id string) bool
+
+	// Close terminates every session, for server shutdown.
+	Close()
+}
+
+// SessionOwner identifies the caller a session belongs to: the subject of
+// the access token that created it and the client the token was issued to.
+// Only requests authenticated as the same owner may use the session.
+type SessionOwner struct {
+	// Issuer is the authorization server that issued the token.
+	Issuer string
+
+	// Subject is the token's subject (sub) claim.
+	Subject string
+
+	// ClientID is the OAuth client the token was issued to.
+	ClientID string
+}
+
+// Session is an MCP session established by initialize. The transport
+// identifies it by the Mcp-Session-Id header. Messages the server sends
+// outside of a request are delivered on the session's standalone stream.
+type Session struct {
+	id    string
+	owner SessionOwner
+	now   func() time.Time
+
+	mu              sync.Mutex
+	initialized     bool
+	protocolVersion string
+	clientInfo      ClientInfo
+	capabilities    ClientCapabilities
+	lastUsed        time.Time
+	stream          Sender
+	nextCallID      uint64
+	calls           map[string]chan *Message
+	inFlight        map[string]context.CancelFunc
+	subscriptions   map[string]bool
+	pendingUpdates  map[string]bool
+	pendingChanges  map[string]bool
+	updateTimer     *time.Timer
+	done            chan struct{}
+	closed          bool
+}
+
+// newSession creates an open session with the given ID, reading the time
+// from now.
+func newSession(id string, owner SessionOwner, now func() time.Time) *Session {
+	return &Session{
+		id:       id,
+		owner:    owner,
+		now:      now,
+		lastUsed: now(),
+		calls:    make(map[string]chan *Message),
+		inFlight: make(map[string]context.CancelFunc),
+		done:     make(chan struct{}),
+
+		subscriptions:  make(map[string]bool),
+		pendingUpdates: make(map[string]bool),
+		pendingChanges: make(map[string]bool),
+	}
+}
+
+// ID returns the session identifier.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Owner returns the caller the session belongs to.
+func (s *Session) Owner() SessionOwner {
+	return s.owner
+}
+
+// Initialized reports whether initialize completed in this session.
+func (s *Session) Initialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialized
+}
+
+// ProtocolVersion returns the protocol version negotiated by initialize.
+func (s *Session) ProtocolVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.protocolVersion
+}
+
+// Features returns the behaviour of the negotiated protocol version.
+// Elicitation is only enabled if the client declared support for it.
+func (s *Session) Features() Features {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	features := FeaturesFor(s.protocolVersion)
+	features.Elicitation = features.Elicitation && s.capabilities.Elicitation != nil
+	return features
+}
+
+// ClientInfo returns the client name and version sent with initialize.
+func (s *Session) ClientInfo() ClientInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientInfo
+}
+
+// ClientCapabilities returns the capabilities the client declared in
+// initialize.
+func (s *Session) ClientCapabilities() ClientCapabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capabilities
+}
+
+// initialize records the outcome of the initialize request.
+func (s *Session) initialize(protocolVersion string, params InitializeParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.initialized = true
+	s.protocolVersion = protocolVersion
+	s.clientInfo = params.ClientInfo
+	s.capabilities = params.Capabilities
+}
+
+// Done returns a channel that is closed when the session is terminated.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// OpenStream makes stream the standalone stream of the session. The
+// returned function detaches it again. Returns ErrStreamOpen if the session
+// already has a standalone stream, or ErrSessionClosed if it was terminated.
+func (s *Session) OpenStream(stream Sender) (func(), error) {
+	if stream == nil {
+		return nil, fmt.Errorf("stream cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrSessionClosed
+	}
+	if s.stream != nil {
+		return nil, ErrStreamOpen
+	}
+	s.stream = stream
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.stream == stream {
+			s.stream = nil
+			s.lastUsed = s.now()
+		}
+	}, nil
+}
+
+// Send writes msg to the standalone stream.
+// Returns ErrNoStream if no standalone stream is open.
+func (s *Session) Send(msg any) error {
+	s.mu.Lock()
+	stream := s.stream
+	s.mu.Unlock()
+
+	if stream == nil {
+		return ErrNoStream
+	}
+	return stream.Send(msg)
+}
+
+// Notify sends a notification on the standalone stream.
+// Returns ErrNoStream if no standalone stream is open.
+func (s *Session) Notify(method string, params any) error {
+	return s.Send(NewNotification(method, params))
+}
+
+// Call sends a request to the client on the standalone stream and waits
+// for the response, which the client POSTs back in the session. Returns
+// the response's error as an *Error, ErrNoStream if no standalone stream
+// is open, or ErrSessionClosed if the session ends first.
+func (s *Session) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return s.call(ctx, s.Send, method, params)
+}
+
+// call sends a request with send and waits for its response.
+func (s *Session) call(ctx context.Context, send func(msg any) error, method string, params any) (json.RawMessage, error) {
+	req := &Request{JSONRPC: JSONRPCVersion, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		req.Params = data
+	}
+
+	id, response, err := s.addCall()
+	if err != nil {
+		return nil, err
+	}
+	defer s.removeCall(id)
+	req.ID = id
+
+	if err := send(req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-response:
+		if msg.Error != nil {
+			return nil, msg.Error
+		}
+		return msg.Result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, ErrSessionClosed
+	}
+}
+
+// addCall registers a server-initiated request and returns its ID and the
+// channel its response is delivered on.
+func (s *Session) addCall() (string, chan *Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return "", nil, ErrSessionClosed
+	}
+	s.nextCallID++
+	id := "s-" + strconv.FormatUint(s.nextCallID, 10)
+	response := make(chan *Message, 1)
+	s.calls[id] = response
+	return id, response, nil
+}
+
+// removeCall forgets a server-initiated request.
+func (s *Session) removeCall(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.calls, id)
+}
+
+// Respond delivers a client's response to the server-initiated request it
+// answers. Returns false if no request with its ID is waiting.
+func (s *Session) Respond(msg *Message) bool {
+	var id string
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	response, ok := s.calls[id]
+	delete(s.calls, id)
+	s.mu.Unlock()
+
+	if ok {
+		response <- msg
+	}
+	return ok
+}
+
+// trackRequest makes the request with the given ID cancellable by a
+// notifications/cancelled notification. The returned function stops
+// tracking it.
+func (s *Session) trackRequest(id any, cancel context.CancelFunc) func() {
+	key := requestKey(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight[key] = cancel
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.inFlight, key)
+	}
+}
+
+// cancelRequest cancels the in-flight request with the given ID.
+// Returns false if no such request is being handled.
+func (s *Session) cancelRequest(id any) bool {
+	key := requestKey(id)
+
+	s.mu.Lock()
+	cancel, ok := s.inFlight[key]
+	delete(s.inFlight, key)
+	s.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// requestKey returns the canonical JSON text of a request ID, so that
+// IDs decoded from different messages compare equal.
+func requestKey(id any) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
+
+// Subscribed reports whether the client subscribed to updates of the
+// resource at uri.
+func (s *Session) Subscribed(uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscriptions[uri]
+}
+
+// subscribe subscribes the session to updates of the resource at uri.
+func (s *Session) subscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[uri] = true
+}
+
+// unsubscribe ends the subscription to uri, dropping an update that is
+// still pending.
+func (s *Session) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscriptions, uri)
+	delete(s.pendingUpdates, uri)
+}
+
+// resourceUpdated schedules a notifications/resources/updated notification
+// for uri if the session is subscribed to it.
+func (s *Session) resourceUpdated(uri string, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || !s.subscriptions[uri] {
+		return
+	}
+	s.pendingUpdates[uri] = true
+	s.scheduleFlush(window)
+}
+
+// listChanged schedules a list_changed notification with the given method.
+func (s *Session) listChanged(method string, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.pendingChanges[method] = true
+	s.scheduleFlush(window)
+}
+
+// scheduleFlush starts the window of pending notifications unless one is
+// running. Notifications are coalesced: everything scheduled within window
+// of the first is sent together when it ends, once each. s.mu must be held.
+func (s *Session) scheduleFlush(window time.Duration) {
+	if s.updateTimer == nil {
+		s.updateTimer = time.AfterFunc(window, s.flushUpdates)
+	}
+}
+
+// flushUpdates sends the pending list_changed and resource update
+// notifications on the standalone stream. They are dropped while no stream
+// is open, as the client lists and reads again when it reconnects.
+func (s *Session) flushUpdates() {
+	s.mu.Lock()
+	methods := sortedKeys(s.pendingChanges)
+	uris := sortedKeys(s.pendingUpdates)
+	s.pendingChanges = make(map[string]bool)
+	s.pendingUpdates = make(map[string]bool)
+	s.updateTimer = nil
+	s.mu.Unlock()
+
+	for _, method := range methods {
+		if err := s.Notify(method, nil); err != nil {
+			return
+		}
+	}
+	for _, uri := range uris {
+		if err := s.Notify(MethodResourceUpdated, ResourceUpdatedParams{URI: uri}); err != nil {
+			return
+		}
+	}
+}
+
+// sortedKeys returns the keys of set in ascending order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// touch marks the session as used at now.
+func (s *Session) touch(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUsed = now
+}
+
+// expired reports whether the session was idle for longer than ttl at now.
+// A session with an open standalone stream is in use and does not expire;
+// the transport ends the stream when the token that opened it expires, and
+// the session is idle from then on.
+func (s *Session) expired(now time.Time, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ttl > 0 && s.stream == nil && now.Sub(s.lastUsed) > ttl
+}
+
+// close terminates the session.
+func (s *Session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		s.stream = nil
+		if s.updateTimer != nil {
+			s.updateTimer.Stop()
+			s.updateTimer = nil
+		}
+		close(s.done)
+	}
+}
+
+// sessionManager implements SessionManager with an in-memory map.
+type sessionManager struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+
+	stop     chan struct{} // closed by Close to end the sweep
+	stopOnce sync.Once
+}
+
+// NewSessionManager creates a new thread-safe in-memory session manager.
+// Sessions idle for longer than ttl expire; a zero ttl keeps them until
+// they are deleted. Expired sessions are swept every ttl until Close, so
+// they are terminated even while no request arrives.
+func NewSessionManager(ttl time.Duration) SessionManager {
+	m := &sessionManager{
+		ttl:      ttl,
+		now:      time.Now,
+		sessions: make(map[string]*Session),
+		stop:     make(chan struct{}),
+	}
+	if ttl > 0 {
+		go m.sweepEvery(ttl)
+	}
+	return m
+}
+
+// sweepEvery removes expired sessions every interval until Close.
+func (m *sessionManager) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.sweep(m.now())
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// sweep terminates and forgets the sessions expired at now.
+func (m *sessionManager) sweep(now time.Time) {
+	m.mu.Lock()
+	var expired []*Session
+	for id, session := range m.sessions {
+		if session.expired(now, m.ttl) {
+			delete(m.sessions, id)
+			expired = append(expired, session)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, session := range expired {
+		session.close()
+	}
+}
+
+// Create starts a new session identified by 128 random bits. Expired
+// sessions are removed first, so abandoned sessions do not accumulate.
+func (m *sessionManager) Create(owner SessionOwner) (*Session, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+	session := newSession(hex.EncodeToString(b), owner, m.now)
+
+	m.sweep(session.lastUsed)
+
+	m.mu.Lock()
+	m.sessions[session.id] = session
+	m.mu.Unlock()
+	return session, nil
+}
+
+// Get returns the session with the given ID, terminating it instead if it
+// expired.
+func (m *sessionManager) Get(id string) (*Session, bool) {
+	now := m.now()
+
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	if ok && session.expired(now, m.ttl) {
+		delete(m.sessions, id)
+		m.mu.Unlock()
+		session.close()
+		return nil, false
+	}
+	m.mu.Unlock()
+
+	if ok {
+		session.touch(now)
+	}
+	return session, ok
+}
+
+// Delete terminates and forgets the session with the given ID.
+func (m *sessionManager) Delete(id string) bool {
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if ok {
+		session.close()
+	}
+	return ok
+}
+
+// Close terminates every session and stops the sweep of expired ones.
+func (m *sessionManager) Close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[string]*Session)
+	m.mu.Unlock()
+
+	for _, session := range sessions {
+		session.close()
+	}
+}
+
+// contextKey is the type of the context keys of this package.
+type contextKey int
+
+const (
+	sessionKey contextKey = iota
+	senderKey
+	progressTokenKey
+	scopesKey
+)
+
+// ContextWithSession returns a context carrying the session of a request.
+func ContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey, session)
+}
+
+// SessionFromContext returns the session of the request being handled.
+// Returns nil and false outside of a session.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey).(*Session)
+	return session, ok && session != nil
+}
+
+// ContextWithScopes returns a context carrying the OAuth scopes granted to
+// the caller, which decide the tools, resources and prompts the caller may
+// list and use.
+func ContextWithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey, scopes)
+}
+
+// ScopesFromContext returns the scopes granted to the caller.
+// Returns nil if the context carries none.
+func ScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesKey).([]string)
+	return scopes
+}
@@ -0,0 +1,526 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSender records the messages sent to it.
+type recordingSender struct {
+	mu       sync.Mutex
+	messages []any
+}
+
+func (s *recordingSender) Send(msg any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func (s *recordingSender) sent() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]any(nil), s.messages...)
+}
+
+func TestSessionManager(t *testing.T) {
+	t.Parallel()
+
+	manager := NewSessionManager(time.Hour)
+
+	first, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if first.ID() == "" || first.ID() == second.ID() {
+		t.Fatalf("session IDs %q and %q are not unique", first.ID(), second.ID())
+	}
+
+	if got, ok := manager.Get(first.ID()); !ok || got != first {
+		t.Errorf("Get(%q) = %v, %v", first.ID(), got, ok)
+	}
+
+	if !manager.Delete(first.ID()) {
+		t.Error("Delete() of an existing session returned false")
+	}
+	if manager.Delete(first.ID()) {
+		t.Error("Delete() of a deleted session returned true")
+	}
+	if _, ok := manager.Get(first.ID()); ok {
+		t.Error("Get() returned a deleted session")
+	}
+	select {
+	case <-first.Done():
+	default:
+		t.Error("Delete() did not terminate the session")
+	}
+
+	manager.Close()
+	select {
+	case <-second.Done():
+	default:
+		t.Error("Close() did not terminate the session")
+	}
+	if _, ok := manager.Get(second.ID()); ok {
+		t.Error("Get() returned a session after Close()")
+	}
+}
+
+func TestSession_OpenStream(t *testing.T) {
+	t.Parallel()
+
+	manager := NewSessionManager(time.Hour)
+	session, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := session.Notify("notifications/message", nil); !errors.Is(err, ErrNoStream) {
+		t.Errorf("Notify() without a stream error = %v, want ErrNoStream", err)
+	}
+
+	stream := &recordingSender{}
+	detach, err := session.OpenStream(stream)
+	if err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	if _, err := session.OpenStream(&recordingSender{}); !errors.Is(err, ErrStreamOpen) {
+		t.Errorf("second OpenStream() error = %v, want ErrStreamOpen", err)
+	}
+
+	if err := session.Notify("notifications/message", map[string]any{"data": "hello"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	sent := stream.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	if notification, ok := sent[0].(*Notification); !ok || notification.Method != "notifications/message" || notification.JSONRPC != "2.0" {
+		t.Errorf("sent %#v, want a notifications/message notification", sent[0])
+	}
+
+	detach()
+	if _, err := session.OpenStream(stream); err != nil {
+		t.Errorf("OpenStream() after detach error = %v", err)
+	}
+
+	manager.Delete(session.ID())
+	if _, err := session.OpenStream(&recordingSender{}); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("OpenStream() on a terminated session error = %v, want ErrSessionClosed", err)
+	}
+	if err := session.Notify("notifications/message", nil); !errors.Is(err, ErrNoStream) {
+		t.Errorf("Notify() on a terminated session error = %v, want ErrNoStream", err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	t.Parallel()
+
+	manager := NewSessionManager(time.Hour)
+	session, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	standalone := &recordingSender{}
+	if _, err := session.OpenStream(standalone); err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	sessionCtx := ContextWithSession(context.Background(), session)
+
+	// Without a request stream, notifications go to the standalone stream
+	if err := Notify(sessionCtx, "notifications/message", nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(standalone.sent()) != 1 {
+		t.Errorf("standalone stream received %d messages, want 1", len(standalone.sent()))
+	}
+
+	// The stream answering the request takes precedence
+	request := &recordingSender{}
+	if err := Notify(ContextWithSender(sessionCtx, request), "notifications/message", nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(request.sent()) != 1 || len(standalone.sent()) != 1 {
+		t.Errorf("request stream received %d, standalone %d, want 1 and 1", len(request.sent()), len(standalone.sent()))
+	}
+
+	if err := Notify(context.Background(), "notifications/message", nil); !errors.Is(err, ErrNoStream) {
+		t.Errorf("Notify() without a stream error = %v, want ErrNoStream", err)
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	t.Parallel()
+
+	stream := &recordingSender{}
+	ctx := ContextWithSender(context.Background(), stream)
+
+	// Without a progress token nothing is sent
+	if err := ReportProgress(ctx, 1, 2, "half"); err != nil {
+		t.Fatalf("ReportProgress() error = %v", err)
+	}
+	if len(stream.sent()) != 0 {
+		t.Fatalf("sent %d messages without a progress token", len(stream.sent()))
+	}
+
+	if err := ReportProgress(ContextWithProgressToken(ctx, "job-1"), 1, 2, "half"); err != nil {
+		t.Fatalf("ReportProgress() error = %v", err)
+	}
+	sent := stream.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	notification, ok := sent[0].(*Notification)
+	if !ok || notification.Method != MethodProgress {
+		t.Fatalf("sent %#v, want a progress notification", sent[0])
+	}
+	params, ok := notification.Params.(ProgressParams)
+	if !ok || params.ProgressToken != "job-1" || params.Progress != 1 || params.Total != 2 || params.Message != "half" {
+		t.Errorf("progress params = %#v", notification.Params)
+	}
+}
+
+func TestSessionManager_Expiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	manager := NewSessionManager(time.Minute).(*sessionManager)
+	manager.now = func() time.Time { return now }
+
+	idle, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	active, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	streaming, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := streaming.OpenStream(&recordingSender{}); err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	// Using a session keeps it alive
+	now = now.Add(45 * time.Second)
+	if _, ok := manager.Get(active.ID()); !ok {
+		t.Fatal("Get() of an active session failed")
+	}
+
+	now = now.Add(45 * time.Second)
+	if _, ok := manager.Get(idle.ID()); ok {
+		t.Error("Get() returned a session idle for longer than the TTL")
+	}
+	select {
+	case <-idle.Done():
+	default:
+		t.Error("expired session was not terminated")
+	}
+	if _, ok := manager.Get(active.ID()); !ok {
+		t.Error("Get() of a session used within the TTL failed")
+	}
+	if _, ok := manager.Get(streaming.ID()); !ok {
+		t.Error("Get() of a session with an open stream failed")
+	}
+
+	// Creating a session sweeps the expired ones
+	now = now.Add(2 * time.Minute)
+	if _, err := manager.Create(SessionOwner{}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	select {
+	case <-active.Done():
+	default:
+		t.Error("Create() did not terminate the expired session")
+	}
+	select {
+	case <-streaming.Done():
+		t.Error("Create() terminated a session with an open stream")
+	default:
+	}
+}
+
+func TestSessionManager_Sweep(t *testing.T) {
+	t.Parallel()
+
+	manager := NewSessionManager(10 * time.Millisecond)
+	t.Cleanup(manager.Close)
+
+	session, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// No request arrives, yet the idle session is terminated
+	select {
+	case <-session.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle session was not swept")
+	}
+}
+
+func TestSessionManager_NoTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	manager := NewSessionManager(0).(*sessionManager)
+	manager.now = func() time.Time { return now }
+
+	session, err := manager.Create(SessionOwner{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if _, ok := manager.Get(session.ID()); !ok {
+		t.Error("Get() failed for a session without a TTL")
+	}
+}
+
+func TestHandler_Sessions(t *testing.T) {
+	t.Parallel()
+
+	handler, _, _, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+	manager := NewSessionManager(time.Hour)
+
+	session, err := manager.Create(SessionOwner{Subject: "alice", ClientID: "client-1"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ctx := ContextWithSession(context.Background(), session)
+
+	listTools := &Request{JSONRPC: JSONRPCVersion, ID: 1, Method: "tools/list"}
+
+	// Other methods are rejected until initialize completes
+	for name, ctx := range map[string]context.Context{
+		"without session":       context.Background(),
+		"before initialization": ctx,
+	} {
+		resp, err := handler.HandleRequest(ctx, listTools)
+		if err != nil {
+			t.Fatalf("%s: HandleRequest() error = %v", name, err)
+		}
+		if resp.Error == nil || resp.Error.Code != CodeInvalidRequest {
+			t.Errorf("%s: tools/list error = %v, want invalid request", name, resp.Error)
+		}
+	}
+
+	initialize := &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      2,
+		Method:  "initialize",
+		Params:  json.RawMessage(`{"protocolVersion":"2024-11-05","clientInfo":{"name":"inspector","version":"0.9"},"capabilities":{"roots":{"listChanged":true}}}`),
+	}
+	if resp, err := handler.HandleRequest(ctx, initialize); err != nil || resp.Error != nil {
+		t.Fatalf("initialize = %v, %v", resp, err)
+	}
+
+	if !session.Initialized() {
+		t.Error("session not initialized after initialize")
+	}
+	if version := session.ProtocolVersion(); version != ProtocolVersion20241105 {
+		t.Errorf("ProtocolVersion() = %q, want %q", version, ProtocolVersion20241105)
+	}
+	if info := session.ClientInfo(); info.Name != "inspector" || info.Version != "0.9" {
+		t.Errorf("ClientInfo() = %+v", info)
+	}
+	if roots := session.ClientCapabilities().Roots; roots == nil || !roots.ListChanged {
+		t.Errorf("ClientCapabilities().Roots = %+v, want listChanged", roots)
+	}
+
+	resp, err := handler.HandleRequest(ctx, listTools)
+	if err != nil || resp.Error != nil {
+		t.Errorf("tools/list after initialize = %v, %v", resp, err)
+	}
+
+	// Initializing one session does not initialize another
+	other, err := manager.Create(SessionOwner{Subject: "bob"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	resp, err = handler.HandleRequest(ContextWithSession(context.Background(), other), listTools)
+	if err != nil || resp.Error == nil {
+		t.Errorf("tools/list in an uninitialized session = %v, %v, want an error", resp, err)
+	}
+}
+
+func TestMessage_Kinds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		data             string
+		wantRequest      bool
+		wantNotification bool
+		wantResponse     bool
+	}{
+		{name: "request", data: `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, wantRequest: true},
+		{name: "request with null id", data: `{"jsonrpc":"2.0","id":null,"method":"tools/list"}`, wantRequest: true},
+		{name: "notification", data: `{"jsonrpc":"2.0","method":"notifications/initialized"}`, wantNotification: true},
+		{name: "result", data: `{"jsonrpc":"2.0","id":"s-1","result":{}}`, wantResponse: true},
+		{name: "error", data: `{"jsonrpc":"2.0","id":"s-1","error":{"code":-1,"message":"declined"}}`, wantResponse: true},
+		{name: "neither", data: `{"jsonrpc":"2.0","id":1}`},
+	}
+
+	for _, tt := range tests {
+		var msg Message
+		if err := json.Unmarshal([]byte(tt.data), &msg); err != nil {
+			t.Fatalf("%s: Unmarshal() error = %v", tt.name, err)
+		}
+		if msg.IsRequest() != tt.wantRequest || msg.IsNotification() != tt.wantNotification || msg.IsResponse() != tt.wantResponse {
+			t.Errorf("%s: IsRequest() = %v, IsNotification() = %v, IsResponse() = %v", tt.name, msg.IsRequest(), msg.IsNotification(), msg.IsResponse())
+		}
+	}
+}
+
+func TestSession_Call(t *testing.T) {
+	t.Parallel()
+
+	manager := NewSessionManager(time.Hour)
+	session, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if _, err := session.Call(context.Background(), "roots/list", nil); !errors.Is(err, ErrNoStream) {
+		t.Errorf("Call() without a stream error = %v, want ErrNoStream", err)
+	}
+
+	stream := &recordingSender{}
+	if _, err := session.OpenStream(stream); err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	// respond answers the last request sent on the stream. It runs in its
+	// own goroutine, so failures are reported with Errorf.
+	respond := func(response string) {
+		for len(stream.sent()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		sent := stream.sent()
+		req := sent[len(sent)-1].(*Request)
+		id, _ := json.Marshal(req.ID)
+
+		var msg Message
+		if err := json.Unmarshal([]byte(response), &msg); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+			return
+		}
+		msg.ID = id
+		if !session.Respond(&msg) {
+			t.Errorf("Respond() found no request %s", id)
+		}
+		stream.mu.Lock()
+		stream.messages = nil
+		stream.mu.Unlock()
+	}
+
+	go respond(`{"jsonrpc":"2.0","result":{"roots":[]}}`)
+	result, err := session.Call(context.Background(), "roots/list", nil)
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if string(result) != `{"roots":[]}` {
+		t.Errorf("Call() result = %s", result)
+	}
+
+	go respond(`{"jsonrpc":"2.0","error":{"code":-1,"message":"declined"}}`)
+	var rpcErr *Error
+	if _, err := session.Call(context.Background(), "elicitation/create", map[string]any{"message": "Name?"}); !errors.As(err, &rpcErr) || rpcErr.Message != "declined" {
+		t.Errorf("Call() error = %v, want the client's error", err)
+	}
+
+	if session.Respond(&Message{ID: json.RawMessage(`"s-99"`), Result: json.RawMessage(`{}`)}) {
+		t.Error("Respond() delivered a response to an unknown request")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := session.Call(ctx, "roots/list", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Call() with a cancelled context error = %v, want context.Canceled", err)
+	}
+
+	if _, err := Call(context.Background(), "roots/list", nil); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Call() outside of a session error = %v, want ErrNoSession", err)
+	}
+}
+
+func TestHandler_CancelledNotification(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	handler, tools, _, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+	if err := tools.RegisterTool("wait", &blockingTool{started: started}); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	session, err := NewSessionManager(time.Hour).Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ctx := ContextWithSession(context.Background(), session)
+	if _, err := handler.HandleRequest(ctx, &Request{JSONRPC: JSONRPCVersion, ID: 1, Method: "initialize"}); err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+
+	done := make(chan *Response)
+	go func() {
+		resp, _ := handler.HandleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      "call-1",
+			Method:  "tools/call",
+			Params:  json.RawMessage(`{"name":"wait"}`),
+		})
+		done <- resp
+	}()
+	<-started
+
+	notifications := handler.(NotificationHandler)
+	err = notifications.HandleNotification(ctx, &Request{
+		JSONRPC: JSONRPCVersion,
+		Method:  MethodCancelled,
+		Params:  json.RawMessage(`{"requestId":"call-1","reason":"user cancelled"}`),
+	})
+	if err != nil {
+		t.Fatalf("HandleNotification() error = %v", err)
+	}
+
+	select {
+	case resp := <-done:
+		if resp.Error == nil {
+			t.Errorf("cancelled tools/call = %+v, want an error", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notifications/cancelled did not cancel the request")
+	}
+
+	if err := notifications.HandleNotification(ctx, &Request{JSONRPC: JSONRPCVersion, Method: MethodInitialized}); err != nil {
+		t.Errorf("HandleNotification(initialized) error = %v", err)
+	}
+}
+
+// blockingTool runs until its context is cancelled.
+type blockingTool struct {
+	started chan struct{}
+}
+
+func (b *blockingTool) Execute(ctx context.Context, args map[string]any) (any, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (b *blockingTool) Definition() ToolDefinition {
+	return ToolDefinition{Name: "wait", InputSchema: map[string]any{"type": "object"}}
+}
@@ -0,0 +1,295 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// watchedProvider is a ResourceProvider that signals its own changes.
+type watchedProvider struct {
+	scopedProvider
+	updated func(uri string)
+}
+
+func (p *watchedProvider) WatchUpdates(updated func(uri string)) {
+	p.updated = updated
+}
+
+// updatedURIs returns the URIs of the resource update notifications sent
+// to sender.
+func updatedURIs(sender *recordingSender) []string {
+	var uris []string
+	for _, msg := range sender.sent() {
+		notification, ok := msg.(*Notification)
+		if !ok || notification.Method != MethodResourceUpdated {
+			continue
+		}
+		uris = append(uris, notification.Params.(ResourceUpdatedParams).URI)
+	}
+	return uris
+}
+
+func TestHandler_ResourceSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	const window = 20 * time.Millisecond
+	handler, _, resources, _ := NewMCPServices(&Config{
+		ServerName:           "test",
+		ServerVersion:        "1.0.0",
+		ResourceUpdateWindow: window,
+	})
+	provider := &watchedProvider{scopedProvider: scopedProvider{uri: "file:///config"}}
+	if err := resources.RegisterResource(provider.uri, provider); err != nil {
+		t.Fatalf("RegisterResource() error = %v", err)
+	}
+	if provider.updated == nil {
+		t.Fatal("WatchUpdates() not called on registration")
+	}
+
+	manager := NewSessionManager(0)
+	t.Cleanup(manager.Close)
+	session, err := manager.Create(SessionOwner{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	stream := &recordingSender{}
+	if _, err := session.OpenStream(stream); err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+	ctx := ContextWithSession(context.Background(), session)
+
+	call := func(id int, method, params string) *Response {
+		t.Helper()
+
+		resp, err := handler.HandleRequest(ctx, &Request{
+			JSONRPC: JSONRPCVersion,
+			ID:      id,
+			Method:  method,
+			Params:  json.RawMessage(params),
+		})
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		return resp
+	}
+
+	resp := call(1, "initialize", `{"protocolVersion":"2025-06-18"}`)
+	if resources := resp.Result.(InitializeResult).Capabilities.Resources; resources == nil || !resources.Subscribe {
+		t.Errorf("Capabilities.Resources = %+v, want subscribe", resources)
+	}
+
+	if resp := call(2, "resources/subscribe", `{}`); resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("resources/subscribe without uri error = %v, want invalid params", resp.Error)
+	}
+	for _, uri := range []string{"file:///config", "db://tables/users"} {
+		if resp := call(3, "resources/subscribe", `{"uri":"`+uri+`"}`); resp.Error != nil {
+			t.Fatalf("resources/subscribe error = %v", resp.Error)
+		}
+	}
+	if !session.Subscribed("file:///config") {
+		t.Error("Subscribed() = false after resources/subscribe")
+	}
+
+	// A burst of updates within the window is notified once per resource,
+	// and resources without subscribers are not notified at all
+	for i := 0; i < 5; i++ {
+		provider.updated("file:///config")
+	}
+	resources.NotifyUpdated("db://tables/users")
+	resources.NotifyUpdated("file:///other")
+
+	want := []string{"db://tables/users", "file:///config"}
+	deadline := time.Now().Add(time.Second)
+	for len(updatedURIs(stream)) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(window / 4)
+	}
+	time.Sleep(2 * window)
+	if got := updatedURIs(stream); !reflect.DeepEqual(got, want) {
+		t.Errorf("updated notifications = %v, want %v", got, want)
+	}
+
+	// A later update is notified again
+	resources.NotifyUpdated("file:///config")
+	deadline = time.Now().Add(time.Second)
+	for len(updatedURIs(stream)) < 3 && time.Now().Before(deadline) {
+		time.Sleep(window / 4)
+	}
+	if got := updatedURIs(stream); len(got) != 3 || got[2] != "file:///config" {
+		t.Errorf("updated notifications = %v, want a third for file:///config", got)
+	}
+
+	// Unsubscribed resources are no longer notified
+	for _, uri := range []string{"file:///config", "db://tables/users"} {
+		if resp := call(4, "resources/unsubscribe", `{"uri":"`+uri+`"}`); resp.Error != nil {
+			t.Fatalf("resources/unsubscribe error = %v", resp.Error)
+		}
+	}
+	resources.NotifyUpdated("file:///config")
+	time.Sleep(3 * window)
+	if got := updatedURIs(stream); len(got) != 3 {
+		t.Errorf("updated notifications after unsubscribe = %v, want no more", got)
+	}
+}
+
+// watchedTemplateProvider is a ResourceTemplateProvider that signals its
+// own changes.
+type watchedTemplateProvider struct {
+	templateProvider
+	updated func(uri string)
+}
+
+func (p *watchedTemplateProvider) WatchUpdates(updated func(uri string)) {
+	p.updated = updated
+}
+
+func TestResourceRegistry_UnregisteredProviderUpdates(t *testing.T) {
+	t.Parallel()
+
+	registry := NewResourceRegistry()
+	var observed []string
+	registry.ObserveUpdates(func(uri string) { observed = append(observed, uri) })
+
+	resource := &watchedProvider{scopedProvider: scopedProvider{uri: "file:///config"}}
+	if err := registry.RegisterResource(resource.uri, resource); err != nil {
+		t.Fatalf("RegisterResource() error = %v", err)
+	}
+	template := &watchedTemplateProvider{templateProvider: templateProvider{template: "db://rows/{id}"}}
+	if err := registry.RegisterResourceTemplate(template.template, template); err != nil {
+		t.Fatalf("RegisterResourceTemplate() error = %v", err)
+	}
+
+	resource.updated("file:///config")
+	template.updated("db://rows/1")
+	if err := registry.UnregisterResource(resource.uri); err != nil {
+		t.Fatalf("UnregisterResource() error = %v", err)
+	}
+	if err := registry.UnregisterResourceTemplate(template.template); err != nil {
+		t.Fatalf("UnregisterResourceTemplate() error = %v", err)
+	}
+	resource.updated("file:///config")
+	template.updated("db://rows/2")
+
+	// Registering again watches afresh; the old watch stays stopped
+	stale := resource.updated
+	if err := registry.RegisterResource(resource.uri, resource); err != nil {
+		t.Fatalf("RegisterResource() error = %v", err)
+	}
+	stale("file:///stale")
+	resource.updated("file:///config")
+
+	want := []string{"file:///config", "db://rows/1", "file:///config"}
+	if !reflect.DeepEqual(observed, want) {
+		t.Errorf("observed updates = %v, want %v", observed, want)
+	}
+}
+
+func TestSession_ResourceUpdatedAfterClose(t *testing.T) {
+	t.Parallel()
+
+	manager := NewSessionManager(0)
+	session, err := manager.Create(SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	stream := &recordingSender{}
+	if _, err := session.OpenStream(stream); err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	session.subscribe("file:///config")
+	session.resourceUpdated("file:///config", 10*time.Millisecond)
+	manager.Delete(session.ID())
+	session.resourceUpdated("file:///config", 10*time.Millisecond)
+
+	time.Sleep(30 * time.Millisecond)
+	if got := updatedURIs(stream); len(got) != 0 {
+		t.Errorf("updated notifications after close = %v, want none", got)
+	}
+}
+
+func TestHandler_ListChanged(t *testing.T) {
+	t.Parallel()
+
+	const window = 20 * time.Millisecond
+	handler, tools, _, prompts := NewMCPServices(&Config{
+		ServerName:           "test",
+		ServerVersion:        "1.0.0",
+		ResourceUpdateWindow: window,
+	})
+
+	manager := NewSessionManager(0)
+	t.Cleanup(manager.Close)
+	session, err := manager.Create(SessionOwner{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	stream := &recordingSender{}
+	if _, err := session.OpenStream(stream); err != nil {
+		t.Fatalf("OpenStream() error = %v", err)
+	}
+
+	resp, err := handler.HandleRequest(ContextWithSession(context.Background(), session), &Request{
+		JSONRPC: JSONRPCVersion,
+		ID:      1,
+		Method:  "initialize",
+	})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("initialize = %v, %v", resp, err)
+	}
+	capabilities := resp.Result.(InitializeResult).Capabilities
+	if !capabilities.Tools.ListChanged || !capabilities.Resources.ListChanged {
+		t.Errorf("Capabilities = %+v, want listChanged for tools and resources", capabilities)
+	}
+
+	// listChangedMethods returns the list_changed notifications sent so far
+	listChangedMethods := func() []string {
+		var methods []string
+		for _, msg := range stream.sent() {
+			if notification, ok := msg.(*Notification); ok && notification.Method != MethodResourceUpdated {
+				methods = append(methods, notification.Method)
+			}
+		}
+		return methods
+	}
+	waitFor := func(n int) {
+		deadline := time.Now().Add(time.Second)
+		for len(listChangedMethods()) < n && time.Now().Before(deadline) {
+			time.Sleep(window / 4)
+		}
+		time.Sleep(2 * window)
+	}
+
+	// Changes within the window are notified once per list
+	if err := tools.RegisterTool("plain", plainTool{}); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := tools.UnregisterTool("plain"); err != nil {
+		t.Fatalf("UnregisterTool() error = %v", err)
+	}
+	prompt, err := NewTemplatePrompt(PromptDefinition{Name: "greet"}, nil, PromptMessageTemplate{Role: "user", Text: "Hello"})
+	if err != nil {
+		t.Fatalf("NewTemplatePrompt() error = %v", err)
+	}
+	if err := prompts.RegisterPrompt("greet", prompt); err != nil {
+		t.Fatalf("RegisterPrompt() error = %v", err)
+	}
+
+	waitFor(2)
+	want := []string{MethodPromptsListChanged, MethodToolsListChanged}
+	if got := listChangedMethods(); !reflect.DeepEqual(got, want) {
+		t.Errorf("list_changed notifications = %v, want %v", got, want)
+	}
+
+	// Terminated sessions are no longer notified
+	manager.Delete(session.ID())
+	if err := tools.RegisterTool("plain", plainTool{}); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	time.Sleep(3 * window)
+	if got := listChangedMethods(); len(got) != len(want) {
+		t.Errorf("list_changed notifications after Delete = %v, want no more", got)
+	}
+}
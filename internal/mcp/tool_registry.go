@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	internalerrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
@@ -9,8 +10,9 @@ import (
 
 // toolRegistry implements ToolRegistry with thread-safe access.
 type toolRegistry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
+	mu      sync.RWMutex
+	tools   map[string]Tool
+	changes changeObservers
 }
 
 // NewToolRegistry creates a new thread-safe tool registry.
@@ -32,14 +34,31 @@ func (r *toolRegistry) RegisterTool(name string, tool Tool) error {
 	}
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if _, exists := r.tools[name]; exists {
+		r.mu.Unlock()
 		return internalerrors.New("mcp", "RegisterTool", internalerrors.ErrBadRequest, ErrToolAlreadyRegistered).
 			WithContext("tool_name", name)
 	}
-
 	r.tools[name] = tool
+	r.mu.Unlock()
+
+	r.changes.notify()
+	return nil
+}
+
+// UnregisterTool removes the tool with the given name.
+// Returns ErrToolNotFound if the tool does not exist.
+func (r *toolRegistry) UnregisterTool(name string) error {
+	r.mu.Lock()
+	if _, exists := r.tools[name]; !exists {
+		r.mu.Unlock()
+		return internalerrors.New("mcp", "UnregisterTool", internalerrors.ErrNotFound, ErrToolNotFound).
+			WithContext("tool_name", name)
+	}
+	delete(r.tools, name)
+	r.mu.Unlock()
+
+	r.changes.notify()
 	return nil
 }
 
@@ -62,7 +81,7 @@ func (r *toolRegistry) GetTool(name string) (Tool, error) {
 	return tool, nil
 }
 
-// ListTools returns definitions for all registered tools.
+// ListTools returns definitions for all registered tools, sorted by name.
 // The returned slice is a snapshot and safe for concurrent access.
 func (r *toolRegistry) ListTools() []ToolDefinition {
 	r.mu.RLock()
@@ -73,5 +92,28 @@ func (r *toolRegistry) ListTools() []ToolDefinition {
 		definitions = append(definitions, tool.Definition())
 	}
 
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
 	return definitions
 }
+
+// ObserveChanges registers an observer of tool registrations and removals.
+func (r *toolRegistry) ObserveChanges(observer func()) {
+	r.changes.add(observer)
+}
+
+// RequiredScopes returns the sorted union of the scopes declared by registered tools.
+func (r *toolRegistry) RequiredScopes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var declared [][]string
+	for _, tool := range r.tools {
+		if scoped, ok := tool.(ScopedTool); ok {
+			declared = append(declared, scoped.RequiredScopes())
+		}
+	}
+
+	return unionScopes(declared...)
+}
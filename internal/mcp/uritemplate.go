@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// uriTemplate is a parsed RFC 6570 URI template used to match resource URIs.
+//
+// Simple string expansion ({var}) and reserved expansion ({+var}) are
+// supported. A simple variable matches a non-empty value without "/", "?"
+// or "#", which expansion would have percent-encoded; a reserved variable
+// matches any non-empty value, so file:///{+path} matches nested paths.
+// Matched values are percent-decoded. A value that decodes to one with "?"
+// or "#", or with a "." or ".." path segment, does not match, nor does a
+// simple value with "/", so values cannot traverse out of the part of the
+// URI the variable names.
+type uriTemplate struct {
+	raw          string
+	pattern      *regexp.Regexp
+	names        []string
+	reservedVars []bool
+	literals     int
+	reserved     int
+}
+
+// parseURITemplate parses a URI template. Returns an error for malformed
+// expressions, repeated variables and operators or modifiers other than
+// reserved expansion.
+func parseURITemplate(raw string) (*uriTemplate, error) {
+	t := &uriTemplate{raw: raw}
+	seen := make(map[string]bool)
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	rest := raw
+	for rest != "" {
+		start := strings.IndexAny(rest, "{}")
+		if start < 0 {
+			t.addLiteral(&pattern, rest)
+			break
+		}
+		if rest[start] == '}' {
+			return nil, fmt.Errorf("uri template %q: unmatched '}'", raw)
+		}
+		t.addLiteral(&pattern, rest[:start])
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("uri template %q: unterminated expression", raw)
+		}
+		expression := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		reserved := strings.HasPrefix(expression, "+")
+		name := strings.TrimPrefix(expression, "+")
+		if !validVarName(name) {
+			return nil, fmt.Errorf("uri template %q: unsupported expression {%s}", raw, expression)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("uri template %q: variable %q appears twice", raw, name)
+		}
+		seen[name] = true
+
+		t.names = append(t.names, name)
+		t.reservedVars = append(t.reservedVars, reserved)
+		if reserved {
+			t.reserved++
+			pattern.WriteString("(.+)")
+		} else {
+			pattern.WriteString("([^/?#]+)")
+		}
+	}
+
+	if len(t.names) == 0 {
+		return nil, fmt.Errorf("uri template %q has no variables", raw)
+	}
+
+	pattern.WriteString("$")
+	t.pattern = regexp.MustCompile(pattern.String())
+	return t, nil
+}
+
+// addLiteral appends literal template text to the pattern.
+func (t *uriTemplate) addLiteral(pattern *strings.Builder, literal string) {
+	t.literals += len(literal)
+	pattern.WriteString(regexp.QuoteMeta(literal))
+}
+
+// match reports whether uri is an expansion of the template and returns
+// the decoded values of its variables.
+func (t *uriTemplate) match(uri string) (map[string]string, bool) {
+	groups := t.pattern.FindStringSubmatch(uri)
+	if groups == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(t.names))
+	for i, name := range t.names {
+		value, err := url.PathUnescape(groups[i+1])
+		if err != nil {
+			return nil, false
+		}
+		if !safeValue(value, t.reservedVars[i]) {
+			return nil, false
+		}
+		vars[name] = value
+	}
+	return vars, true
+}
+
+// safeValue reports whether the decoded value of a variable stays within
+// the part of the URI the variable names: it holds no "?" or "#", no "/"
+// unless reserved, and no "." or ".." path segment.
+func safeValue(value string, reserved bool) bool {
+	if strings.ContainsAny(value, "?#") {
+		return false
+	}
+	if !reserved && strings.Contains(value, "/") {
+		return false
+	}
+	for _, segment := range strings.Split(value, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific reports whether t takes precedence over other when both
+// match a URI: the template with more literal text wins, then the one with
+// fewer reserved variables, as those match the widest range of values.
+// Templates equal on both count as equally specific.
+func (t *uriTemplate) moreSpecific(other *uriTemplate) bool {
+	if t.literals != other.literals {
+		return t.literals > other.literals
+	}
+	return t.reserved < other.reserved
+}
+
+// validVarName reports whether name is an RFC 6570 variable name without
+// modifiers: ALPHA, DIGIT, "_", "." and percent-encoded octets.
+func validVarName(name string) bool {
+	if name == "" || strings.HasPrefix(name, ".") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.':
+		case c == '%' && i+2 < len(name) && isHex(name[i+1]) && isHex(name[i+2]):
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
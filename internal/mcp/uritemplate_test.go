@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURITemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{name: "simple", template: "db://tables/{table}/rows/{id}"},
+		{name: "reserved", template: "file:///{+path}"},
+		{name: "encoded name", template: "urn:{na%20me}"},
+		{name: "no variables", template: "file:///etc/hosts", wantErr: true},
+		{name: "unterminated", template: "file:///{path", wantErr: true},
+		{name: "unmatched brace", template: "file:///path}", wantErr: true},
+		{name: "empty expression", template: "file:///{}", wantErr: true},
+		{name: "query operator", template: "search://{?q}", wantErr: true},
+		{name: "explode modifier", template: "file:///{path*}", wantErr: true},
+		{name: "variable list", template: "geo:{x,y}", wantErr: true},
+		{name: "repeated variable", template: "a://{x}/{x}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseURITemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseURITemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestURITemplate_Match(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		uri      string
+		want     map[string]string
+	}{
+		{
+			name:     "simple variables",
+			template: "db://tables/{table}/rows/{id}",
+			uri:      "db://tables/users/rows/42",
+			want:     map[string]string{"table": "users", "id": "42"},
+		},
+		{
+			name:     "percent-decoded",
+			template: "db://tables/{table}/rows/{id}",
+			uri:      "db://tables/user%20data/rows/a%3Ab",
+			want:     map[string]string{"table": "user data", "id": "a:b"},
+		},
+		{
+			name:     "simple variable rejects encoded slash",
+			template: "file:///docs/{name}",
+			uri:      "file:///docs/..%2F..%2Fetc%2Fpasswd",
+		},
+		{
+			name:     "simple variable rejects encoded query",
+			template: "db://tables/{table}",
+			uri:      "db://tables/users%3Fdrop",
+		},
+		{
+			name:     "simple variable rejects dot segment",
+			template: "file:///docs/{name}/index",
+			uri:      "file:///docs/%2E%2E/index",
+		},
+		{
+			name:     "reserved variable rejects dot segments",
+			template: "file:///{+path}",
+			uri:      "file:///a/../../etc",
+		},
+		{
+			name:     "reserved variable rejects encoded dot segments",
+			template: "file:///{+path}",
+			uri:      "file:///a%2F..%2F..%2Fetc",
+		},
+		{
+			name:     "reserved variable rejects query",
+			template: "file:///{+path}",
+			uri:      "file:///a%3Fb",
+		},
+		{
+			name:     "reserved variable keeps encoded slash",
+			template: "file:///{+path}",
+			uri:      "file:///docs/a%2Fb",
+			want:     map[string]string{"path": "docs/a/b"},
+		},
+		{
+			name:     "simple variable stops at slash",
+			template: "file:///{path}",
+			uri:      "file:///docs/readme.md",
+		},
+		{
+			name:     "reserved variable spans slashes",
+			template: "file:///{+path}",
+			uri:      "file:///docs/readme.md",
+			want:     map[string]string{"path": "docs/readme.md"},
+		},
+		{
+			name:     "empty value",
+			template: "db://tables/{table}",
+			uri:      "db://tables/",
+		},
+		{
+			name:     "literal mismatch",
+			template: "db://tables/{table}",
+			uri:      "db://views/users",
+		},
+		{
+			name:     "regexp metacharacters are literal",
+			template: "a+b://{x}.json",
+			uri:      "aab://1xjson",
+		},
+		{
+			name:     "invalid escape",
+			template: "db://tables/{table}",
+			uri:      "db://tables/%zz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			template, err := parseURITemplate(tt.template)
+			if err != nil {
+				t.Fatalf("parseURITemplate(%q) error = %v", tt.template, err)
+			}
+
+			got, ok := template.match(tt.uri)
+			if ok != (tt.want != nil) {
+				t.Fatalf("match(%q) ok = %v, want %v", tt.uri, ok, tt.want != nil)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("match(%q) = %v, want %v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
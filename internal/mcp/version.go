@@ -0,0 +1,85 @@
+package mcp
+
+import "slices"
+
+// MCP protocol revisions supported by the server.
+const (
+	// ProtocolVersion20241105 is the 2024-11-05 revision.
+	ProtocolVersion20241105 = "2024-11-05"
+
+	// ProtocolVersion20250326 is the 2025-03-26 revision, which introduced
+	// the Streamable HTTP transport and JSON-RPC batching.
+	ProtocolVersion20250326 = "2025-03-26"
+
+	// ProtocolVersion20250618 is the 2025-06-18 revision, which removed
+	// batching and introduced structured tool output, elicitation and the
+	// MCP-Protocol-Version header.
+	ProtocolVersion20250618 = "2025-06-18"
+)
+
+// supportedProtocolVersions lists the supported revisions, newest first.
+var supportedProtocolVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// SupportedProtocolVersions returns the supported protocol revisions,
+// newest first.
+func SupportedProtocolVersions() []string {
+	return slices.Clone(supportedProtocolVersions)
+}
+
+// IsSupportedProtocolVersion reports whether version is a supported
+// protocol revision.
+func IsSupportedProtocolVersion(version string) bool {
+	return slices.Contains(supportedProtocolVersions, version)
+}
+
+// NegotiateProtocolVersion returns the protocol version to answer an
+// initialize request for requested with. A supported version is accepted
+// as is; otherwise the latest supported version is offered and the client
+// decides whether it can continue.
+func NegotiateProtocolVersion(requested string) string {
+	if IsSupportedProtocolVersion(requested) {
+		return requested
+	}
+	return ProtocolVersion
+}
+
+// Features describes the behaviour that differs between protocol
+// revisions.
+type Features struct {
+	// Batching allows JSON-RPC batches in one request body. Only the
+	// 2025-03-26 revision supports them.
+	Batching bool
+
+	// StructuredContent allows tools to declare an output schema and
+	// return structured content alongside text content.
+	StructuredContent bool
+
+	// Elicitation allows the server to ask the user for input during a
+	// request. It also requires the client's elicitation capability.
+	Elicitation bool
+
+	// ProtocolVersionHeader requires clients to send the
+	// MCP-Protocol-Version header on every request after initialize.
+	ProtocolVersionHeader bool
+}
+
+// FeaturesFor returns the features of a protocol revision. Unknown
+// versions have no optional features.
+func FeaturesFor(version string) Features {
+	switch version {
+	case ProtocolVersion20250618:
+		return Features{
+			StructuredContent:     true,
+			Elicitation:           true,
+			ProtocolVersionHeader: true,
+		}
+	case ProtocolVersion20250326:
+		return Features{Batching: true}
+	default:
+		return Features{}
+	}
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{requested: "2024-11-05", want: "2024-11-05"},
+		{requested: "2025-03-26", want: "2025-03-26"},
+		{requested: "2025-06-18", want: "2025-06-18"},
+		{requested: "2099-01-01", want: ProtocolVersion},
+		{requested: "2024-10-07", want: ProtocolVersion},
+		{requested: "", want: ProtocolVersion},
+	}
+
+	for _, tt := range tests {
+		if got := NegotiateProtocolVersion(tt.requested); got != tt.want {
+			t.Errorf("NegotiateProtocolVersion(%q) = %q, want %q", tt.requested, got, tt.want)
+		}
+	}
+
+	if versions := SupportedProtocolVersions(); versions[0] != ProtocolVersion {
+		t.Errorf("SupportedProtocolVersions()[0] = %q, want the latest version %q", versions[0], ProtocolVersion)
+	}
+}
+
+func TestFeaturesFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version string
+		want    Features
+	}{
+		{version: ProtocolVersion20241105, want: Features{}},
+		{version: ProtocolVersion20250326, want: Features{Batching: true}},
+		{version: ProtocolVersion20250618, want: Features{StructuredContent: true, Elicitation: true, ProtocolVersionHeader: true}},
+		{version: "unknown", want: Features{}},
+	}
+
+	for _, tt := range tests {
+		if got := FeaturesFor(tt.version); got != tt.want {
+			t.Errorf("FeaturesFor(%q) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_InitializeNegotiatesVersion(t *testing.T) {
+	t.Parallel()
+
+	handler, _, _, _ := NewMCPServices(&Config{ServerName: "test", ServerVersion: "1.0.0"})
+	manager := NewSessionManager(time.Hour)
+
+	tests := []struct {
+		name            string
+		params          string
+		wantVersion     string
+		wantElicitation bool
+	}{
+		{
+			name:        "oldest revision",
+			params:      `{"protocolVersion":"2024-11-05"}`,
+			wantVersion: ProtocolVersion20241105,
+		},
+		{
+			name:        "batching revision",
+			params:      `{"protocolVersion":"2025-03-26"}`,
+			wantVersion: ProtocolVersion20250326,
+		},
+		{
+			name:            "latest revision with elicitation",
+			params:          `{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}}}`,
+			wantVersion:     ProtocolVersion20250618,
+			wantElicitation: true,
+		},
+		{
+			name:        "latest revision without elicitation",
+			params:      `{"protocolVersion":"2025-06-18"}`,
+			wantVersion: ProtocolVersion20250618,
+		},
+		{
+			name:        "unsupported revision",
+			params:      `{"protocolVersion":"2030-01-01"}`,
+			wantVersion: ProtocolVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			session, err := manager.Create(SessionOwner{})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			resp, err := handler.HandleRequest(ContextWithSession(context.Background(), session), &Request{
+				JSONRPC: JSONRPCVersion,
+				ID:      1,
+				Method:  "initialize",
+				Params:  json.RawMessage(tt.params),
+			})
+			if err != nil || resp.Error != nil {
+				t.Fatalf("initialize = %v, %v", resp, err)
+			}
+
+			if result := resp.Result.(InitializeResult); result.ProtocolVersion != tt.wantVersion {
+				t.Errorf("result protocolVersion = %q, want %q", result.ProtocolVersion, tt.wantVersion)
+			}
+			if version := session.ProtocolVersion(); version != tt.wantVersion {
+				t.Errorf("session ProtocolVersion() = %q, want %q", version, tt.wantVersion)
+			}
+			if features := session.Features(); features.Elicitation != tt.wantElicitation {
+				t.Errorf("session Features().Elicitation = %v, want %v", features.Elicitation, tt.wantElicitation)
+			}
+		})
+	}
+}
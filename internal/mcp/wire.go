@@ -1,5 +1,7 @@
 package mcp
 
+import "time"
+
 // Config holds configuration for MCP services.
 type Config struct {
 	// ServerName is the name of the MCP server.
@@ -7,11 +9,24 @@ type Config struct {
 
 	// ServerVersion is the version of the MCP server.
 	ServerVersion string
+
+	// ResourceUpdateWindow is how long resource updates are collected
+	// before subscribed clients are notified, so that a resource changing
+	// repeatedly is notified once per window. Zero uses
+	// DefaultResourceUpdateWindow.
+	ResourceUpdateWindow time.Duration
+
+	// PageSize is the number of items per page of the list methods.
+	// Zero uses DefaultPageSize.
+	PageSize int
 }
 
+// DefaultResourceUpdateWindow is the default ResourceUpdateWindow.
+const DefaultResourceUpdateWindow = 100 * time.Millisecond
+
 // NewHandler creates a new MCP protocol handler.
 // The handler routes JSON-RPC requests to the appropriate registries.
-func NewHandler(cfg *Config, toolRegistry ToolRegistry, resourceRegistry ResourceRegistry) Handler {
+func NewHandler(cfg *Config, toolRegistry ToolRegistry, resourceRegistry ResourceRegistry, promptRegistry PromptRegistry) Handler {
 	if cfg == nil {
 		panic("config cannot be nil")
 	}
@@ -21,21 +36,35 @@ func NewHandler(cfg *Config, toolRegistry ToolRegistry, resourceRegistry Resourc
 	if resourceRegistry == nil {
 		panic("resourceRegistry cannot be nil")
 	}
+	if promptRegistry == nil {
+		panic("promptRegistry cannot be nil")
+	}
 
 	info := serverInfo{
 		Name:    cfg.ServerName,
 		Version: cfg.ServerVersion,
 	}
 
-	return newHandler(toolRegistry, resourceRegistry, info)
+	updateWindow := cfg.ResourceUpdateWindow
+	if updateWindow <= 0 {
+		updateWindow = DefaultResourceUpdateWindow
+	}
+
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return newHandler(toolRegistry, resourceRegistry, promptRegistry, info, updateWindow, pageSize)
 }
 
 // NewMCPServices creates all MCP services from the configuration.
 // This is a convenience function for dependency injection.
-func NewMCPServices(cfg *Config) (Handler, ToolRegistry, ResourceRegistry) {
+func NewMCPServices(cfg *Config) (Handler, ToolRegistry, ResourceRegistry, PromptRegistry) {
 	toolRegistry := NewToolRegistry()
 	resourceRegistry := NewResourceRegistry()
-	handler := NewHandler(cfg, toolRegistry, resourceRegistry)
+	promptRegistry := NewPromptRegistry()
+	handler := NewHandler(cfg, toolRegistry, resourceRegistry, promptRegistry)
 
-	return handler, toolRegistry, resourceRegistry
+	return handler, toolRegistry, resourceRegistry, promptRegistry
 }
@@ -0,0 +1,193 @@
+package devserver
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// authorizationCode is an issued, not yet redeemed authorization code.
+type authorizationCode struct {
+	clientID      string
+	redirectURI   string
+	redirectGiven bool // the authorization request named redirectURI
+	codeChallenge string
+	subject       string
+	scopes        []string
+	expiresAt     time.Time
+}
+
+// handleAuthorize implements the authorization endpoint. Every valid
+// request is approved for the configured user without interaction.
+//
+// Errors concerning the client or redirect URI are returned to the user
+// agent; all others are reported to the client through the redirect URI
+// (RFC 6749 Section 4.1.2.1).
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	c, ok := s.clients[query.Get("client_id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, errInvalidClient, "unknown client_id")
+		return
+	}
+
+	redirectURI, ok := c.matchRedirectURI(query.Get("redirect_uri"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, errInvalidRequest, "redirect_uri is not registered for this client")
+		return
+	}
+
+	state := query.Get("state")
+	redirectError := func(code, description string) {
+		s.redirect(w, r, redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+			"state":             {state},
+		})
+	}
+
+	if query.Get("response_type") != pkgoauth.ResponseTypeCode {
+		redirectError(errUnsupportedResponseType, "response_type must be code")
+		return
+	}
+	if !c.allowsGrant(pkgoauth.GrantTypeAuthorizationCode) {
+		redirectError(errUnauthorizedClient, "client is not registered for the authorization_code grant")
+		return
+	}
+
+	// OAuth 2.1 requires PKCE, and only S256 is accepted
+	challenge := query.Get("code_challenge")
+	if challenge == "" {
+		redirectError(errInvalidRequest, "code_challenge is required")
+		return
+	}
+	if query.Get("code_challenge_method") != pkgoauth.CodeChallengeMethodS256 {
+		redirectError(errInvalidRequest, "code_challenge_method must be S256")
+		return
+	}
+
+	scopes, ok := s.grantScopes(c, strings.Fields(query.Get("scope")))
+	if !ok {
+		redirectError(errInvalidScope, "requested scope is not supported")
+		return
+	}
+
+	code := randomToken()
+	s.mu.Lock()
+	s.purgeExpiredLocked()
+	s.codes[code] = &authorizationCode{
+		clientID:      c.id,
+		redirectURI:   redirectURI,
+		redirectGiven: query.Get("redirect_uri") != "",
+		codeChallenge: challenge,
+		subject:       s.user,
+		scopes:        scopes,
+		expiresAt:     s.now().Add(codeTTL),
+	}
+	s.mu.Unlock()
+
+	slog.Info("dev authorization server approved request",
+		"client_id", c.id,
+		"subject", s.user,
+		"scope", strings.Join(scopes, " "),
+	)
+
+	s.redirect(w, r, redirectURI, url.Values{
+		"code":  {code},
+		"state": {state},
+	})
+}
+
+// redirect sends the user agent back to the client with params added to
+// the redirect URI query, including the RFC 9207 iss parameter.
+func (s *Server) redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidRequest, "invalid redirect_uri")
+		return
+	}
+
+	query := target.Query()
+	for name, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(name, values[0])
+		}
+	}
+	query.Set("iss", s.issuer)
+	target.RawQuery = query.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// grantScopes resolves the scopes granted for a request. No requested scope
+// selects the client's registered scopes, or the default scopes if the
+// client registered none. It reports false if a scope is not supported.
+func (s *Server) grantScopes(c *client, requested []string) ([]string, bool) {
+	if len(requested) == 0 {
+		if len(c.scopes) > 0 {
+			return c.scopes, true
+		}
+		return s.defaultScopes, true
+	}
+
+	if !s.scopesAllowed(requested) {
+		return nil, false
+	}
+	if len(c.scopes) > 0 && !subset(requested, c.scopes) {
+		return nil, false
+	}
+	return requested, true
+}
+
+// scopesAllowed reports whether every scope is supported by the server.
+func (s *Server) scopesAllowed(scopes []string) bool {
+	return len(s.scopesSupported) == 0 || subset(scopes, s.scopesSupported)
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against an S256 challenge
+// (RFC 7636 Section 4.6).
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// purgeExpiredLocked drops expired authorization codes and refresh tokens.
+// The caller must hold s.mu.
+func (s *Server) purgeExpiredLocked() {
+	now := s.now()
+	for code, grant := range s.codes {
+		if now.After(grant.expiresAt) {
+			delete(s.codes, code)
+		}
+	}
+	for token, grant := range s.refreshTokens {
+		if now.After(grant.expiresAt) {
+			delete(s.refreshTokens, token)
+		}
+	}
+}
+
+// subset reports whether every value of a is in b.
+func subset(a, b []string) bool {
+	for _, v := range a {
+		if !contains(b, v) {
+			return false
+		}
+	}
+	return true
+}
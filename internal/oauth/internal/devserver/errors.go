@@ -0,0 +1,33 @@
+package devserver
+
+import (
+	"net/http"
+
+	ierrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
+)
+
+// OAuth error codes returned by the server (RFC 6749 Section 4.1.2.1 and
+// 5.2, RFC 7591 Section 3.2.2).
+const (
+	errInvalidRequest          = ierrors.ErrorCodeInvalidRequest
+	errInvalidClient           = "invalid_client"
+	errInvalidGrant            = "invalid_grant"
+	errInvalidScope            = "invalid_scope"
+	errUnauthorizedClient      = "unauthorized_client"
+	errUnsupportedGrantType    = "unsupported_grant_type"
+	errUnsupportedResponseType = "unsupported_response_type"
+	errInvalidRedirectURI      = "invalid_redirect_uri"
+	errInvalidClientMetadata   = "invalid_client_metadata"
+	errServerError             = "server_error"
+)
+
+// errorResponse is an OAuth error response body.
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// writeError sends an OAuth error response.
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, errorResponse{Error: code, ErrorDescription: description})
+}
@@ -0,0 +1,239 @@
+package devserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// Client authentication methods at the token endpoint (RFC 7591 Section 2).
+const (
+	authMethodNone              = "none"
+	authMethodClientSecretBasic = "client_secret_basic"
+	authMethodClientSecretPost  = "client_secret_post"
+)
+
+var (
+	supportedGrantTypes = []string{
+		pkgoauth.GrantTypeAuthorizationCode,
+		pkgoauth.GrantTypeRefreshToken,
+		pkgoauth.GrantTypeClientCredentials,
+	}
+	supportedAuthMethods = []string{
+		authMethodNone,
+		authMethodClientSecretBasic,
+		authMethodClientSecretPost,
+	}
+)
+
+// client is a registered client.
+type client struct {
+	id           string
+	secret       string
+	redirectURIs []string
+	grantTypes   []string
+	authMethod   string
+	scopes       []string
+}
+
+// allowsGrant reports whether the client registered grantType.
+func (c *client) allowsGrant(grantType string) bool {
+	return contains(c.grantTypes, grantType)
+}
+
+// checkSecret reports whether secret is the client secret, in constant time.
+func (c *client) checkSecret(secret string) bool {
+	return c.secret != "" && subtle.ConstantTimeCompare([]byte(c.secret), []byte(secret)) == 1
+}
+
+// matchRedirectURI returns the registered redirect URI that redirectURI
+// selects. An empty redirectURI selects the only registered URI. Loopback
+// redirect URIs match on any port (RFC 8252 Section 7.3).
+func (c *client) matchRedirectURI(redirectURI string) (string, bool) {
+	if redirectURI == "" {
+		if len(c.redirectURIs) == 1 {
+			return c.redirectURIs[0], true
+		}
+		return "", false
+	}
+
+	for _, registered := range c.redirectURIs {
+		if registered == redirectURI || sameLoopbackURI(registered, redirectURI) {
+			return redirectURI, true
+		}
+	}
+	return "", false
+}
+
+// clientMetadata is the RFC 7591 client metadata used in registration
+// requests and responses.
+type clientMetadata struct {
+	RedirectURIs            []string `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes              []string `json:"grant_types,omitempty"`
+	ResponseTypes           []string `json:"response_types,omitempty"`
+	ClientName              string   `json:"client_name,omitempty"`
+	Scope                   string   `json:"scope,omitempty"`
+}
+
+// registrationResponse is the RFC 7591 client information response.
+type registrationResponse struct {
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt      int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt *int64 `json:"client_secret_expires_at,omitempty"`
+	clientMetadata
+}
+
+// handleRegister implements RFC 7591 dynamic client registration. Any
+// client may register; there is no initial access token.
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req clientMetadata
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidClientMetadata, "request body must be a JSON object")
+		return
+	}
+
+	c, code, err := s.newClient(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, code, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.clients[c.id] = c
+	s.mu.Unlock()
+
+	resp := registrationResponse{
+		ClientID:         c.id,
+		ClientSecret:     c.secret,
+		ClientIDIssuedAt: s.now().Unix(),
+		clientMetadata: clientMetadata{
+			RedirectURIs:            c.redirectURIs,
+			TokenEndpointAuthMethod: c.authMethod,
+			GrantTypes:              c.grantTypes,
+			ResponseTypes:           req.ResponseTypes,
+			ClientName:              req.ClientName,
+			Scope:                   strings.Join(c.scopes, " "),
+		},
+	}
+	if c.secret != "" {
+		// Secrets never expire
+		var never int64
+		resp.ClientSecretExpiresAt = &never
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// newClient validates registration metadata, applying the RFC 7591 defaults,
+// and returns the client with the error code to report on failure.
+func (s *Server) newClient(req *clientMetadata) (*client, string, error) {
+	c := &client{
+		id:           randomToken(),
+		redirectURIs: req.RedirectURIs,
+		grantTypes:   req.GrantTypes,
+		authMethod:   req.TokenEndpointAuthMethod,
+		scopes:       strings.Fields(req.Scope),
+	}
+	if len(c.grantTypes) == 0 {
+		c.grantTypes = []string{pkgoauth.GrantTypeAuthorizationCode}
+	}
+	if c.authMethod == "" {
+		c.authMethod = authMethodClientSecretBasic
+	}
+	if len(req.ResponseTypes) == 0 && c.allowsGrant(pkgoauth.GrantTypeAuthorizationCode) {
+		req.ResponseTypes = []string{pkgoauth.ResponseTypeCode}
+	}
+
+	if !contains(supportedAuthMethods, c.authMethod) {
+		return nil, errInvalidClientMetadata, fmt.Errorf("unsupported token_endpoint_auth_method %q", c.authMethod)
+	}
+	for _, grantType := range c.grantTypes {
+		if !contains(supportedGrantTypes, grantType) {
+			return nil, errInvalidClientMetadata, fmt.Errorf("unsupported grant type %q", grantType)
+		}
+	}
+	for _, responseType := range req.ResponseTypes {
+		if responseType != pkgoauth.ResponseTypeCode {
+			return nil, errInvalidClientMetadata, fmt.Errorf("unsupported response type %q", responseType)
+		}
+	}
+	if c.authMethod == authMethodNone && c.allowsGrant(pkgoauth.GrantTypeClientCredentials) {
+		return nil, errInvalidClientMetadata, fmt.Errorf("client_credentials requires a confidential client")
+	}
+	if !s.scopesAllowed(c.scopes) {
+		return nil, errInvalidClientMetadata, fmt.Errorf("scope contains unsupported values")
+	}
+
+	if c.allowsGrant(pkgoauth.GrantTypeAuthorizationCode) && len(c.redirectURIs) == 0 {
+		return nil, errInvalidRedirectURI, fmt.Errorf("redirect_uris is required for the authorization_code grant")
+	}
+	for _, redirectURI := range c.redirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, errInvalidRedirectURI, err
+		}
+	}
+
+	if c.authMethod != authMethodNone {
+		c.secret = randomToken()
+	}
+
+	return c, "", nil
+}
+
+// validateRedirectURI enforces the OAuth 2.1 redirect URI rules: absolute,
+// without fragment, and https unless the host is a loopback address.
+// Private-use schemes for native apps are allowed.
+func validateRedirectURI(redirectURI string) error {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || !parsed.IsAbs() {
+		return fmt.Errorf("redirect URI %q must be an absolute URI", redirectURI)
+	}
+	if parsed.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not contain a fragment", redirectURI)
+	}
+	if parsed.Scheme == "http" && !isLoopbackHost(parsed.Hostname()) {
+		return fmt.Errorf("redirect URI %q must use https unless the host is a loopback address", redirectURI)
+	}
+	return nil
+}
+
+// sameLoopbackURI reports whether a and b are loopback http URIs that differ
+// only in port.
+func sameLoopbackURI(a, b string) bool {
+	pa, errA := url.Parse(a)
+	pb, errB := url.Parse(b)
+	if errA != nil || errB != nil || pa.Scheme != "http" || pb.Scheme != "http" {
+		return false
+	}
+	if !isLoopbackHost(pa.Hostname()) || pa.Hostname() != pb.Hostname() {
+		return false
+	}
+	return pa.Path == pb.Path && pa.RawQuery == pb.RawQuery
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP address.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// contains reports whether values contains v.
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+// Package devserver implements a minimal OAuth 2.1 authorization server for
+// local development. It auto-approves every authorization request for a
+// configured user, keeps all state in memory and must never be exposed to
+// untrusted networks.
+package devserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/metadata"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// Default lifetimes of the tokens issued by the server.
+const (
+	DefaultAccessTokenTTL  = time.Hour
+	DefaultRefreshTokenTTL = 24 * time.Hour
+)
+
+// codeTTL is the lifetime of an authorization code.
+const codeTTL = time.Minute
+
+// Endpoint paths, relative to the issuer.
+const (
+	metadataPath  = "/.well-known/oauth-authorization-server"
+	jwksPath      = "/jwks"
+	authorizePath = "/authorize"
+	tokenPath     = "/token"
+	registerPath  = "/register"
+)
+
+// Config holds the settings of the development authorization server.
+type Config struct {
+	// Issuer is the issuer identifier. Endpoints are served below its path.
+	Issuer string
+
+	// Audience is the aud claim of every access token. It must match the
+	// audience the resource server validates.
+	Audience string
+
+	// User is the subject auto-approved at the authorization endpoint.
+	User string
+
+	// ScopesSupported lists the scopes clients may request. Empty allows any scope.
+	ScopesSupported []string
+
+	// DefaultScopes are granted when a request names no scope.
+	DefaultScopes []string
+
+	// AccessTokenTTL is the lifetime of access tokens. Defaults to DefaultAccessTokenTTL.
+	AccessTokenTTL time.Duration
+
+	// RefreshTokenTTL is the lifetime of refresh tokens. Defaults to DefaultRefreshTokenTTL.
+	RefreshTokenTTL time.Duration
+
+	// SigningKey signs access tokens. A P-256 key is generated when nil.
+	SigningKey *metadata.SigningKey
+}
+
+// Server is an in-memory OAuth 2.1 authorization server. It implements
+// http.Handler and serves RFC 8414 metadata, a JWKS, the authorization and
+// token endpoints and RFC 7591 dynamic client registration.
+type Server struct {
+	issuer          string
+	audience        string
+	user            string
+	scopesSupported []string
+	defaultScopes   []string
+	accessTTL       time.Duration
+	refreshTTL      time.Duration
+	key             *metadata.SigningKey
+	mux             *http.ServeMux
+	now             func() time.Time
+
+	mu            sync.Mutex
+	clients       map[string]*client
+	codes         map[string]*authorizationCode
+	refreshTokens map[string]*refreshGrant
+}
+
+// NewServer creates a development authorization server from cfg.
+func NewServer(cfg *Config) (*Server, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	issuer, err := url.Parse(cfg.Issuer)
+	if err != nil || !issuer.IsAbs() || issuer.RawQuery != "" || issuer.Fragment != "" {
+		return nil, fmt.Errorf("issuer must be an absolute URL without query or fragment")
+	}
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("audience cannot be empty")
+	}
+	if cfg.User == "" {
+		return nil, fmt.Errorf("user cannot be empty")
+	}
+
+	key := cfg.SigningKey
+	if key == nil {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		if key, err = metadata.NewSigningKey(ecKey, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Server{
+		issuer:          strings.TrimRight(cfg.Issuer, "/"),
+		audience:        cfg.Audience,
+		user:            cfg.User,
+		scopesSupported: cfg.ScopesSupported,
+		defaultScopes:   cfg.DefaultScopes,
+		accessTTL:       cfg.AccessTokenTTL,
+		refreshTTL:      cfg.RefreshTokenTTL,
+		key:             key,
+		mux:             http.NewServeMux(),
+		now:             time.Now,
+		clients:         make(map[string]*client),
+		codes:           make(map[string]*authorizationCode),
+		refreshTokens:   make(map[string]*refreshGrant),
+	}
+	if s.accessTTL <= 0 {
+		s.accessTTL = DefaultAccessTokenTTL
+	}
+	if s.refreshTTL <= 0 {
+		s.refreshTTL = DefaultRefreshTokenTTL
+	}
+
+	// Metadata is served both below the issuer, where the JWKS client looks
+	// for it, and at the RFC 8414 location with the issuer path inserted.
+	base := strings.TrimRight(issuer.EscapedPath(), "/")
+	s.mux.HandleFunc("GET "+base+metadataPath, s.handleMetadata)
+	if base != "" {
+		s.mux.HandleFunc("GET "+metadataPath+base, s.handleMetadata)
+	}
+	s.mux.HandleFunc("GET "+base+jwksPath, s.handleJWKS)
+	s.mux.HandleFunc("GET "+base+authorizePath, s.handleAuthorize)
+	s.mux.HandleFunc("POST "+base+tokenPath, s.handleToken)
+	s.mux.HandleFunc("POST "+base+registerPath, s.handleRegister)
+
+	return s, nil
+}
+
+// Issuer returns the issuer identifier of the server.
+func (s *Server) Issuer() string {
+	return s.issuer
+}
+
+// ServeHTTP dispatches requests to the authorization server endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// serverMetadata is the RFC 8414 authorization server metadata document.
+type serverMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	RegistrationEndpoint              string   `json:"registration_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	AuthorizationResponseIssParameter bool     `json:"authorization_response_iss_parameter_supported"`
+}
+
+// handleMetadata serves the authorization server metadata.
+func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, serverMetadata{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             s.issuer + authorizePath,
+		TokenEndpoint:                     s.issuer + tokenPath,
+		RegistrationEndpoint:              s.issuer + registerPath,
+		JWKSURI:                           s.issuer + jwksPath,
+		ScopesSupported:                   s.scopesSupported,
+		ResponseTypesSupported:            []string{pkgoauth.ResponseTypeCode},
+		GrantTypesSupported:               supportedGrantTypes,
+		TokenEndpointAuthMethodsSupported: supportedAuthMethods,
+		CodeChallengeMethodsSupported:     []string{pkgoauth.CodeChallengeMethodS256},
+		AuthorizationResponseIssParameter: true,
+	})
+}
+
+// handleJWKS serves the public key that verifies access tokens.
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, metadata.JSONWebKeySet{Keys: []metadata.JSONWebKey{s.key.PublicJWK()}})
+}
+
+// randomToken returns 32 random bytes encoded as unpadded base64url.
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the system entropy source is broken
+		panic(fmt.Sprintf("devserver: failed to read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// writeJSON sends v as a JSON response. Responses carry credentials, so
+// they are never cached.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode dev authorization server response", "error", err)
+		// Can't send error response here since headers are already written
+	}
+}
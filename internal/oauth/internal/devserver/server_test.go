@@ -0,0 +1,471 @@
+package devserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/jwks"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/token"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+const (
+	testAudience = "https://example.com/mcp"
+	testVerifier = "dBjftJeZ4CVP-mJ92K9bX1kGzLg3TvXbjGZRn2YaOaE-test"
+	testRedirect = "http://127.0.0.1:9000/callback"
+)
+
+// newTestServer starts the development server below /dev-as.
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+
+	var srv *Server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	var err error
+	srv, err = NewServer(&Config{
+		Issuer:          ts.URL + "/dev-as",
+		Audience:        testAudience,
+		User:            "alice",
+		ScopesSupported: []string{pkgoauth.ScopeRead, pkgoauth.ScopeWrite},
+		DefaultScopes:   []string{pkgoauth.ScopeRead},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	return srv, ts
+}
+
+// register registers a client and returns the registration response.
+func register(t *testing.T, ts *httptest.Server, metadata string) (int, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Post(ts.URL+"/dev-as/register", pkgoauth.ContentTypeJSON, strings.NewReader(metadata))
+	if err != nil {
+		t.Fatalf("register request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode registration response: %v", err)
+	}
+	return resp.StatusCode, body
+}
+
+// authorize runs the authorization endpoint and returns the redirect query.
+func authorize(t *testing.T, ts *httptest.Server, params url.Values) (int, url.Values) {
+	t.Helper()
+
+	httpClient := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := httpClient.Get(ts.URL + "/dev-as/authorize?" + params.Encode())
+	if err != nil {
+		t.Fatalf("authorize request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return resp.StatusCode, nil
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect location: %v", err)
+	}
+	return resp.StatusCode, location.Query()
+}
+
+// tokenRequest posts form to the token endpoint, with Basic credentials if set.
+func tokenRequest(t *testing.T, ts *httptest.Server, form url.Values, basicID, basicSecret string) (int, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/dev-as/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatalf("failed to build token request: %v", err)
+	}
+	req.Header.Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeFormURLEncoded)
+	if basicID != "" {
+		req.SetBasicAuth(url.QueryEscape(basicID), url.QueryEscape(basicSecret))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("token request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode token response: %v", err)
+	}
+	return resp.StatusCode, body
+}
+
+// challenge returns the S256 code challenge of verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorizeParams returns a valid authorization request for clientID.
+func authorizeParams(clientID string) url.Values {
+	return url.Values{
+		"response_type":         {pkgoauth.ResponseTypeCode},
+		"client_id":             {clientID},
+		"redirect_uri":          {testRedirect},
+		"code_challenge":        {challenge(testVerifier)},
+		"code_challenge_method": {pkgoauth.CodeChallengeMethodS256},
+		"state":                 {"xyz"},
+	}
+}
+
+// newValidator returns a token validator trusting the development server.
+func newValidator(srv *Server) *token.Validator {
+	return token.NewValidator(jwks.NewClient([]string{srv.Issuer()}, time.Minute), testAudience, time.Minute)
+}
+
+func TestServer_AuthorizationCodeFlow(t *testing.T) {
+	t.Parallel()
+
+	srv, ts := newTestServer(t)
+
+	status, client := register(t, ts, `{"redirect_uris":["`+testRedirect+`"],"token_endpoint_auth_method":"none",`+
+		`"grant_types":["authorization_code","refresh_token"],"client_name":"Test"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("register status = %d, want %d: %v", status, http.StatusCreated, client)
+	}
+	if _, ok := client["client_secret"]; ok {
+		t.Error("public client was issued a client_secret")
+	}
+	clientID := client["client_id"].(string)
+
+	// The redirect port of a loopback URI may differ from the registered one
+	params := authorizeParams(clientID)
+	params.Set("redirect_uri", "http://127.0.0.1:54321/callback")
+	params.Set("scope", pkgoauth.ScopeRead+" "+pkgoauth.ScopeWrite)
+	status, redirect := authorize(t, ts, params)
+	if status != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", status, http.StatusFound)
+	}
+	if redirect.Get("state") != "xyz" || redirect.Get("iss") != srv.Issuer() {
+		t.Errorf("redirect state = %q, iss = %q", redirect.Get("state"), redirect.Get("iss"))
+	}
+
+	form := url.Values{
+		"grant_type":    {pkgoauth.GrantTypeAuthorizationCode},
+		"code":          {redirect.Get("code")},
+		"redirect_uri":  {"http://127.0.0.1:54321/callback"},
+		"code_verifier": {testVerifier},
+		"client_id":     {clientID},
+	}
+	status, tokens := tokenRequest(t, ts, form, "", "")
+	if status != http.StatusOK {
+		t.Fatalf("token status = %d, want %d: %v", status, http.StatusOK, tokens)
+	}
+	if tokens["token_type"] != pkgoauth.TokenTypeBearer || tokens["refresh_token"] == nil {
+		t.Errorf("token response = %v, want Bearer token with refresh_token", tokens)
+	}
+
+	validator := newValidator(srv)
+	claims, err := validator.ValidateToken(context.Background(), tokens["access_token"].(string))
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Subject != "alice" || claims.Issuer != srv.Issuer() {
+		t.Errorf("claims sub = %q, iss = %q", claims.Subject, claims.Issuer)
+	}
+	if !claims.HasAllScopes(pkgoauth.ScopeRead, pkgoauth.ScopeWrite) {
+		t.Errorf("claims scopes = %v, want read and write", claims.Scopes)
+	}
+
+	// Codes are single use
+	if status, _ := tokenRequest(t, ts, form, "", ""); status != http.StatusBadRequest {
+		t.Errorf("second redemption status = %d, want %d", status, http.StatusBadRequest)
+	}
+
+	// Refresh with a narrower scope; the old refresh token is rotated out
+	refreshForm := url.Values{
+		"grant_type":    {pkgoauth.GrantTypeRefreshToken},
+		"refresh_token": {tokens["refresh_token"].(string)},
+		"scope":         {pkgoauth.ScopeRead},
+		"client_id":     {clientID},
+	}
+	status, refreshed := tokenRequest(t, ts, refreshForm, "", "")
+	if status != http.StatusOK {
+		t.Fatalf("refresh status = %d, want %d: %v", status, http.StatusOK, refreshed)
+	}
+	claims, err = validator.ValidateToken(context.Background(), refreshed["access_token"].(string))
+	if err != nil {
+		t.Fatalf("ValidateToken() after refresh error = %v", err)
+	}
+	if claims.HasScope(pkgoauth.ScopeWrite) {
+		t.Errorf("refreshed scopes = %v, want read only", claims.Scopes)
+	}
+	if status, _ := tokenRequest(t, ts, refreshForm, "", ""); status != http.StatusBadRequest {
+		t.Errorf("reused refresh token status = %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestServer_ClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	srv, ts := newTestServer(t)
+
+	status, client := register(t, ts, `{"grant_types":["client_credentials"],"scope":"mcp:read"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("register status = %d, want %d: %v", status, http.StatusCreated, client)
+	}
+	clientID, secret := client["client_id"].(string), client["client_secret"].(string)
+
+	form := url.Values{"grant_type": {pkgoauth.GrantTypeClientCredentials}}
+	if status, body := tokenRequest(t, ts, form, clientID, "wrong"); status != http.StatusUnauthorized || body["error"] != errInvalidClient {
+		t.Errorf("wrong secret = %d %v, want 401 invalid_client", status, body)
+	}
+
+	status, tokens := tokenRequest(t, ts, form, clientID, secret)
+	if status != http.StatusOK {
+		t.Fatalf("token status = %d, want %d: %v", status, http.StatusOK, tokens)
+	}
+	if _, ok := tokens["refresh_token"]; ok {
+		t.Error("client_credentials response contains a refresh_token")
+	}
+
+	claims, err := newValidator(srv).ValidateToken(context.Background(), tokens["access_token"].(string))
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Subject != clientID || !claims.HasScope(pkgoauth.ScopeRead) {
+		t.Errorf("claims sub = %q, scopes = %v", claims.Subject, claims.Scopes)
+	}
+
+	form.Set("scope", pkgoauth.ScopeWrite)
+	if status, body := tokenRequest(t, ts, form, clientID, secret); status != http.StatusBadRequest || body["error"] != errInvalidScope {
+		t.Errorf("unregistered scope = %d %v, want 400 invalid_scope", status, body)
+	}
+}
+
+func TestServer_Authorize_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, ts := newTestServer(t)
+	_, client := register(t, ts, `{"redirect_uris":["`+testRedirect+`"],"token_endpoint_auth_method":"none"}`)
+	clientID := client["client_id"].(string)
+
+	tests := []struct {
+		name       string
+		modify     func(url.Values)
+		wantStatus int
+		wantError  string
+	}{
+		{name: "unknown client", modify: func(v url.Values) { v.Set("client_id", "unknown") }, wantStatus: http.StatusBadRequest},
+		{name: "unregistered redirect", modify: func(v url.Values) { v.Set("redirect_uri", "https://evil.example.com/cb") }, wantStatus: http.StatusBadRequest},
+		{name: "token response type", modify: func(v url.Values) { v.Set("response_type", "token") }, wantStatus: http.StatusFound, wantError: errUnsupportedResponseType},
+		{name: "missing challenge", modify: func(v url.Values) { v.Del("code_challenge") }, wantStatus: http.StatusFound, wantError: errInvalidRequest},
+		{name: "plain method", modify: func(v url.Values) { v.Set("code_challenge_method", "plain") }, wantStatus: http.StatusFound, wantError: errInvalidRequest},
+		{name: "unsupported scope", modify: func(v url.Values) { v.Set("scope", pkgoauth.ScopeAdmin) }, wantStatus: http.StatusFound, wantError: errInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := authorizeParams(clientID)
+			tt.modify(params)
+
+			status, redirect := authorize(t, ts, params)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				if redirect.Get("error") != tt.wantError || redirect.Get("state") != "xyz" {
+					t.Errorf("redirect error = %q, state = %q, want %q", redirect.Get("error"), redirect.Get("state"), tt.wantError)
+				}
+				if redirect.Get("code") != "" {
+					t.Error("error redirect contains a code")
+				}
+			}
+		})
+	}
+}
+
+func TestServer_Token_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, ts := newTestServer(t)
+	_, client := register(t, ts, `{"redirect_uris":["`+testRedirect+`"],"token_endpoint_auth_method":"none"}`)
+	clientID := client["client_id"].(string)
+
+	newCode := func() string {
+		_, redirect := authorize(t, ts, authorizeParams(clientID))
+		return redirect.Get("code")
+	}
+
+	tests := []struct {
+		name       string
+		form       func() url.Values
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name: "wrong verifier",
+			form: func() url.Values {
+				return url.Values{"grant_type": {pkgoauth.GrantTypeAuthorizationCode}, "code": {newCode()},
+					"code_verifier": {strings.Repeat("a", 43)}, "redirect_uri": {testRedirect}, "client_id": {clientID}}
+			},
+			wantStatus: http.StatusBadRequest, wantError: errInvalidGrant,
+		},
+		{
+			name: "redirect omitted",
+			form: func() url.Values {
+				return url.Values{"grant_type": {pkgoauth.GrantTypeAuthorizationCode}, "code": {newCode()},
+					"code_verifier": {testVerifier}, "client_id": {clientID}}
+			},
+			wantStatus: http.StatusBadRequest, wantError: errInvalidGrant,
+		},
+		{
+			name: "redirect mismatch",
+			form: func() url.Values {
+				return url.Values{"grant_type": {pkgoauth.GrantTypeAuthorizationCode}, "code": {newCode()},
+					"code_verifier": {testVerifier}, "redirect_uri": {"http://127.0.0.1:9000/other"}, "client_id": {clientID}}
+			},
+			wantStatus: http.StatusBadRequest, wantError: errInvalidGrant,
+		},
+		{
+			name: "unknown client",
+			form: func() url.Values {
+				return url.Values{"grant_type": {pkgoauth.GrantTypeAuthorizationCode}, "client_id": {"unknown"}}
+			},
+			wantStatus: http.StatusUnauthorized, wantError: errInvalidClient,
+		},
+		{
+			name: "grant not registered",
+			form: func() url.Values {
+				return url.Values{"grant_type": {pkgoauth.GrantTypeRefreshToken}, "refresh_token": {"x"}, "client_id": {clientID}}
+			},
+			wantStatus: http.StatusBadRequest, wantError: errUnauthorizedClient,
+		},
+		{
+			name: "unsupported grant",
+			form: func() url.Values {
+				return url.Values{"grant_type": {"password"}, "client_id": {clientID}}
+			},
+			wantStatus: http.StatusBadRequest, wantError: errUnsupportedGrantType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status, body := tokenRequest(t, ts, tt.form(), "", "")
+			if status != tt.wantStatus || body["error"] != tt.wantError {
+				t.Errorf("response = %d %v, want %d %s", status, body, tt.wantStatus, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestServer_Register_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, ts := newTestServer(t)
+
+	tests := []struct {
+		name      string
+		metadata  string
+		wantError string
+	}{
+		{name: "missing redirect URIs", metadata: `{}`, wantError: errInvalidRedirectURI},
+		{name: "plain http redirect", metadata: `{"redirect_uris":["http://example.com/cb"]}`, wantError: errInvalidRedirectURI},
+		{name: "fragment in redirect", metadata: `{"redirect_uris":["https://example.com/cb#x"]}`, wantError: errInvalidRedirectURI},
+		{name: "public client credentials", metadata: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"none"}`, wantError: errInvalidClientMetadata},
+		{name: "implicit grant", metadata: `{"grant_types":["implicit"],"redirect_uris":["https://example.com/cb"]}`, wantError: errInvalidClientMetadata},
+		{name: "unsupported scope", metadata: `{"grant_types":["client_credentials"],"scope":"mcp:admin"}`, wantError: errInvalidClientMetadata},
+		{name: "not JSON", metadata: `redirect_uris=x`, wantError: errInvalidClientMetadata},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status, body := register(t, ts, tt.metadata)
+			if status != http.StatusBadRequest || body["error"] != tt.wantError {
+				t.Errorf("response = %d %v, want 400 %s", status, body, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestServer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	srv, ts := newTestServer(t)
+
+	// Served below the issuer and at the RFC 8414 path-inserted location
+	for _, path := range []string{"/dev-as/.well-known/oauth-authorization-server", "/.well-known/oauth-authorization-server/dev-as"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		var metadata jwks.AuthorizationServerMetadata
+		err = json.NewDecoder(resp.Body).Decode(&metadata)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to decode metadata from %s: %v", path, err)
+		}
+
+		if metadata.Issuer != srv.Issuer() || metadata.JWKSURI != srv.Issuer()+"/jwks" {
+			t.Errorf("%s: issuer = %q, jwks_uri = %q", path, metadata.Issuer, metadata.JWKSURI)
+		}
+		if len(metadata.CodeChallengeMethodsSupported) != 1 || metadata.CodeChallengeMethodsSupported[0] != pkgoauth.CodeChallengeMethodS256 {
+			t.Errorf("%s: code_challenge_methods_supported = %v", path, metadata.CodeChallengeMethodsSupported)
+		}
+	}
+
+	// The aggregated view of the JWKS client reports full capabilities
+	statuses := jwks.NewClient([]string{srv.Issuer()}, time.Minute).ServerMetadata(context.Background())
+	if len(statuses) != 1 || !statuses[0].Healthy {
+		t.Fatalf("ServerMetadata() = %+v, want one healthy server", statuses)
+	}
+	caps := statuses[0].Capabilities
+	if !caps.PKCES256 || !caps.DynamicClientRegistration {
+		t.Errorf("capabilities = %+v, want PKCE S256 and DCR", caps)
+	}
+}
+
+func TestNewServer_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{name: "nil config", cfg: nil},
+		{name: "relative issuer", cfg: &Config{Issuer: "/dev-as", Audience: testAudience, User: "alice"}},
+		{name: "missing audience", cfg: &Config{Issuer: "http://localhost:8080/dev-as", User: "alice"}},
+		{name: "missing user", cfg: &Config{Issuer: "http://localhost:8080/dev-as", Audience: testAudience}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := NewServer(tt.cfg); err == nil {
+				t.Error("NewServer() expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package devserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// refreshGrant is an issued refresh token.
+type refreshGrant struct {
+	clientID  string
+	subject   string
+	scopes    []string
+	expiresAt time.Time
+}
+
+// tokenResponse is a successful token endpoint response (RFC 6749 Section 5.1).
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// handleToken implements the token endpoint for the authorization_code,
+// refresh_token and client_credentials grants.
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get(pkgoauth.HeaderContentType), pkgoauth.ContentTypeFormURLEncoded) {
+		writeError(w, http.StatusBadRequest, errInvalidRequest, "request body must be "+pkgoauth.ContentTypeFormURLEncoded)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, errInvalidRequest, "malformed request body")
+		return
+	}
+
+	c, ok := s.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	grantType := r.PostForm.Get("grant_type")
+	if !contains(supportedGrantTypes, grantType) {
+		writeError(w, http.StatusBadRequest, errUnsupportedGrantType, "unsupported grant_type")
+		return
+	}
+	if !c.allowsGrant(grantType) {
+		writeError(w, http.StatusBadRequest, errUnauthorizedClient, "client is not registered for the "+grantType+" grant")
+		return
+	}
+
+	switch grantType {
+	case pkgoauth.GrantTypeAuthorizationCode:
+		s.exchangeCode(w, c, r.PostForm)
+	case pkgoauth.GrantTypeRefreshToken:
+		s.refresh(w, c, r.PostForm)
+	case pkgoauth.GrantTypeClientCredentials:
+		s.clientCredentials(w, c, r.PostForm)
+	}
+}
+
+// authenticateClient identifies the client with its registered method:
+// HTTP Basic, form credentials, or client_id alone for public clients.
+// On failure it writes an invalid_client response and returns false.
+func (s *Server) authenticateClient(w http.ResponseWriter, r *http.Request) (*client, bool) {
+	clientID, secret, basic := r.BasicAuth()
+	if basic {
+		// RFC 6749 Section 2.3.1: credentials are form-encoded before Basic encoding
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	s.mu.Lock()
+	c, ok := s.clients[clientID]
+	s.mu.Unlock()
+
+	authenticated := ok
+	if ok {
+		switch c.authMethod {
+		case authMethodNone:
+			authenticated = !basic && secret == ""
+		case authMethodClientSecretBasic:
+			authenticated = basic && c.checkSecret(secret)
+		case authMethodClientSecretPost:
+			authenticated = !basic && c.checkSecret(secret)
+		}
+	}
+
+	if !authenticated {
+		if basic {
+			w.Header().Set(pkgoauth.HeaderWWWAuthenticate, `Basic realm="token"`)
+		}
+		writeError(w, http.StatusUnauthorized, errInvalidClient, "client authentication failed")
+		return nil, false
+	}
+	return c, true
+}
+
+// exchangeCode redeems an authorization code after checking its PKCE verifier.
+// The redirect URI must be repeated if the authorization request named it
+// (RFC 6749 Section 4.1.3), and must match in any case.
+// Codes are single use: a failed redemption consumes the code as well.
+func (s *Server) exchangeCode(w http.ResponseWriter, c *client, form url.Values) {
+	s.mu.Lock()
+	grant, ok := s.codes[form.Get("code")]
+	delete(s.codes, form.Get("code"))
+	s.mu.Unlock()
+
+	switch {
+	case !ok || s.now().After(grant.expiresAt):
+		writeError(w, http.StatusBadRequest, errInvalidGrant, "authorization code is invalid or expired")
+	case grant.clientID != c.id:
+		writeError(w, http.StatusBadRequest, errInvalidGrant, "authorization code was issued to another client")
+	case grant.redirectGiven && form.Get("redirect_uri") == "":
+		writeError(w, http.StatusBadRequest, errInvalidGrant, "redirect_uri is required as it was sent with the authorization request")
+	case form.Get("redirect_uri") != "" && form.Get("redirect_uri") != grant.redirectURI:
+		writeError(w, http.StatusBadRequest, errInvalidGrant, "redirect_uri does not match the authorization request")
+	case !verifyCodeChallenge(form.Get("code_verifier"), grant.codeChallenge):
+		writeError(w, http.StatusBadRequest, errInvalidGrant, "code_verifier does not match the code challenge")
+	default:
+		s.issueTokens(w, c, grant.subject, grant.scopes)
+	}
+}
+
+// refresh issues new tokens for a refresh token. The refresh token is
+// rotated; a narrower scope may be requested.
+func (s *Server) refresh(w http.ResponseWriter, c *client, form url.Values) {
+	s.mu.Lock()
+	grant, ok := s.refreshTokens[form.Get("refresh_token")]
+	if ok && grant.clientID == c.id {
+		delete(s.refreshTokens, form.Get("refresh_token"))
+	}
+	s.mu.Unlock()
+
+	if !ok || grant.clientID != c.id || s.now().After(grant.expiresAt) {
+		writeError(w, http.StatusBadRequest, errInvalidGrant, "refresh token is invalid or expired")
+		return
+	}
+
+	scopes := grant.scopes
+	if requested := strings.Fields(form.Get("scope")); len(requested) > 0 {
+		if !subset(requested, grant.scopes) {
+			writeError(w, http.StatusBadRequest, errInvalidScope, "requested scope exceeds the original grant")
+			return
+		}
+		scopes = requested
+	}
+
+	s.issueTokens(w, c, grant.subject, scopes)
+}
+
+// clientCredentials issues an access token to a confidential client acting
+// on its own behalf. The client ID is the subject.
+func (s *Server) clientCredentials(w http.ResponseWriter, c *client, form url.Values) {
+	scopes, ok := s.grantScopes(c, strings.Fields(form.Get("scope")))
+	if !ok {
+		writeError(w, http.StatusBadRequest, errInvalidScope, "requested scope is not supported")
+		return
+	}
+
+	accessToken, err := s.mintAccessToken(c.id, c.id, scopes)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken: accessToken,
+		TokenType:   pkgoauth.TokenTypeBearer,
+		ExpiresIn:   int64(s.accessTTL / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// issueTokens sends an access token for subject, with a refresh token if the
+// client registered the refresh_token grant.
+func (s *Server) issueTokens(w http.ResponseWriter, c *client, subject string, scopes []string) {
+	accessToken, err := s.mintAccessToken(subject, c.id, scopes)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errServerError, err.Error())
+		return
+	}
+
+	resp := tokenResponse{
+		AccessToken: accessToken,
+		TokenType:   pkgoauth.TokenTypeBearer,
+		ExpiresIn:   int64(s.accessTTL / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	if c.allowsGrant(pkgoauth.GrantTypeRefreshToken) {
+		resp.RefreshToken = randomToken()
+		s.mu.Lock()
+		s.purgeExpiredLocked()
+		s.refreshTokens[resp.RefreshToken] = &refreshGrant{
+			clientID:  c.id,
+			subject:   subject,
+			scopes:    scopes,
+			expiresAt: s.now().Add(s.refreshTTL),
+		}
+		s.mu.Unlock()
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// mintAccessToken signs a JWT access token in the format the resource
+// server's token validator expects.
+func (s *Server) mintAccessToken(subject, clientID string, scopes []string) (string, error) {
+	now := s.now()
+	claims := jwt.MapClaims{
+		"iss":       s.issuer,
+		"sub":       subject,
+		"aud":       s.audience,
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
+		"exp":       now.Add(s.accessTTL).Unix(),
+		"jti":       randomToken(),
+		"client_id": clientID,
+	}
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(s.key.Algorithm), claims)
+	token.Header["kid"] = s.key.KeyID
+	token.Header["typ"] = "at+jwt"
+
+	signed, err := token.SignedString(s.key.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, nil
+}
@@ -0,0 +1,183 @@
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// metadataRetryInterval is how long ServerMetadata waits before trying
+// again to discover a server whose metadata could not be fetched.
+const metadataRetryInterval = time.Minute
+
+// Errors reported by ServerMetadata. The underlying fetch errors may reveal
+// internal addresses, so they are only exposed through the admin status.
+const (
+	errMetadataUnavailable = "authorization server metadata could not be fetched"
+	errKeySetUnavailable   = "authorization server key set could not be fetched"
+)
+
+// discoveredMetadata is the last authorization server metadata document
+// fetched during JWKS discovery.
+type discoveredMetadata struct {
+	raw       json.RawMessage
+	parsed    AuthorizationServerMetadata
+	fetchedAt time.Time
+}
+
+// ServerMetadataStatus describes the RFC 8414 metadata of one authorization server.
+type ServerMetadataStatus struct {
+	// Issuer is the authorization server URL as configured.
+	Issuer string `json:"issuer"`
+
+	// Metadata is the metadata document exactly as served by the
+	// authorization server. It is omitted when the document is invalid.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// FetchedAt is when the metadata was fetched, nil if it never was.
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+
+	// Healthy reports that valid metadata is cached and that the most
+	// recent key set fetch for the server succeeded.
+	Healthy bool `json:"healthy"`
+
+	// Error explains why the server is unhealthy. Fetch failures are
+	// reported without their cause, which the admin JWKS status shows.
+	Error string `json:"error,omitempty"`
+
+	// Capabilities summarizes the features advertised by the metadata.
+	Capabilities *ServerCapabilities `json:"capabilities,omitempty"`
+}
+
+// ServerCapabilities summarizes the features an authorization server
+// advertises, applying the RFC 8414 defaults for omitted fields.
+type ServerCapabilities struct {
+	// PKCES256 reports that the S256 code challenge method is supported.
+	PKCES256 bool `json:"pkce_s256"`
+
+	// DynamicClientRegistration reports that a registration endpoint is advertised.
+	DynamicClientRegistration bool `json:"dynamic_client_registration"`
+
+	// TokenEndpointAuthMethods lists the client authentication methods of
+	// the token endpoint. Defaults to client_secret_basic.
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods"`
+
+	// GrantTypes lists the supported grant types. Defaults to
+	// authorization_code and implicit.
+	GrantTypes []string `json:"grant_types"`
+}
+
+// ServerMetadata returns the metadata of every configured authorization
+// server, in configuration order. The result is served from the documents
+// cached during JWKS discovery. A server whose metadata has not been
+// downloaded yet is discovered first, at most once per metadataRetryInterval
+// however often ServerMetadata is called, as callers may be anonymous.
+func (c *Client) ServerMetadata(ctx context.Context) []ServerMetadataStatus {
+	for _, serverURL := range c.serverURLs {
+		if c.claimDiscovery(serverURL) {
+			// Failures are reported below as a missing document
+			_, _ = c.discoverJWKSURI(ctx, serverURL)
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := make([]ServerMetadataStatus, 0, len(c.serverURLs))
+	for _, serverURL := range c.serverURLs {
+		status := ServerMetadataStatus{Issuer: serverURL}
+
+		discovered, ok := c.discovered[serverURL]
+		if !ok {
+			status.Error = errMetadataUnavailable
+			statuses = append(statuses, status)
+			continue
+		}
+
+		fetchedAt := discovered.fetchedAt
+		status.FetchedAt = &fetchedAt
+
+		if err := validateServerMetadata(serverURL, &discovered.parsed); err != nil {
+			status.Error = err.Error()
+			statuses = append(statuses, status)
+			continue
+		}
+
+		status.Metadata = discovered.raw
+		status.Capabilities = serverCapabilities(&discovered.parsed)
+		status.Healthy = true
+		if state, ok := c.issuers[serverURL]; ok && state.lastError != nil {
+			status.Healthy = false
+			status.Error = errKeySetUnavailable
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// claimDiscovery reports whether ServerMetadata should discover serverURL:
+// its metadata is not cached and no attempt was made within
+// metadataRetryInterval. A true result records the attempt, so concurrent
+// callers do not discover the same server.
+func (c *Client) claimDiscovery(serverURL string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.discovered[serverURL]; ok {
+		return false
+	}
+	if last, ok := c.attempts[serverURL]; ok && time.Since(last) < metadataRetryInterval {
+		return false
+	}
+	c.attempts[serverURL] = time.Now()
+	return true
+}
+
+// validateServerMetadata checks the fields RFC 8414 requires of metadata
+// that is passed on to clients.
+func validateServerMetadata(serverURL string, m *AuthorizationServerMetadata) error {
+	if m.Issuer == "" {
+		return fmt.Errorf("authorization server metadata missing issuer field")
+	}
+	if strings.TrimRight(m.Issuer, "/") != strings.TrimRight(serverURL, "/") {
+		return fmt.Errorf("authorization server metadata issuer %q does not match %q", m.Issuer, serverURL)
+	}
+	if len(m.ResponseTypesSupported) == 0 {
+		return fmt.Errorf("authorization server metadata missing response_types_supported field")
+	}
+	if m.TokenEndpoint == "" && !containsValue(m.GrantTypesSupported, "implicit") {
+		return fmt.Errorf("authorization server metadata missing token_endpoint field")
+	}
+	return nil
+}
+
+// serverCapabilities summarizes m, applying the RFC 8414 defaults.
+func serverCapabilities(m *AuthorizationServerMetadata) *ServerCapabilities {
+	caps := &ServerCapabilities{
+		PKCES256:                  containsValue(m.CodeChallengeMethodsSupported, "S256"),
+		DynamicClientRegistration: m.RegistrationEndpoint != "",
+		TokenEndpointAuthMethods:  m.TokenEndpointAuthMethodsSupported,
+		GrantTypes:                m.GrantTypesSupported,
+	}
+	if len(caps.TokenEndpointAuthMethods) == 0 {
+		caps.TokenEndpointAuthMethods = []string{"client_secret_basic"}
+	}
+	if len(caps.GrantTypes) == 0 {
+		caps.GrantTypes = []string{"authorization_code", "implicit"}
+	}
+	return caps
+}
+
+// containsValue reports whether values contains v.
+func containsValue(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newMetadataServer serves the given metadata document, with "{{URL}}"
+// replaced by the server URL, and counts metadata requests.
+func newMetadataServer(t *testing.T, status int, document string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			requests.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(strings.ReplaceAll(document, "{{URL}}", srv.URL)))
+		case "/jwks":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"keys":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestClient_ServerMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    int
+		document  string
+		wantOK    bool
+		wantError string
+		wantCaps  *ServerCapabilities
+	}{
+		{
+			name:   "full metadata",
+			status: http.StatusOK,
+			document: `{"issuer":"{{URL}}","jwks_uri":"{{URL}}/jwks",` +
+				`"authorization_endpoint":"{{URL}}/authorize","token_endpoint":"{{URL}}/token",` +
+				`"registration_endpoint":"{{URL}}/register","response_types_supported":["code"],` +
+				`"grant_types_supported":["authorization_code","refresh_token"],` +
+				`"code_challenge_methods_supported":["S256"],` +
+				`"token_endpoint_auth_methods_supported":["none","private_key_jwt"]}`,
+			wantOK: true,
+			wantCaps: &ServerCapabilities{
+				PKCES256:                  true,
+				DynamicClientRegistration: true,
+				TokenEndpointAuthMethods:  []string{"none", "private_key_jwt"},
+				GrantTypes:                []string{"authorization_code", "refresh_token"},
+			},
+		},
+		{
+			name:   "RFC 8414 defaults",
+			status: http.StatusOK,
+			document: `{"issuer":"{{URL}}","jwks_uri":"{{URL}}/jwks",` +
+				`"token_endpoint":"{{URL}}/token","response_types_supported":["code"],` +
+				`"code_challenge_methods_supported":["plain"]}`,
+			wantOK: true,
+			wantCaps: &ServerCapabilities{
+				TokenEndpointAuthMethods: []string{"client_secret_basic"},
+				GrantTypes:               []string{"authorization_code", "implicit"},
+			},
+		},
+		{
+			name:   "issuer mismatch",
+			status: http.StatusOK,
+			document: `{"issuer":"https://other.example.com","jwks_uri":"{{URL}}/jwks",` +
+				`"token_endpoint":"{{URL}}/token","response_types_supported":["code"]}`,
+			wantError: "does not match",
+		},
+		{
+			name:      "missing response types",
+			status:    http.StatusOK,
+			document:  `{"issuer":"{{URL}}","jwks_uri":"{{URL}}/jwks","token_endpoint":"{{URL}}/token"}`,
+			wantError: "response_types_supported",
+		},
+		{
+			name:      "missing token endpoint",
+			status:    http.StatusOK,
+			document:  `{"issuer":"{{URL}}","jwks_uri":"{{URL}}/jwks","response_types_supported":["code"]}`,
+			wantError: "token_endpoint",
+		},
+		{
+			name:      "unavailable",
+			status:    http.StatusServiceUnavailable,
+			document:  `{}`,
+			wantError: "could not be fetched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv, _ := newMetadataServer(t, tt.status, tt.document)
+			client := NewClient([]string{srv.URL}, 0)
+
+			statuses := client.ServerMetadata(context.Background())
+			if len(statuses) != 1 {
+				t.Fatalf("ServerMetadata() returned %d statuses, want 1", len(statuses))
+			}
+			status := statuses[0]
+
+			if status.Issuer != srv.URL {
+				t.Errorf("Issuer = %q, want %q", status.Issuer, srv.URL)
+			}
+			if status.Healthy != tt.wantOK {
+				t.Errorf("Healthy = %v, want %v (error %q)", status.Healthy, tt.wantOK, status.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(status.Error, tt.wantError) {
+				t.Errorf("Error = %q, want it to contain %q", status.Error, tt.wantError)
+			}
+
+			if !tt.wantOK {
+				if status.Metadata != nil {
+					t.Errorf("Metadata = %s, want nil for an invalid document", status.Metadata)
+				}
+				if status.Capabilities != nil {
+					t.Errorf("Capabilities = %+v, want nil for an invalid document", status.Capabilities)
+				}
+				return
+			}
+
+			if status.FetchedAt == nil {
+				t.Error("FetchedAt = nil, want fetch time")
+			}
+			want := strings.ReplaceAll(tt.document, "{{URL}}", srv.URL)
+			if string(status.Metadata) != want {
+				t.Errorf("Metadata = %s, want %s", status.Metadata, want)
+			}
+			if !reflect.DeepEqual(status.Capabilities, tt.wantCaps) {
+				t.Errorf("Capabilities = %+v, want %+v", status.Capabilities, tt.wantCaps)
+			}
+		})
+	}
+}
+
+func TestClient_ServerMetadata_ReusesDiscovery(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := newMetadataServer(t, http.StatusOK,
+		`{"issuer":"{{URL}}","jwks_uri":"{{URL}}/jwks","token_endpoint":"{{URL}}/token","response_types_supported":["code"]}`)
+	client := NewClient([]string{srv.URL}, 0)
+
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() error = %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("metadata requests after RefreshKeys() = %d, want 1", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		statuses := client.ServerMetadata(context.Background())
+		if !statuses[0].Healthy {
+			t.Fatalf("Healthy = false, error %q", statuses[0].Error)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("metadata requests = %d, want 1 (document served from cache)", got)
+	}
+}
+
+func TestClient_ServerMetadata_BacksOffFailures(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := newMetadataServer(t, http.StatusServiceUnavailable, `{}`)
+	client := NewClient([]string{srv.URL}, 0)
+
+	for i := 0; i < 3; i++ {
+		statuses := client.ServerMetadata(context.Background())
+		if statuses[0].Healthy || statuses[0].Error != errMetadataUnavailable {
+			t.Fatalf("status = %+v, want the generic unavailable error", statuses[0])
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("metadata requests = %d, want 1 within the retry interval", got)
+	}
+
+	// The next call after the interval tries again
+	client.mu.Lock()
+	client.attempts[srv.URL] = time.Now().Add(-metadataRetryInterval)
+	client.mu.Unlock()
+	client.ServerMetadata(context.Background())
+	if got := requests.Load(); got != 2 {
+		t.Errorf("metadata requests after the retry interval = %d, want 2", got)
+	}
+}
+
+func TestClient_ServerMetadata_HidesFetchErrors(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newMetadataServer(t, http.StatusOK,
+		`{"issuer":"{{URL}}","jwks_uri":"{{URL}}/internal/jwks","token_endpoint":"{{URL}}/token","response_types_supported":["code"]}`)
+	client := NewClient([]string{srv.URL}, 0)
+
+	// The key set location answers 404
+	if err := client.RefreshKeys(context.Background()); err == nil {
+		t.Fatal("RefreshKeys() error = nil, want a fetch error")
+	}
+
+	status := client.ServerMetadata(context.Background())[0]
+	if status.Healthy || status.Error != errKeySetUnavailable {
+		t.Errorf("status = %+v, want the generic key set error", status)
+	}
+	if strings.Contains(status.Error, "/internal/jwks") {
+		t.Errorf("Error = %q exposes the fetch error", status.Error)
+	}
+}
+
+func TestServerMetadataStatus_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	status := ServerMetadataStatus{
+		Issuer:   "https://auth.example.com",
+		Metadata: json.RawMessage(`{"issuer":"https://auth.example.com"}`),
+		Healthy:  true,
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `{"issuer":"https://auth.example.com","metadata":{"issuer":"https://auth.example.com"},"healthy":true}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
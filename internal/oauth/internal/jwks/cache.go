@@ -9,6 +9,7 @@ import (
 type cacheEntry struct {
 	key       any
 	expiresAt time.Time
+	stale     bool // restored from a snapshot rather than freshly fetched
 }
 
 // Cache provides an in-memory cache for JWKS keys with TTL.
@@ -57,6 +58,29 @@ func (c *Cache) Set(keyID string, key any) {
 	}
 }
 
+// SetStale stores a key restored from a snapshot. The key remains usable
+// until expiresAt or until it is replaced by a fresh Set.
+func (c *Cache) SetStale(keyID string, key any, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[keyID] = &cacheEntry{
+		key:       key,
+		expiresAt: expiresAt,
+		stale:     true,
+	}
+}
+
+// IsStale reports whether a cached key was restored from a snapshot
+// and has not yet been refreshed.
+func (c *Cache) IsStale(keyID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[keyID]
+	return ok && entry.stale
+}
+
 // Delete removes a key from the cache.
 func (c *Cache) Delete(keyID string) {
 	c.mu.Lock()
@@ -95,3 +119,67 @@ func (c *Cache) Size() int {
 
 	return len(c.entries)
 }
+
+// IssuerCache partitions cached keys by authorization server so that the keys
+// of one server can be replaced without affecting the others.
+// It is safe for concurrent use by multiple goroutines.
+type IssuerCache struct {
+	mu         sync.RWMutex
+	order      []string
+	partitions map[string]*Cache
+}
+
+// NewIssuerCache creates an empty cache with one partition slot per server.
+// Lookups search partitions in the order of serverURLs.
+func NewIssuerCache(serverURLs []string) *IssuerCache {
+	return &IssuerCache{
+		order:      serverURLs,
+		partitions: make(map[string]*Cache, len(serverURLs)),
+	}
+}
+
+// Get retrieves a key by key ID from the first partition that holds it.
+// Returns nil if no partition holds an unexpired key with that ID.
+func (p *IssuerCache) Get(keyID string) any {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, serverURL := range p.order {
+		if partition, ok := p.partitions[serverURL]; ok {
+			if key := partition.Get(keyID); key != nil {
+				return key
+			}
+		}
+	}
+	return nil
+}
+
+// IsStale reports whether the key returned by Get for keyID was restored
+// from a snapshot and has not yet been refreshed.
+func (p *IssuerCache) IsStale(keyID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, serverURL := range p.order {
+		if partition, ok := p.partitions[serverURL]; ok && partition.Get(keyID) != nil {
+			return partition.IsStale(keyID)
+		}
+	}
+	return false
+}
+
+// Replace atomically swaps the partition of serverURL for a new one.
+func (p *IssuerCache) Replace(serverURL string, partition *Cache) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.partitions[serverURL] = partition
+}
+
+// Clear removes the keys of every server.
+func (p *IssuerCache) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.partitions = make(map[string]*Cache, len(p.order))
+}
@@ -514,6 +514,47 @@ func TestCache_Cleanup_NoneExpired(t *testing.T) {
 }
 
 // Benchmark tests for cache operations
+func TestIssuerCache_Partitions(t *testing.T) {
+	t.Parallel()
+
+	cache := NewIssuerCache([]string{"https://a.example.com", "https://b.example.com"})
+
+	partitionA := NewCache(time.Minute)
+	partitionA.Set("shared", "a-shared")
+	partitionA.Set("a-only", "a-only")
+	cache.Replace("https://a.example.com", partitionA)
+
+	partitionB := NewCache(time.Minute)
+	partitionB.Set("shared", "b-shared")
+	partitionB.SetStale("b-only", "b-only", time.Now().Add(time.Minute))
+	cache.Replace("https://b.example.com", partitionB)
+
+	// Lookups search partitions in server order
+	if got := cache.Get("shared"); got != "a-shared" {
+		t.Errorf("Get(shared) = %v, want a-shared", got)
+	}
+	if got := cache.Get("b-only"); got != "b-only" {
+		t.Errorf("Get(b-only) = %v, want b-only", got)
+	}
+	if !cache.IsStale("b-only") || cache.IsStale("a-only") {
+		t.Error("IsStale() should follow the partition holding the key")
+	}
+
+	// Replacing one partition leaves the other untouched
+	cache.Replace("https://a.example.com", NewCache(time.Minute))
+	if got := cache.Get("a-only"); got != nil {
+		t.Errorf("Get(a-only) after replace = %v, want nil", got)
+	}
+	if got := cache.Get("shared"); got != "b-shared" {
+		t.Errorf("Get(shared) after replace = %v, want b-shared", got)
+	}
+
+	cache.Clear()
+	if got := cache.Get("b-only"); got != nil {
+		t.Errorf("Get(b-only) after Clear() = %v, want nil", got)
+	}
+}
+
 func BenchmarkCache_Get(b *testing.B) {
 	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
 	cache := NewCache(1 * time.Hour)
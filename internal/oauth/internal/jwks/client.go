@@ -5,8 +5,10 @@ import (
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"io/fs"
+	"log/slog"
 	"math/big"
 	"net/http"
 	"sync"
@@ -15,10 +17,19 @@ import (
 	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/oautherr"
 )
 
-// AuthorizationServerMetadata represents the minimal AS metadata needed for JWKS discovery.
+// AuthorizationServerMetadata represents the RFC 8414 AS metadata fields used
+// for JWKS discovery and capability reporting.
 type AuthorizationServerMetadata struct {
-	Issuer  string `json:"issuer"`
-	JWKSURI string `json:"jwks_uri"`
+	Issuer                            string   `json:"issuer"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                     string   `json:"token_endpoint,omitempty"`
+	RegistrationEndpoint              string   `json:"registration_endpoint,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported,omitempty"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
 }
 
 // JWKS represents a JSON Web Key Set.
@@ -39,29 +50,111 @@ type JWK struct {
 	Curve string `json:"crv,omitempty"` // curve name
 	X     string `json:"x,omitempty"`   // x coordinate
 	Y     string `json:"y,omitempty"`   // y coordinate
+	// X.509 certificate chain parameters
+	X5C     []string `json:"x5c,omitempty"`      // base64 DER certificates, leaf first
+	X5T     string   `json:"x5t,omitempty"`      // SHA-1 thumbprint of the leaf
+	X5TS256 string   `json:"x5t#S256,omitempty"` // SHA-256 thumbprint of the leaf
 }
 
 // Client fetches and caches JWKS from authorization servers.
 type Client struct {
-	httpClient   *http.Client
-	cache        *Cache
+	fetcher      *fetcher            // default fetcher for URLs outside serverURLs
+	fetchers     map[string]*fetcher // per authorization server
+	cache        *IssuerCache        // partitioned by authorization server
 	serverURLs   []string
 	cacheTTL     time.Duration
 	mu           sync.RWMutex
 	jwksURICache map[string]string // maps issuer to JWKS URI
+	x5cPolicy    *X5CPolicy
+
+	issuers     map[string]*issuerState        // per-server fetch state, guarded by mu
+	discovered  map[string]*discoveredMetadata // per-server AS metadata, guarded by mu
+	attempts    map[string]time.Time           // per-server ServerMetadata discovery, guarded by mu
+	persistence *PersistenceConfig
+	persistMu   sync.Mutex // serializes snapshot writes
 }
 
+// ClientConfig holds the settings for a JWKS client.
+type ClientConfig struct {
+	// ServerURLs is the list of authorization server URLs to fetch keys from.
+	ServerURLs []string
+
+	// CacheTTL is how long fetched keys are cached.
+	CacheTTL time.Duration
+
+	// X5C controls x5c certificate chain validation (optional).
+	// Keys carrying a chain are always checked against their key parameters.
+	X5C *X5CPolicy
+
+	// HTTP configures the outbound HTTP client (optional).
+	// A nil value selects hardened defaults.
+	HTTP *HTTPConfig
+
+	// ServerHTTP overrides HTTP for individual authorization servers,
+	// keyed by server URL (optional).
+	ServerHTTP map[string]*HTTPConfig
+
+	// Persistence snapshots the last good key sets to disk (optional).
+	Persistence *PersistenceConfig
+}
+
+// DefaultMaxStale is how long persisted keys remain usable when
+// PersistenceConfig.MaxStale is not set.
+const DefaultMaxStale = 24 * time.Hour
+
 // NewClient creates a new JWKS client.
 func NewClient(serverURLs []string, cacheTTL time.Duration) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		cache:        NewCache(cacheTTL),
-		serverURLs:   serverURLs,
-		cacheTTL:     cacheTTL,
+	return NewClientWithConfig(&ClientConfig{
+		ServerURLs: serverURLs,
+		CacheTTL:   cacheTTL,
+	})
+}
+
+// NewClientWithConfig creates a new JWKS client from the provided configuration.
+//
+// Each authorization server gets its own HTTP client built from ServerHTTP, or
+// HTTP if it has no override. Servers configured on a loopback address may
+// reach private networks, since config validation only permits plain HTTP for
+// localhost development servers.
+//
+// When persistence is enabled, key sets from an existing snapshot are loaded
+// as stale-but-usable cache entries and refreshed in the background.
+func NewClientWithConfig(cfg *ClientConfig) *Client {
+	fetchers := make(map[string]*fetcher, len(cfg.ServerURLs))
+	for _, serverURL := range cfg.ServerURLs {
+		httpCfg := cfg.HTTP
+		if override, ok := cfg.ServerHTTP[serverURL]; ok {
+			httpCfg = override
+		}
+		fetchers[serverURL] = newFetcher(httpCfg, isLoopbackURL(serverURL))
+	}
+
+	c := &Client{
+		fetcher:      newFetcher(cfg.HTTP, false),
+		fetchers:     fetchers,
+		cache:        NewIssuerCache(cfg.ServerURLs),
+		serverURLs:   cfg.ServerURLs,
+		cacheTTL:     cfg.CacheTTL,
 		jwksURICache: make(map[string]string),
+		x5cPolicy:    cfg.X5C,
+		issuers:      make(map[string]*issuerState),
+		discovered:   make(map[string]*discoveredMetadata),
+		attempts:     make(map[string]time.Time),
 	}
+
+	if cfg.Persistence != nil && cfg.Persistence.Path != "" {
+		persistence := *cfg.Persistence
+		if persistence.MaxStale <= 0 {
+			persistence.MaxStale = DefaultMaxStale
+		}
+		c.persistence = &persistence
+
+		if restored := c.restoreSnapshot(); len(restored) > 0 {
+			go c.refreshRestored(restored)
+		}
+	}
+
+	return c
 }
 
 // GetKey retrieves a public key for the given key ID.
@@ -100,88 +193,216 @@ func (c *Client) GetKey(ctx context.Context, keyID string) (any, error) {
 }
 
 // RefreshKeys forces a refresh of the JWKS cache from all configured authorization servers.
+//
+// Each server's JWKS URI is rediscovered and its cached keys are replaced only
+// if its new key set is fetched successfully, so an unreachable server keeps
+// serving its last good keys. Failures are reported per server in a *RefreshError.
 func (c *Client) RefreshKeys(ctx context.Context) error {
-	c.cache.Clear()
-	c.mu.Lock()
-	c.jwksURICache = make(map[string]string)
-	c.mu.Unlock()
-
-	var lastErr error
+	errs := make(map[string]error)
 	for _, serverURL := range c.serverURLs {
-		if err := c.refreshFromServer(ctx, serverURL); err != nil {
-			lastErr = err
+		if _, err := c.fetchKeySet(ctx, serverURL, true); err != nil {
 			// Continue to try other servers
-			continue
+			errs[serverURL] = err
 		}
 	}
 
-	if lastErr != nil {
-		return lastErr
+	if len(errs) > 0 {
+		return &RefreshError{order: c.serverURLs, Errors: errs}
 	}
 	return nil
 }
 
-// fetchAndCacheKey fetches JWKS from a server and caches the specified key.
+// fetchAndCacheKey fetches JWKS from a server and caches its keys,
+// returning the key with the specified ID if the server publishes it.
 func (c *Client) fetchAndCacheKey(ctx context.Context, serverURL, keyID string) (any, error) {
-	jwksURI, err := c.getJWKSURI(ctx, serverURL)
+	partition, err := c.fetchKeySet(ctx, serverURL, false)
 	if err != nil {
 		return nil, err
 	}
 
-	jwks, err := c.fetchJWKS(ctx, jwksURI)
+	return partition.Get(keyID), nil
+}
+
+// refreshFromServer refreshes all keys from a specific server.
+func (c *Client) refreshFromServer(ctx context.Context, serverURL string) error {
+	_, err := c.fetchKeySet(ctx, serverURL, false)
+	return err
+}
+
+// fetchKeySet fetches the key set of serverURL and, on success, atomically
+// replaces its cache partition with the keys that pass validation. The JWKS
+// URI is rediscovered when rediscover is set, otherwise the cached one is used.
+// The outcome is recorded for status reporting and persistence.
+func (c *Client) fetchKeySet(ctx context.Context, serverURL string, rediscover bool) (*Cache, error) {
+	var jwksURI string
+	var err error
+	if rediscover {
+		jwksURI, err = c.discoverJWKSURI(ctx, serverURL)
+	} else {
+		jwksURI, err = c.getJWKSURI(ctx, serverURL)
+	}
+	if err != nil {
+		c.recordError(serverURL, err)
+		return nil, err
+	}
+
+	jwks, err := c.fetchJWKS(ctx, serverURL, jwksURI)
 	if err != nil {
+		c.recordError(serverURL, err)
 		return nil, err
 	}
 
-	// Cache all keys from the JWKS
+	partition := NewCache(c.cacheTTL)
 	for _, jwk := range jwks.Keys {
 		if jwk.KeyID == "" {
 			continue
 		}
-		key, err := c.jwkToPublicKey(&jwk)
+		key, err := c.parseKey(&jwk)
 		if err != nil {
 			// Skip invalid keys
 			continue
 		}
-		c.cache.Set(jwk.KeyID, key)
+		partition.Set(jwk.KeyID, key)
+	}
 
-		// Return immediately if this is the key we're looking for
-		if jwk.KeyID == keyID {
-			return key, nil
+	c.cache.Replace(serverURL, partition)
+	c.recordKeySet(serverURL, jwksURI, jwks)
+
+	return partition, nil
+}
+
+// recordKeySet remembers the last good key set fetched from serverURL and,
+// when persistence is enabled, writes all recorded key sets to disk.
+//
+// Fetches for unknown key IDs can be triggered from outside, so the snapshot
+// is only rewritten when the key set differs from the one on disk, or when
+// the one on disk was fetched a cache TTL ago or more: this keeps its fetch
+// time, from which MaxStale is measured, close to the last successful fetch
+// while bounding writes to one per cache TTL for an unchanged key set.
+func (c *Client) recordKeySet(serverURL, jwksURI string, set *JWKS) {
+	c.persistMu.Lock()
+	defer c.persistMu.Unlock()
+
+	now := time.Now()
+
+	c.mu.Lock()
+	state := c.issuerStateLocked(serverURL)
+	state.keySet = &KeySetSnapshot{
+		JWKSURI:   jwksURI,
+		FetchedAt: now,
+		Keys:      *set,
+	}
+	state.expiresAt = now.Add(c.cacheTTL)
+	state.stale = false
+	state.lastError = nil
+	state.lastErrorAt = time.Time{}
+
+	if c.persistence == nil ||
+		(state.keySet.digest() == state.persisted && now.Sub(state.persistedAt) < c.cacheTTL) {
+		c.mu.Unlock()
+		return
+	}
+
+	snapshot := &Snapshot{Issuers: make(map[string]*KeySetSnapshot, len(c.issuers))}
+	for issuer, s := range c.issuers {
+		if s.keySet != nil {
+			snapshot.Issuers[issuer] = s.keySet
 		}
 	}
+	c.mu.Unlock()
 
-	return nil, nil
-}
+	if err := WriteSnapshot(c.persistence.Path, c.persistence.Key, snapshot); err != nil {
+		slog.Warn("failed to persist JWKS snapshot", "path", c.persistence.Path, "error", err)
+		return
+	}
 
-// refreshFromServer refreshes all keys from a specific server.
-func (c *Client) refreshFromServer(ctx context.Context, serverURL string) error {
-	jwksURI, err := c.getJWKSURI(ctx, serverURL)
-	if err != nil {
-		return err
+	c.mu.Lock()
+	for issuer, keySet := range snapshot.Issuers {
+		c.markPersistedLocked(issuer, keySet)
 	}
+	c.mu.Unlock()
+}
 
-	jwks, err := c.fetchJWKS(ctx, jwksURI)
+// markPersistedLocked records keySet as the key set of issuer on disk.
+// c.mu must be held.
+func (c *Client) markPersistedLocked(issuer string, keySet *KeySetSnapshot) {
+	state := c.issuerStateLocked(issuer)
+	state.persisted = keySet.digest()
+	state.persistedAt = keySet.FetchedAt
+}
+
+// restoreSnapshot loads persisted key sets into the cache as stale entries.
+// Key sets older than MaxStale and servers that are no longer configured are
+// ignored. Returns the servers whose key sets were restored.
+func (c *Client) restoreSnapshot() []string {
+	snapshot, err := ReadSnapshot(c.persistence.Path, c.persistence.Key)
 	if err != nil {
-		return err
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("ignoring JWKS snapshot", "path", c.persistence.Path, "error", err)
+		}
+		return nil
 	}
 
-	// Cache all keys
-	for _, jwk := range jwks.Keys {
-		if jwk.KeyID == "" {
+	now := time.Now()
+	var restored []string
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, serverURL := range c.serverURLs {
+		keySet, ok := snapshot.Issuers[serverURL]
+		if !ok || keySet == nil {
 			continue
 		}
-		key, err := c.jwkToPublicKey(&jwk)
-		if err != nil {
+
+		expiresAt := keySet.FetchedAt.Add(c.persistence.MaxStale)
+		if !now.Before(expiresAt) {
 			continue
 		}
-		c.cache.Set(jwk.KeyID, key)
+
+		partition := NewCache(c.cacheTTL)
+		for _, jwk := range keySet.Keys.Keys {
+			if jwk.KeyID == "" {
+				continue
+			}
+			// Persisted keys pass the same checks as freshly fetched ones
+			key, err := c.parseKey(&jwk)
+			if err != nil {
+				continue
+			}
+			partition.SetStale(jwk.KeyID, key, expiresAt)
+		}
+		c.cache.Replace(serverURL, partition)
+
+		state := c.issuerStateLocked(serverURL)
+		state.keySet = keySet
+		state.expiresAt = expiresAt
+		state.stale = true
+		c.markPersistedLocked(serverURL, keySet)
+		if keySet.JWKSURI != "" {
+			c.jwksURICache[serverURL] = keySet.JWKSURI
+		}
+		restored = append(restored, serverURL)
 	}
 
-	return nil
+	return restored
 }
 
-// getJWKSURI retrieves the JWKS URI from authorization server metadata.
+// refreshRestored replaces restored stale key sets with freshly fetched ones.
+// Failures are logged and leave the stale keys in place.
+func (c *Client) refreshRestored(serverURLs []string) {
+	for _, serverURL := range serverURLs {
+		if err := c.refreshFromServer(context.Background(), serverURL); err != nil {
+			slog.Warn("background JWKS refresh failed, using persisted keys",
+				"authorization_server", serverURL,
+				"error", err,
+			)
+		}
+	}
+}
+
+// getJWKSURI retrieves the JWKS URI from authorization server metadata,
+// using the cached value if the server has already been discovered.
 func (c *Client) getJWKSURI(ctx context.Context, serverURL string) (string, error) {
 	// Check cache first
 	c.mu.RLock()
@@ -191,27 +412,22 @@ func (c *Client) getJWKSURI(ctx context.Context, serverURL string) (string, erro
 		return cached, nil
 	}
 
+	return c.discoverJWKSURI(ctx, serverURL)
+}
+
+// discoverJWKSURI fetches the authorization server metadata and caches its
+// JWKS URI. On failure the previously cached URI, if any, is kept.
+func (c *Client) discoverJWKSURI(ctx context.Context, serverURL string) (string, error) {
 	// Fetch metadata
 	metadataURL := serverURL + "/.well-known/oauth-authorization-server"
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
-	if err != nil {
-		return "", oautherr.NewInvalidMetadataError("getJWKSURI", serverURL, err)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	status, body, err := c.fetcherFor(serverURL).get(ctx, metadataURL)
 	if err != nil {
 		return "", oautherr.NewJWKSFetchError("getJWKSURI", serverURL, err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
+	if status != http.StatusOK {
 		return "", oautherr.NewJWKSFetchError("getJWKSURI", serverURL,
-			fmt.Errorf("metadata endpoint returned status %d", resp.StatusCode))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", oautherr.NewJWKSFetchError("getJWKSURI", serverURL, err)
+			fmt.Errorf("metadata endpoint returned status %d", status))
 	}
 
 	var metadata AuthorizationServerMetadata
@@ -224,43 +440,60 @@ func (c *Client) getJWKSURI(ctx context.Context, serverURL string) (string, erro
 			fmt.Errorf("authorization server metadata missing jwks_uri field"))
 	}
 
-	// Cache the JWKS URI
+	// Cache the JWKS URI and the metadata document
 	c.mu.Lock()
 	c.jwksURICache[serverURL] = metadata.JWKSURI
+	c.discovered[serverURL] = &discoveredMetadata{
+		raw:       json.RawMessage(body),
+		parsed:    metadata,
+		fetchedAt: time.Now(),
+	}
 	c.mu.Unlock()
 
 	return metadata.JWKSURI, nil
 }
 
-// fetchJWKS fetches the JWKS from the given URI.
-func (c *Client) fetchJWKS(ctx context.Context, jwksURI string) (*JWKS, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+// fetchJWKS fetches the JWKS from the given URI using the fetcher for serverURL.
+func (c *Client) fetchJWKS(ctx context.Context, serverURL, jwksURI string) (*JWKS, error) {
+	status, body, err := c.fetcherFor(serverURL).get(ctx, jwksURI)
 	if err != nil {
 		return nil, oautherr.NewJWKSFetchError("fetchJWKS", jwksURI, err)
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	if status != http.StatusOK {
+		return nil, oautherr.NewJWKSFetchError("fetchJWKS", jwksURI,
+			fmt.Errorf("jwks endpoint returned status %d", status))
+	}
+
+	var jwks JWKS
+	if err := json.Unmarshal(body, &jwks); err != nil {
 		return nil, oautherr.NewJWKSFetchError("fetchJWKS", jwksURI, err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, oautherr.NewJWKSFetchError("fetchJWKS", jwksURI,
-			fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode))
+	return &jwks, nil
+}
+
+// fetcherFor returns the HTTP fetcher configured for an authorization server.
+func (c *Client) fetcherFor(serverURL string) *fetcher {
+	if f, ok := c.fetchers[serverURL]; ok {
+		return f
 	}
+	return c.fetcher
+}
 
-	body, err := io.ReadAll(resp.Body)
+// parseKey converts a JWK to a public key and validates its x5c chain, if any.
+// Keys that fail either step must not be trusted.
+func (c *Client) parseKey(jwk *JWK) (any, error) {
+	key, err := c.jwkToPublicKey(jwk)
 	if err != nil {
-		return nil, oautherr.NewJWKSFetchError("fetchJWKS", jwksURI, err)
+		return nil, err
 	}
 
-	var jwks JWKS
-	if err := json.Unmarshal(body, &jwks); err != nil {
-		return nil, oautherr.NewJWKSFetchError("fetchJWKS", jwksURI, err)
+	if err := validateX5C(jwk, key, c.x5cPolicy, time.Now()); err != nil {
+		return nil, err
 	}
 
-	return &jwks, nil
+	return key, nil
 }
 
 // jwkToPublicKey converts a JWK to a public key interface.
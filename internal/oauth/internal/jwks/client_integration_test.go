@@ -555,8 +555,14 @@ func TestClient_NewClient(t *testing.T) {
 		t.Error("client.cache should not be nil")
 	}
 
-	if client.httpClient == nil {
-		t.Error("client.httpClient should not be nil")
+	if client.fetcher == nil {
+		t.Error("client.fetcher should not be nil")
+	}
+
+	for _, serverURL := range serverURLs {
+		if client.fetchers[serverURL] == nil {
+			t.Errorf("client.fetchers[%q] should not be nil", serverURL)
+		}
 	}
 }
 
@@ -0,0 +1,320 @@
+package jwks
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Defaults applied to zero-valued HTTPConfig fields.
+const (
+	// DefaultHTTPTimeout bounds each discovery or JWKS request.
+	DefaultHTTPTimeout = 10 * time.Second
+
+	// DefaultMaxBodyBytes caps the size of metadata and JWKS responses.
+	DefaultMaxBodyBytes int64 = 1 << 20
+
+	// DefaultUserAgent identifies this server to authorization servers.
+	DefaultUserAgent = "mcp-oauth-2.1/1.0 (+jwks)"
+)
+
+// ErrPrivateAddress indicates an outbound request targeted a private,
+// loopback or link-local address while private networks are not allowed.
+var ErrPrivateAddress = errors.New("destination address is in a private network range")
+
+// HTTPConfig controls the outbound HTTP client used for authorization server
+// discovery and JWKS fetches. Zero values select hardened defaults.
+type HTTPConfig struct {
+	// RootCAs is the trust store for TLS connections. When nil, the system pool is used.
+	RootCAs *x509.CertPool
+
+	// Certificates are presented as TLS client certificates (mutual TLS).
+	Certificates []tls.Certificate
+
+	// Proxy is the proxy to use. When nil, the HTTP_PROXY/HTTPS_PROXY
+	// environment variables are honoured unless DisableProxy is set.
+	// Destinations reached through a proxy are subject to the same private
+	// network policy as direct connections.
+	Proxy *url.URL
+
+	// DisableProxy connects directly, ignoring proxy environment variables.
+	DisableProxy bool
+
+	// Timeout bounds each request, including reading the body.
+	Timeout time.Duration
+
+	// MaxBodyBytes caps the size of a response body.
+	MaxBodyBytes int64
+
+	// MaxRedirects is the number of redirects to follow. Zero disables redirects.
+	MaxRedirects int
+
+	// AllowPrivateNetworks permits connections to private, loopback and
+	// link-local addresses. When false, such connections are refused to
+	// protect against SSRF through attacker-influenced jwks_uri values.
+	AllowPrivateNetworks bool
+
+	// UserAgent is sent with every request.
+	UserAgent string
+}
+
+// fetcher performs size-limited GET requests with a hardened http.Client.
+type fetcher struct {
+	client       *http.Client
+	maxBodyBytes int64
+	userAgent    string
+}
+
+// newFetcher builds a fetcher from cfg. A nil cfg selects the defaults.
+// allowPrivate additionally permits private destinations, which is used for
+// authorization servers that are themselves configured on a loopback address.
+func newFetcher(cfg *HTTPConfig, allowPrivate bool) *fetcher {
+	if cfg == nil {
+		cfg = &HTTPConfig{}
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	maxBody := cfg.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBodyBytes
+	}
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	guard := &dialGuard{
+		allowPrivate: allowPrivate || cfg.AllowPrivateNetworks,
+		proxyAddrs:   make(map[string]bool),
+	}
+
+	var proxy func(*http.Request) (*url.URL, error)
+	if cfg.Proxy != nil {
+		proxy = http.ProxyURL(cfg.Proxy)
+		guard.proxyAddrs[proxyAddr(cfg.Proxy)] = true
+	} else if !cfg.DisableProxy {
+		proxy = http.ProxyFromEnvironment
+		for _, envProxy := range environmentProxies() {
+			guard.proxyAddrs[proxyAddr(envProxy)] = true
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:       guard.checkProxy(proxy),
+		DialContext: guard.dialContext,
+		TLSClientConfig: &tls.Config{
+			RootCAs:      cfg.RootCAs,
+			Certificates: cfg.Certificates,
+			MinVersion:   tls.VersionTLS12,
+		},
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	return &fetcher{
+		client: &http.Client{
+			Transport:     transport,
+			Timeout:       timeout,
+			CheckRedirect: redirectPolicy(cfg.MaxRedirects),
+		},
+		maxBodyBytes: maxBody,
+		userAgent:    userAgent,
+	}
+}
+
+// get fetches rawURL and returns the response status code and a size-limited body.
+func (f *fetcher) get(ctx context.Context, rawURL string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil, nil
+	}
+
+	// Read one byte past the limit to detect oversized bodies
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBodyBytes+1))
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if int64(len(body)) > f.maxBodyBytes {
+		return resp.StatusCode, nil, fmt.Errorf("response body exceeds %d bytes", f.maxBodyBytes)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// redirectPolicy returns a CheckRedirect function that follows at most maxRedirects
+// redirects and never downgrades from HTTPS to HTTP.
+func redirectPolicy(maxRedirects int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			if maxRedirects == 0 {
+				return fmt.Errorf("redirects are not allowed (to %s)", req.URL.Redacted())
+			}
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing redirect from https to %s", req.URL.Scheme)
+		}
+		return nil
+	}
+}
+
+// dialGuard refuses connections to private addresses unless allowed.
+// The check runs on the resolved IP at dial time so DNS rebinding cannot
+// bypass it. Connections to the configured proxies are exempt because the
+// proxy, not this process, connects to the final destination; the
+// destination is checked before the request is handed to the proxy instead.
+type dialGuard struct {
+	allowPrivate bool
+	proxyAddrs   map[string]bool // host:port of the configured proxies
+}
+
+// checkProxy wraps a proxy function to refuse private destinations before
+// any proxy is chosen. The destination is resolved here, as the proxy
+// resolves it out of reach of the dial check. Requests the proxy function
+// sends direct are checked too, so that a destination sharing a proxy's
+// address cannot inherit its exemption.
+func (g *dialGuard) checkProxy(proxy func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	if proxy == nil || g.allowPrivate {
+		return proxy
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if err := checkDestination(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return proxy(req)
+	}
+}
+
+// dialContext dials addr, enforcing the private network policy for non-proxy addresses.
+func (g *dialGuard) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+
+	if !g.allowPrivate && !g.proxyAddrs[addr] {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			ipStr, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(ipStr); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, ipStr)
+			}
+			return nil
+		}
+	}
+
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// checkDestination resolves host and refuses it if any of its addresses is private.
+func checkDestination(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateIP(ip) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if isPrivateIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// environmentProxies returns the proxies named by the environment variables
+// http.ProxyFromEnvironment reads. Values without a scheme are taken as
+// http proxies, as that function does.
+func environmentProxies() []*url.URL {
+	var proxies []*url.URL
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		proxy, err := url.Parse(value)
+		if err != nil || proxy.Host == "" {
+			if proxy, err = url.Parse("http://" + value); err != nil {
+				continue
+			}
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// proxyAddr returns the host:port dialled to reach proxy.
+func proxyAddr(proxy *url.URL) string {
+	port := proxy.Port()
+	if port == "" {
+		switch proxy.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+// cgnatRange is the shared address space from RFC 6598.
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPrivateIP reports whether ip is loopback, private, link-local, unspecified
+// or otherwise not publicly routable.
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified() ||
+		cgnatRange.Contains(ip)
+}
+
+// isLoopbackURL reports whether rawURL points at localhost or a loopback IP.
+func isLoopbackURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,253 @@
+package jwks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetcher_RefusesPrivateAddresses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	f := newFetcher(&HTTPConfig{DisableProxy: true}, false)
+	_, _, err := f.get(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("get() expected error for loopback destination, got nil")
+	}
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("get() error = %v, want ErrPrivateAddress", err)
+	}
+
+	allowed := newFetcher(&HTTPConfig{DisableProxy: true, AllowPrivateNetworks: true}, false)
+	status, _, err := allowed.get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("get() with AllowPrivateNetworks unexpected error: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Errorf("get() status = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestFetcher_ProxyRefusesPrivateDestinations(t *testing.T) {
+	t.Parallel()
+
+	// The proxy listens on loopback and answers every request itself
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatalf("failed to parse proxy URL: %v", err)
+	}
+	f := newFetcher(&HTTPConfig{Proxy: proxyURL}, false)
+
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:9/", "http://[::1]/", "http://localhost/"} {
+		if _, _, err := f.get(context.Background(), target); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("get(%q) through proxy error = %v, want ErrPrivateAddress", target, err)
+		}
+	}
+	if len(proxied) != 0 {
+		t.Errorf("proxy received %v, want no requests", proxied)
+	}
+
+	// Public destinations are still reached through the proxy
+	status, _, err := f.get(context.Background(), "http://203.0.113.10/jwks.json")
+	if err != nil {
+		t.Fatalf("get() through proxy unexpected error: %v", err)
+	}
+	if status != http.StatusOK || len(proxied) != 1 {
+		t.Errorf("get() through proxy status = %d, proxied = %v", status, proxied)
+	}
+}
+
+func TestFetcher_MaxBodyBytes(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "body within limit", limit: 64, wantErr: false},
+		{name: "body exceeds limit", limit: 63, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFetcher(&HTTPConfig{MaxBodyBytes: tt.limit, DisableProxy: true}, true)
+			_, body, err := f.get(context.Background(), server.URL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("get() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("get() unexpected error: %v", err)
+			}
+			if int64(len(body)) != tt.limit {
+				t.Errorf("get() body length = %d, want %d", len(body), tt.limit)
+			}
+		})
+	}
+}
+
+func TestFetcher_RedirectPolicy(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/end", http.StatusFound)
+	})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	noRedirects := newFetcher(&HTTPConfig{DisableProxy: true}, true)
+	if _, _, err := noRedirects.get(context.Background(), server.URL+"/start"); err == nil {
+		t.Error("get() with redirects disabled expected error, got nil")
+	}
+
+	oneRedirect := newFetcher(&HTTPConfig{DisableProxy: true, MaxRedirects: 1}, true)
+	status, _, err := oneRedirect.get(context.Background(), server.URL+"/start")
+	if err != nil {
+		t.Fatalf("get() with MaxRedirects=1 unexpected error: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Errorf("get() status = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestFetcher_UserAgentAndTimeout(t *testing.T) {
+	t.Parallel()
+
+	gotUA := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA <- r.Header.Get("User-Agent")
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	f := newFetcher(&HTTPConfig{DisableProxy: true}, true)
+	if _, _, err := f.get(context.Background(), server.URL); err != nil {
+		t.Fatalf("get() unexpected error: %v", err)
+	}
+	if ua := <-gotUA; ua != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", ua, DefaultUserAgent)
+	}
+
+	custom := newFetcher(&HTTPConfig{DisableProxy: true, UserAgent: "corp-mcp/2.0", Timeout: 50 * time.Millisecond}, true)
+	if _, _, err := custom.get(context.Background(), server.URL+"/slow"); err == nil {
+		t.Error("get() expected timeout error, got nil")
+	}
+	if ua := <-gotUA; ua != "corp-mcp/2.0" {
+		t.Errorf("User-Agent = %q, want %q", ua, "corp-mcp/2.0")
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fc00::1", true},
+		{"8.8.8.8", false},
+		{"2001:4860:4860::8888", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isPrivateIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("isPrivateIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:9000", true},
+		{"http://127.0.0.1:8080", true},
+		{"http://[::1]:8080", true},
+		{"https://auth.example.com", false},
+		{"https://10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopbackURL(tt.url); got != tt.want {
+			t.Errorf("isLoopbackURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestClient_ServerHTTPOverride(t *testing.T) {
+	t.Parallel()
+
+	client := NewClientWithConfig(&ClientConfig{
+		ServerURLs: []string{"https://a.example.com", "https://b.example.com"},
+		CacheTTL:   time.Minute,
+		HTTP:       &HTTPConfig{UserAgent: "default-agent"},
+		ServerHTTP: map[string]*HTTPConfig{
+			"https://b.example.com": {UserAgent: "override-agent", MaxBodyBytes: 4096},
+		},
+	})
+
+	if got := client.fetcherFor("https://a.example.com").userAgent; got != "default-agent" {
+		t.Errorf("fetcher a userAgent = %q, want %q", got, "default-agent")
+	}
+
+	b := client.fetcherFor("https://b.example.com")
+	if b.userAgent != "override-agent" {
+		t.Errorf("fetcher b userAgent = %q, want %q", b.userAgent, "override-agent")
+	}
+	if b.maxBodyBytes != 4096 {
+		t.Errorf("fetcher b maxBodyBytes = %d, want 4096", b.maxBodyBytes)
+	}
+
+	if client.fetcherFor("https://unknown.example.com") != client.fetcher {
+		t.Error("fetcherFor(unknown) should return the default fetcher")
+	}
+}
@@ -0,0 +1,180 @@
+package jwks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion is the current on-disk snapshot format version.
+const snapshotVersion = 1
+
+// Integrity algorithms for snapshot files.
+const (
+	// snapshotAlgHMAC authenticates the payload with HMAC-SHA256 under a configured key.
+	snapshotAlgHMAC = "HS256"
+
+	// snapshotAlgDigest detects corruption with a plain SHA-256 digest when no key is configured.
+	snapshotAlgDigest = "SHA256"
+)
+
+// PersistenceConfig enables snapshotting the last good key sets to disk so that
+// a restarted server can validate tokens before the first successful fetch.
+type PersistenceConfig struct {
+	// Path is the snapshot file location.
+	Path string
+
+	// Key authenticates the snapshot with HMAC-SHA256 (recommended).
+	// Without a key, a SHA-256 digest only detects accidental corruption.
+	Key []byte
+
+	// MaxStale is how long after their fetch time persisted keys remain usable
+	// while the authorization server cannot be reached.
+	MaxStale time.Duration
+}
+
+// Snapshot is the last good key set of each authorization server.
+type Snapshot struct {
+	// Issuers maps authorization server URLs to their key sets.
+	Issuers map[string]*KeySetSnapshot `json:"issuers"`
+}
+
+// KeySetSnapshot records a JWKS together with where and when it was fetched.
+type KeySetSnapshot struct {
+	// JWKSURI is the jwks_uri discovered from the authorization server metadata.
+	JWKSURI string `json:"jwks_uri"`
+
+	// FetchedAt is when the key set was fetched.
+	FetchedAt time.Time `json:"fetched_at"`
+
+	// Keys is the key set as published by the authorization server.
+	Keys JWKS `json:"jwks"`
+}
+
+// digest identifies the content of the key set, leaving out when it was
+// fetched, so that refetching an unchanged key set yields the same digest.
+func (s *KeySetSnapshot) digest() [sha256.Size]byte {
+	content, err := json.Marshal(struct {
+		JWKSURI string `json:"jwks_uri"`
+		Keys    JWKS   `json:"jwks"`
+	}{s.JWKSURI, s.Keys})
+	if err != nil {
+		// A key set decoded from JSON always encodes; never match on failure
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256(content)
+}
+
+// snapshotFile is the integrity-protected envelope written to disk.
+type snapshotFile struct {
+	Version   int    `json:"version"`
+	Algorithm string `json:"alg"`
+	MAC       string `json:"mac"`
+	Payload   string `json:"payload"`
+}
+
+// WriteSnapshot atomically writes the snapshot to path with owner-only permissions.
+func WriteSnapshot(path string, key []byte, snapshot *Snapshot) error {
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	alg, mac := snapshotMAC(key, payload)
+	data, err := json.Marshal(snapshotFile{
+		Version:   snapshotVersion,
+		Algorithm: alg,
+		MAC:       base64.RawURLEncoding.EncodeToString(mac),
+		Payload:   base64.RawURLEncoding.EncodeToString(payload),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot envelope: %w", err)
+	}
+
+	// Write to a temporary file in the same directory and rename it into
+	// place so readers never observe a partially written snapshot
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set snapshot file permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// ReadSnapshot reads and verifies a snapshot written by WriteSnapshot.
+// The integrity algorithm must match the key configuration: a keyed reader
+// rejects digest-only files so an attacker cannot strip the HMAC.
+func ReadSnapshot(path string, key []byte) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot envelope: %w", err)
+	}
+
+	if file.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", file.Version)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(file.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot payload: %w", err)
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(file.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot mac: %w", err)
+	}
+
+	alg, wantMAC := snapshotMAC(key, payload)
+	if file.Algorithm != alg {
+		return nil, fmt.Errorf("snapshot integrity algorithm %q does not match expected %q", file.Algorithm, alg)
+	}
+	if !hmac.Equal(gotMAC, wantMAC) {
+		return nil, fmt.Errorf("snapshot integrity check failed")
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// snapshotMAC computes the integrity tag for payload and names the algorithm used.
+func snapshotMAC(key, payload []byte) (string, []byte) {
+	if len(key) == 0 {
+		sum := sha256.Sum256(payload)
+		return snapshotAlgDigest, sum[:]
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return snapshotAlgHMAC, mac.Sum(nil)
+}
@@ -0,0 +1,345 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testSnapshot(t *testing.T) *Snapshot {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	return &Snapshot{Issuers: map[string]*KeySetSnapshot{
+		"https://auth.example.com": {
+			JWKSURI:   "https://auth.example.com/jwks",
+			FetchedAt: time.Now().UTC().Truncate(time.Second),
+			Keys:      JWKS{Keys: []JWK{rsaJWK("key-1", &key.PublicKey)}},
+		},
+	}}
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		key  []byte
+	}{
+		{name: "hmac", key: []byte("0123456789abcdef0123456789abcdef")},
+		{name: "digest only", key: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "jwks.json")
+			want := testSnapshot(t)
+
+			if err := WriteSnapshot(path, tt.key, want); err != nil {
+				t.Fatalf("WriteSnapshot() unexpected error: %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Stat() unexpected error: %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != 0o600 {
+				t.Errorf("snapshot permissions = %o, want 600", perm)
+			}
+
+			got, err := ReadSnapshot(path, tt.key)
+			if err != nil {
+				t.Fatalf("ReadSnapshot() unexpected error: %v", err)
+			}
+
+			issuer := "https://auth.example.com"
+			if got.Issuers[issuer] == nil {
+				t.Fatalf("ReadSnapshot() missing issuer %s", issuer)
+			}
+			if got.Issuers[issuer].JWKSURI != want.Issuers[issuer].JWKSURI {
+				t.Errorf("JWKSURI = %q, want %q", got.Issuers[issuer].JWKSURI, want.Issuers[issuer].JWKSURI)
+			}
+			if !got.Issuers[issuer].FetchedAt.Equal(want.Issuers[issuer].FetchedAt) {
+				t.Errorf("FetchedAt = %v, want %v", got.Issuers[issuer].FetchedAt, want.Issuers[issuer].FetchedAt)
+			}
+			if len(got.Issuers[issuer].Keys.Keys) != 1 || got.Issuers[issuer].Keys.Keys[0].KeyID != "key-1" {
+				t.Errorf("Keys = %+v, want single key-1", got.Issuers[issuer].Keys.Keys)
+			}
+		})
+	}
+}
+
+func TestReadSnapshot_Rejects(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name     string
+		writeKey []byte
+		readKey  []byte
+		mutate   func(f *snapshotFile)
+	}{
+		{
+			name:     "tampered payload",
+			writeKey: key,
+			readKey:  key,
+			mutate: func(f *snapshotFile) {
+				f.Payload = base64.RawURLEncoding.EncodeToString([]byte(`{"issuers":{}}`))
+			},
+		},
+		{
+			name:     "wrong key",
+			writeKey: key,
+			readKey:  []byte("fedcba9876543210fedcba9876543210"),
+			mutate:   func(f *snapshotFile) {},
+		},
+		{
+			name:     "digest file read with key",
+			writeKey: nil,
+			readKey:  key,
+			mutate:   func(f *snapshotFile) {},
+		},
+		{
+			name:     "unsupported version",
+			writeKey: key,
+			readKey:  key,
+			mutate: func(f *snapshotFile) {
+				f.Version = snapshotVersion + 1
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "jwks.json")
+			if err := WriteSnapshot(path, tt.writeKey, testSnapshot(t)); err != nil {
+				t.Fatalf("WriteSnapshot() unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() unexpected error: %v", err)
+			}
+			var file snapshotFile
+			if err := json.Unmarshal(data, &file); err != nil {
+				t.Fatalf("Unmarshal() unexpected error: %v", err)
+			}
+			tt.mutate(&file)
+			data, err = json.Marshal(file)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatalf("WriteFile() unexpected error: %v", err)
+			}
+
+			if _, err := ReadSnapshot(path, tt.readKey); err == nil {
+				t.Error("ReadSnapshot() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestReadSnapshot_Missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadSnapshot(filepath.Join(t.TempDir(), "missing.json"), nil)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadSnapshot() error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestClient_RestoresPersistedKeys(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	var available atomic.Bool
+	available.Store(true)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			_ = json.NewEncoder(w).Encode(AuthorizationServerMetadata{
+				Issuer:  server.URL,
+				JWKSURI: server.URL + "/jwks",
+			})
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(JWKS{Keys: []JWK{rsaJWK("key-1", &key.PublicKey)}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &ClientConfig{
+		ServerURLs: []string{server.URL},
+		CacheTTL:   5 * time.Minute,
+		Persistence: &PersistenceConfig{
+			Path:     filepath.Join(t.TempDir(), "jwks.json"),
+			Key:      []byte("0123456789abcdef0123456789abcdef"),
+			MaxStale: time.Hour,
+		},
+	}
+
+	// A first client fetches the key set and persists it
+	first := NewClientWithConfig(cfg)
+	if _, err := first.GetKey(context.Background(), "key-1"); err != nil {
+		t.Fatalf("GetKey() unexpected error: %v", err)
+	}
+
+	// After a restart with the authorization server down, the key is served
+	// from the snapshot and marked stale
+	available.Store(false)
+	restored := NewClientWithConfig(cfg)
+	if _, err := restored.GetKey(context.Background(), "key-1"); err != nil {
+		t.Fatalf("GetKey() with AS unavailable unexpected error: %v", err)
+	}
+	if !restored.cache.IsStale("key-1") {
+		t.Error("restored key should be marked stale")
+	}
+
+	// Once the authorization server is back, the background refresh replaces
+	// the stale key with a fresh one
+	available.Store(true)
+	refreshed := NewClientWithConfig(cfg)
+	deadline := time.Now().Add(5 * time.Second)
+	for refreshed.cache.IsStale("key-1") {
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not replace the stale key")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if refreshed.cache.Get("key-1") == nil {
+		t.Error("refreshed key should be cached")
+	}
+}
+
+func TestClient_IgnoresExpiredSnapshot(t *testing.T) {
+	t.Parallel()
+
+	serverURL := "https://auth.example.com"
+	snapshot := testSnapshot(t)
+	snapshot.Issuers[serverURL].FetchedAt = time.Now().Add(-2 * time.Hour)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := WriteSnapshot(path, nil, snapshot); err != nil {
+		t.Fatalf("WriteSnapshot() unexpected error: %v", err)
+	}
+
+	client := NewClientWithConfig(&ClientConfig{
+		ServerURLs:  []string{serverURL},
+		CacheTTL:    5 * time.Minute,
+		Persistence: &PersistenceConfig{Path: path, MaxStale: time.Hour},
+	})
+
+	if client.cache.Get("key-1") != nil {
+		t.Error("keys older than MaxStale should not be restored")
+	}
+}
+
+func TestClient_PersistsOnlyChangedKeySets(t *testing.T) {
+	t.Parallel()
+
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	var rotated atomic.Bool
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			_ = json.NewEncoder(w).Encode(AuthorizationServerMetadata{
+				Issuer:  server.URL,
+				JWKSURI: server.URL + "/jwks",
+			})
+		case "/jwks":
+			jwk := rsaJWK("key-1", &first.PublicKey)
+			if rotated.Load() {
+				jwk = rsaJWK("key-2", &second.PublicKey)
+			}
+			_ = json.NewEncoder(w).Encode(JWKS{Keys: []JWK{jwk}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	client := NewClientWithConfig(&ClientConfig{
+		ServerURLs:  []string{server.URL},
+		CacheTTL:    time.Hour,
+		Persistence: &PersistenceConfig{Path: path, MaxStale: time.Hour},
+	})
+
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot was not written: %v", err)
+	}
+
+	// Refetching an unchanged key set, as a lookup of an unknown key ID
+	// does, leaves the snapshot alone
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove snapshot: %v", err)
+	}
+	if _, err := client.GetKey(context.Background(), "unknown"); err == nil {
+		t.Fatal("GetKey(unknown) error = nil, want error")
+	}
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("snapshot rewritten for an unchanged key set: %v", err)
+	}
+
+	// A rotated key set is written
+	rotated.Store(true)
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() unexpected error: %v", err)
+	}
+	snapshot, err := ReadSnapshot(path, nil)
+	if err != nil {
+		t.Fatalf("snapshot was not written after rotation: %v", err)
+	}
+	if keys := snapshot.Issuers[server.URL].Keys.Keys; len(keys) != 1 || keys[0].KeyID != "key-2" {
+		t.Errorf("persisted keys = %+v, want key-2", keys)
+	}
+}
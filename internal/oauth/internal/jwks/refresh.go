@@ -0,0 +1,55 @@
+package jwks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RefreshError reports the authorization servers whose key sets could not be
+// refreshed. Servers not listed were refreshed successfully.
+type RefreshError struct {
+	// Errors maps authorization server URLs to their refresh failure.
+	Errors map[string]error
+
+	order []string // configured server order, for stable messages
+}
+
+// Error implements the error interface.
+func (e *RefreshError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, serverURL := range e.servers() {
+		parts = append(parts, fmt.Sprintf("%s: %v", serverURL, e.Errors[serverURL]))
+	}
+	return fmt.Sprintf("failed to refresh %d authorization server(s): %s", len(e.Errors), strings.Join(parts, "; "))
+}
+
+// Unwrap returns the individual server errors so errors.Is and errors.As
+// can match any of them.
+func (e *RefreshError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, serverURL := range e.servers() {
+		errs = append(errs, e.Errors[serverURL])
+	}
+	return errs
+}
+
+// servers returns the failed server URLs in configuration order,
+// or sorted if the order is unknown.
+func (e *RefreshError) servers() []string {
+	servers := make([]string, 0, len(e.Errors))
+	if len(e.order) == 0 {
+		for serverURL := range e.Errors {
+			servers = append(servers, serverURL)
+		}
+		sort.Strings(servers)
+		return servers
+	}
+
+	for _, serverURL := range e.order {
+		if _, ok := e.Errors[serverURL]; ok {
+			servers = append(servers, serverURL)
+		}
+	}
+	return servers
+}
@@ -0,0 +1,95 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_RefreshKeys_IsolatesIssuers(t *testing.T) {
+	t.Parallel()
+
+	keyA1, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	keyA2, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	keyB, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	serverA := newRotatingServer(t, rsaJWK("a-1", &keyA1.PublicKey))
+	serverB := newRotatingServer(t, rsaJWK("b-1", &keyB.PublicKey))
+	client := NewClient([]string{serverA.URL, serverB.URL}, 5*time.Minute)
+
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() unexpected error: %v", err)
+	}
+
+	// Server A rotates its key while server B goes down
+	serverA.setKeys(rsaJWK("a-2", &keyA2.PublicKey))
+	serverB.setDown(true)
+
+	err = client.RefreshKeys(context.Background())
+	if err == nil {
+		t.Fatal("RefreshKeys() expected error while a server is down, got nil")
+	}
+
+	var refreshErr *RefreshError
+	if !errors.As(err, &refreshErr) {
+		t.Fatalf("RefreshKeys() error type = %T, want *RefreshError", err)
+	}
+	if len(refreshErr.Errors) != 1 || refreshErr.Errors[serverB.URL] == nil {
+		t.Errorf("RefreshError.Errors = %v, want only %s", refreshErr.Errors, serverB.URL)
+	}
+
+	// Server A's partition was replaced, server B's was kept
+	if client.cache.Get("a-2") == nil {
+		t.Error("rotated-in key of server A should be cached")
+	}
+	if client.cache.Get("a-1") != nil {
+		t.Error("rotated-out key of server A should be evicted")
+	}
+	if _, err := client.GetKey(context.Background(), "b-1"); err != nil {
+		t.Errorf("GetKey(b-1) with server B down unexpected error: %v", err)
+	}
+}
+
+func TestRefreshError(t *testing.T) {
+	t.Parallel()
+
+	errA := errors.New("connection refused")
+	errB := errors.New("status 503")
+	err := &RefreshError{
+		Errors: map[string]error{
+			"https://b.example.com": errB,
+			"https://a.example.com": errA,
+		},
+		order: []string{"https://b.example.com", "https://a.example.com"},
+	}
+
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error("errors.Is should match each server error")
+	}
+
+	msg := err.Error()
+	if !strings.Contains(msg, "2 authorization server(s)") {
+		t.Errorf("Error() = %q, want failure count", msg)
+	}
+	if strings.Index(msg, "b.example.com") > strings.Index(msg, "a.example.com") {
+		t.Errorf("Error() = %q, want servers in configuration order", msg)
+	}
+
+	unordered := &RefreshError{Errors: err.Errors}
+	if got := unordered.Unwrap(); len(got) != 2 || got[0] != errA {
+		t.Errorf("Unwrap() without order = %v, want sorted by server URL", got)
+	}
+}
@@ -0,0 +1,172 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/oautherr"
+)
+
+// issuerState tracks the key set and fetch outcome of one authorization server.
+type issuerState struct {
+	keySet      *KeySetSnapshot   // last good key set, nil until the first success
+	expiresAt   time.Time         // when keys from keySet leave the cache
+	stale       bool              // keySet was restored from a snapshot
+	persisted   [sha256.Size]byte // digest of the key set last written to disk
+	persistedAt time.Time         // fetch time of the key set last written to disk
+	lastError   error
+	lastErrorAt time.Time
+}
+
+// IssuerStatus describes the JWKS state of one authorization server.
+type IssuerStatus struct {
+	// Issuer is the authorization server URL as configured.
+	Issuer string `json:"issuer"`
+
+	// JWKSURI is the jwks_uri discovered from the server metadata.
+	JWKSURI string `json:"jwks_uri,omitempty"`
+
+	// Keys lists the keys of the last good key set.
+	Keys []KeyStatus `json:"keys"`
+
+	// FetchedAt is when the last good key set was fetched, nil before the first success.
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+
+	// ExpiresAt is when keys from the last good key set leave the cache.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Stale reports that the key set was restored from a snapshot and has
+	// not been refreshed since.
+	Stale bool `json:"stale,omitempty"`
+
+	// LastError is the error of the most recent failed fetch, cleared by
+	// the next successful one.
+	LastError string `json:"last_error,omitempty"`
+
+	// LastErrorAt is when LastError occurred.
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// KeyStatus describes one key published by an authorization server.
+type KeyStatus struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+
+	// Size is the key size in bits (RSA modulus or EC curve size).
+	Size int `json:"size,omitempty"`
+
+	// Trusted reports whether the key passed parsing and x5c validation
+	// and is therefore used to verify tokens.
+	Trusted bool `json:"trusted"`
+
+	// Error explains why an untrusted key was rejected.
+	Error string `json:"error,omitempty"`
+}
+
+// Status returns the JWKS state of every configured authorization server,
+// in configuration order.
+func (c *Client) Status() []IssuerStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := make([]IssuerStatus, 0, len(c.serverURLs))
+	for _, serverURL := range c.serverURLs {
+		status := IssuerStatus{
+			Issuer:  serverURL,
+			JWKSURI: c.jwksURICache[serverURL],
+			Keys:    []KeyStatus{},
+		}
+
+		if state, ok := c.issuers[serverURL]; ok {
+			if state.keySet != nil {
+				fetchedAt, expiresAt := state.keySet.FetchedAt, state.expiresAt
+				status.FetchedAt = &fetchedAt
+				status.ExpiresAt = &expiresAt
+				status.Stale = state.stale
+				for _, jwk := range state.keySet.Keys.Keys {
+					status.Keys = append(status.Keys, c.keyStatus(&jwk))
+				}
+			}
+			if state.lastError != nil {
+				status.LastError = state.lastError.Error()
+				lastErrorAt := state.lastErrorAt
+				status.LastErrorAt = &lastErrorAt
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// RefreshIssuer re-fetches the key set of a single authorization server.
+// Unlike RefreshKeys, the caches of other servers are left untouched.
+// The server's JWKS URI is rediscovered and its cached keys are replaced
+// only if the new key set is fetched successfully.
+func (c *Client) RefreshIssuer(ctx context.Context, serverURL string) error {
+	if _, ok := c.fetchers[serverURL]; !ok {
+		return oautherr.NewUnknownAuthorizationServerError("RefreshIssuer", serverURL)
+	}
+
+	_, err := c.fetchKeySet(ctx, serverURL, true)
+	return err
+}
+
+// keyStatus describes a JWK, validating it the same way fetched keys are.
+func (c *Client) keyStatus(jwk *JWK) KeyStatus {
+	status := KeyStatus{
+		KeyID:     jwk.KeyID,
+		KeyType:   jwk.KeyType,
+		Algorithm: jwk.Algorithm,
+		Use:       jwk.Use,
+		Curve:     jwk.Curve,
+	}
+
+	key, err := c.parseKey(jwk)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if jwk.KeyID == "" {
+		status.Error = "key has no kid"
+		return status
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		status.Size = k.N.BitLen()
+	case *ecdsa.PublicKey:
+		status.Size = k.Curve.Params().BitSize
+	}
+	status.Trusted = true
+
+	return status
+}
+
+// issuerStateLocked returns the state for serverURL, creating it if needed.
+// The caller must hold c.mu for writing.
+func (c *Client) issuerStateLocked(serverURL string) *issuerState {
+	state, ok := c.issuers[serverURL]
+	if !ok {
+		state = &issuerState{}
+		c.issuers[serverURL] = state
+	}
+	return state
+}
+
+// recordError remembers the most recent fetch failure for serverURL.
+func (c *Client) recordError(serverURL string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.issuerStateLocked(serverURL)
+	state.lastError = err
+	state.lastErrorAt = time.Now()
+}
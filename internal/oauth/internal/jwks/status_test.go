@@ -0,0 +1,209 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ierrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
+)
+
+// rotatingServer is a mock authorization server whose published keys can be replaced.
+type rotatingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []JWK
+	down bool
+}
+
+func newRotatingServer(t *testing.T, keys ...JWK) *rotatingServer {
+	t.Helper()
+
+	rs := &rotatingServer{keys: keys}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		defer rs.mu.Unlock()
+
+		if rs.down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			_ = json.NewEncoder(w).Encode(AuthorizationServerMetadata{
+				Issuer:  rs.URL,
+				JWKSURI: rs.URL + "/jwks",
+			})
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(JWKS{Keys: rs.keys})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(rs.Close)
+
+	return rs
+}
+
+func (rs *rotatingServer) setKeys(keys ...JWK) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.keys = keys
+}
+
+func (rs *rotatingServer) setDown(down bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.down = down
+}
+
+func TestClient_Status(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	ecJWK := JWK{
+		KeyType:   "EC",
+		KeyID:     "ec-key",
+		Algorithm: "ES256",
+		Curve:     "P-256",
+		X:         encodeBase64URL(ecKey.X.FillBytes(make([]byte, 32))),
+		Y:         encodeBase64URL(ecKey.Y.FillBytes(make([]byte, 32))),
+	}
+	badJWK := JWK{KeyType: "oct", KeyID: "symmetric"}
+
+	server := newRotatingServer(t, rsaJWK("rsa-key", &rsaKey.PublicKey), ecJWK, badJWK)
+	client := NewClient([]string{server.URL}, 5*time.Minute)
+
+	// Before any fetch the issuer is listed without keys
+	statuses := client.Status()
+	if len(statuses) != 1 || statuses[0].FetchedAt != nil || len(statuses[0].Keys) != 0 {
+		t.Fatalf("Status() before fetch = %+v, want empty issuer", statuses)
+	}
+
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() unexpected error: %v", err)
+	}
+
+	status := client.Status()[0]
+	if status.JWKSURI != server.URL+"/jwks" {
+		t.Errorf("JWKSURI = %q, want %q", status.JWKSURI, server.URL+"/jwks")
+	}
+	if status.FetchedAt == nil || status.ExpiresAt == nil {
+		t.Fatal("FetchedAt and ExpiresAt should be set after a fetch")
+	}
+	if got := status.ExpiresAt.Sub(*status.FetchedAt); got != 5*time.Minute {
+		t.Errorf("ExpiresAt - FetchedAt = %v, want %v", got, 5*time.Minute)
+	}
+	if status.LastError != "" {
+		t.Errorf("LastError = %q, want empty", status.LastError)
+	}
+
+	want := map[string]struct {
+		size    int
+		trusted bool
+	}{
+		"rsa-key":   {size: 2048, trusted: true},
+		"ec-key":    {size: 256, trusted: true},
+		"symmetric": {size: 0, trusted: false},
+	}
+	if len(status.Keys) != len(want) {
+		t.Fatalf("len(Keys) = %d, want %d", len(status.Keys), len(want))
+	}
+	for _, key := range status.Keys {
+		w := want[key.KeyID]
+		if key.Size != w.size || key.Trusted != w.trusted {
+			t.Errorf("key %s: size=%d trusted=%v, want size=%d trusted=%v", key.KeyID, key.Size, key.Trusted, w.size, w.trusted)
+		}
+		if !key.Trusted && key.Error == "" {
+			t.Errorf("key %s: untrusted key should explain why", key.KeyID)
+		}
+	}
+
+	// A failed fetch is reported but the last good key set is kept
+	server.setDown(true)
+	if err := client.RefreshIssuer(context.Background(), server.URL); err == nil {
+		t.Fatal("RefreshIssuer() expected error while server is down, got nil")
+	}
+	status = client.Status()[0]
+	if status.LastError == "" || status.LastErrorAt == nil {
+		t.Error("LastError should be recorded after a failed fetch")
+	}
+	if len(status.Keys) != len(want) {
+		t.Errorf("len(Keys) after failure = %d, want %d", len(status.Keys), len(want))
+	}
+
+	server.setDown(false)
+	if err := client.RefreshIssuer(context.Background(), server.URL); err != nil {
+		t.Fatalf("RefreshIssuer() unexpected error: %v", err)
+	}
+	if status := client.Status()[0]; status.LastError != "" {
+		t.Errorf("LastError after recovery = %q, want empty", status.LastError)
+	}
+}
+
+func TestClient_RefreshIssuer(t *testing.T) {
+	t.Parallel()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	rotating := newRotatingServer(t, rsaJWK("old", &oldKey.PublicKey))
+	other := newRotatingServer(t, rsaJWK("other", &otherKey.PublicKey))
+	client := NewClient([]string{rotating.URL, other.URL}, 5*time.Minute)
+
+	if err := client.RefreshKeys(context.Background()); err != nil {
+		t.Fatalf("RefreshKeys() unexpected error: %v", err)
+	}
+
+	// Rotate the first server's key and take the second server offline:
+	// refreshing the first must not disturb the second's cached keys
+	rotating.setKeys(rsaJWK("new", &newKey.PublicKey))
+	other.setDown(true)
+
+	if err := client.RefreshIssuer(context.Background(), rotating.URL); err != nil {
+		t.Fatalf("RefreshIssuer() unexpected error: %v", err)
+	}
+
+	if client.cache.Get("new") == nil {
+		t.Error("rotated-in key should be cached")
+	}
+	if client.cache.Get("old") != nil {
+		t.Error("rotated-out key should be evicted")
+	}
+	if client.cache.Get("other") == nil {
+		t.Error("keys of other issuers should be left untouched")
+	}
+
+	err = client.RefreshIssuer(context.Background(), "https://unknown.example.com")
+	if !errors.Is(err, ierrors.ErrNotFound) {
+		t.Errorf("RefreshIssuer(unknown) error = %v, want ErrNotFound", err)
+	}
+}
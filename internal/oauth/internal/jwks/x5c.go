@@ -0,0 +1,157 @@
+package jwks
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // x5t is defined by RFC 7517 as a SHA-1 thumbprint
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+)
+
+// X5CPolicy controls how x5c certificate chains attached to JWKS keys are validated.
+//
+// Keys that carry an x5c chain are always checked: the chain must parse, the leaf
+// certificate must be within its validity period, permit digital signatures, and
+// certify the same public key as the JWK's n/e or x/y parameters. The policy adds
+// optional trust anchor verification and can require every key to carry a chain.
+// Configuring trust anchors requires a chain as well: a key without one could
+// not be verified against them.
+type X5CPolicy struct {
+	// Roots is the CA pool that x5c chains must verify against. Keys
+	// without a chain are dropped when it is set. When nil, chains are
+	// parsed and matched against the key but not verified to a trust anchor.
+	Roots *x509.CertPool
+
+	// Require drops keys that do not carry an x5c chain. It is implied by Roots.
+	Require bool
+}
+
+// validateX5C validates the x5c chain of a JWK against its decoded public key.
+// Returns nil if the JWK has no chain and the policy neither requires one nor
+// sets trust anchors.
+func validateX5C(jwk *JWK, pub any, policy *X5CPolicy, now time.Time) error {
+	if len(jwk.X5C) == 0 {
+		if policy != nil && (policy.Require || policy.Roots != nil) {
+			return fmt.Errorf("x5c certificate chain is required")
+		}
+		return nil
+	}
+
+	certs := make([]*x509.Certificate, 0, len(jwk.X5C))
+	for i, encoded := range jwk.X5C {
+		// x5c entries use standard base64, not base64url (RFC 7517 Section 4.7)
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode x5c[%d]: %w", i, err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("failed to parse x5c[%d]: %w", i, err)
+		}
+		certs = append(certs, cert)
+	}
+	leaf := certs[0]
+
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("x5c leaf certificate is not valid until %s", leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("x5c leaf certificate expired at %s", leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	// A KeyUsage of zero means the extension is absent, which places no restriction
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return fmt.Errorf("x5c leaf certificate does not permit digital signatures")
+	}
+
+	if !publicKeysEqual(leaf.PublicKey, pub) {
+		return fmt.Errorf("x5c leaf certificate public key does not match JWK key parameters")
+	}
+
+	if err := checkThumbprints(jwk, leaf.Raw); err != nil {
+		return err
+	}
+
+	if policy == nil || policy.Roots == nil {
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         policy.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("x5c chain verification failed: %w", err)
+	}
+
+	return nil
+}
+
+// checkThumbprints verifies the optional x5t and x5t#S256 parameters against the leaf certificate.
+func checkThumbprints(jwk *JWK, leafDER []byte) error {
+	if jwk.X5T != "" {
+		want, err := base64URLDecode(jwk.X5T)
+		if err != nil {
+			return fmt.Errorf("failed to decode x5t: %w", err)
+		}
+		sum := sha1.Sum(leafDER) //nolint:gosec // thumbprint comparison only
+		if !bytes.Equal(want, sum[:]) {
+			return fmt.Errorf("x5t thumbprint does not match x5c leaf certificate")
+		}
+	}
+
+	if jwk.X5TS256 != "" {
+		want, err := base64URLDecode(jwk.X5TS256)
+		if err != nil {
+			return fmt.Errorf("failed to decode x5t#S256: %w", err)
+		}
+		sum := sha256.Sum256(leafDER)
+		if !bytes.Equal(want, sum[:]) {
+			return fmt.Errorf("x5t#S256 thumbprint does not match x5c leaf certificate")
+		}
+	}
+
+	return nil
+}
+
+// publicKeysEqual reports whether a certificate public key matches a key decoded from a JWK.
+func publicKeysEqual(certKey, jwkKey any) bool {
+	switch k := jwkKey.(type) {
+	case *rsa.PublicKey:
+		certRSA, ok := certKey.(*rsa.PublicKey)
+		return ok && k.Equal(certRSA)
+	case *ecdsa.PublicKey:
+		certEC, ok := certKey.(*ecdsa.PublicKey)
+		return ok && k.Equal(certEC)
+	default:
+		return false
+	}
+}
+
+// LoadCertPool reads a PEM-encoded CA bundle from disk into a certificate pool.
+// Returns an error if the file cannot be read or contains no certificates.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+
+	return pool, nil
+}
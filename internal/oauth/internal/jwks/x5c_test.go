@@ -0,0 +1,352 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA is a throwaway certificate authority for x5c tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue signs a leaf certificate for pub and returns its DER encoding.
+func (ca *testCA) issue(t *testing.T, pub any, notBefore, notAfter time.Time, usage x509.KeyUsage) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "jwks signing key"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     usage,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, pub, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create leaf certificate: %v", err)
+	}
+	return der
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey, chain ...[]byte) JWK {
+	jwk := JWK{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Algorithm: "RS256",
+		N:         encodeBase64URL(pub.N.Bytes()),
+		E:         encodeBase64URL(big.NewInt(int64(pub.E)).Bytes()),
+	}
+	for _, der := range chain {
+		jwk.X5C = append(jwk.X5C, base64.StdEncoding.EncodeToString(der))
+	}
+	return jwk
+}
+
+func TestValidateX5C(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	now := time.Now()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	validLeaf := ca.issue(t, &rsaKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour), x509.KeyUsageDigitalSignature)
+	expiredLeaf := ca.issue(t, &rsaKey.PublicKey, now.Add(-2*time.Hour), now.Add(-time.Hour), x509.KeyUsageDigitalSignature)
+	futureLeaf := ca.issue(t, &rsaKey.PublicKey, now.Add(time.Hour), now.Add(2*time.Hour), x509.KeyUsageDigitalSignature)
+	encipherLeaf := ca.issue(t, &rsaKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour), x509.KeyUsageKeyEncipherment)
+	otherKeyLeaf := ca.issue(t, &otherKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour), x509.KeyUsageDigitalSignature)
+	untrustedLeaf := otherCA.issue(t, &rsaKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour), x509.KeyUsageDigitalSignature)
+
+	thumbprint := sha256.Sum256(validLeaf)
+
+	tests := []struct {
+		name        string
+		jwk         JWK
+		policy      *X5CPolicy
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:   "no chain and no policy",
+			jwk:    rsaJWK("k1", &rsaKey.PublicKey),
+			policy: nil,
+		},
+		{
+			name:        "no chain when required",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey),
+			policy:      &X5CPolicy{Require: true},
+			wantErr:     true,
+			errContains: "required",
+		},
+		{
+			name:        "no chain with trust anchors",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey),
+			policy:      &X5CPolicy{Roots: ca.pool()},
+			wantErr:     true,
+			errContains: "required",
+		},
+		{
+			name:   "valid chain without trust anchors",
+			jwk:    rsaJWK("k1", &rsaKey.PublicKey, validLeaf),
+			policy: nil,
+		},
+		{
+			name:   "valid chain verified against roots",
+			jwk:    rsaJWK("k1", &rsaKey.PublicKey, validLeaf),
+			policy: &X5CPolicy{Roots: ca.pool(), Require: true},
+		},
+		{
+			name:        "chain from untrusted CA",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey, untrustedLeaf),
+			policy:      &X5CPolicy{Roots: ca.pool()},
+			wantErr:     true,
+			errContains: "verification failed",
+		},
+		{
+			name:        "expired leaf",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey, expiredLeaf),
+			wantErr:     true,
+			errContains: "expired",
+		},
+		{
+			name:        "leaf not yet valid",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey, futureLeaf),
+			wantErr:     true,
+			errContains: "not valid until",
+		},
+		{
+			name:        "leaf without digital signature usage",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey, encipherLeaf),
+			wantErr:     true,
+			errContains: "digital signatures",
+		},
+		{
+			name:        "leaf certifies a different key",
+			jwk:         rsaJWK("k1", &rsaKey.PublicKey, otherKeyLeaf),
+			wantErr:     true,
+			errContains: "does not match",
+		},
+		{
+			name:        "malformed base64",
+			jwk:         JWK{KeyType: "RSA", X5C: []string{"!!!"}},
+			wantErr:     true,
+			errContains: "decode x5c[0]",
+		},
+		{
+			name: "matching x5t#S256",
+			jwk: func() JWK {
+				jwk := rsaJWK("k1", &rsaKey.PublicKey, validLeaf)
+				jwk.X5TS256 = encodeBase64URL(thumbprint[:])
+				return jwk
+			}(),
+		},
+		{
+			name: "mismatched x5t#S256",
+			jwk: func() JWK {
+				jwk := rsaJWK("k1", &rsaKey.PublicKey, validLeaf)
+				jwk.X5TS256 = encodeBase64URL(make([]byte, sha256.Size))
+				return jwk
+			}(),
+			wantErr:     true,
+			errContains: "x5t#S256",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateX5C(&tt.jwk, &rsaKey.PublicKey, tt.policy, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("validateX5C() error = nil, want error")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("validateX5C() error = %q, want to contain %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validateX5C() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateX5C_ECKey(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t)
+	now := time.Now()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	leaf := ca.issue(t, &ecKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour), x509.KeyUsageDigitalSignature)
+	jwk := JWK{
+		KeyType: "EC",
+		KeyID:   "ec-1",
+		Curve:   "P-256",
+		X:       encodeBase64URL(ecKey.X.Bytes()),
+		Y:       encodeBase64URL(ecKey.Y.Bytes()),
+		X5C:     []string{base64.StdEncoding.EncodeToString(leaf)},
+	}
+
+	client := NewClientWithConfig(&ClientConfig{X5C: &X5CPolicy{Roots: ca.pool()}})
+	key, err := client.parseKey(&jwk)
+	if err != nil {
+		t.Fatalf("parseKey() unexpected error: %v", err)
+	}
+	if !ecKey.PublicKey.Equal(key) {
+		t.Error("parseKey() returned a different EC key")
+	}
+}
+
+func TestClient_GetKey_DropsKeysFailingX5C(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t)
+	now := time.Now()
+
+	trustedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	rogueKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	trustedLeaf := ca.issue(t, &trustedKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour), x509.KeyUsageDigitalSignature)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			_ = json.NewEncoder(w).Encode(AuthorizationServerMetadata{
+				Issuer:  server.URL,
+				JWKSURI: server.URL + "/jwks",
+			})
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(JWKS{Keys: []JWK{
+				rsaJWK("trusted", &trustedKey.PublicKey, trustedLeaf),
+				// A key injected without a chain must be ignored when trust anchors are set
+				rsaJWK("rogue", &rogueKey.PublicKey),
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(&ClientConfig{
+		ServerURLs: []string{server.URL},
+		CacheTTL:   5 * time.Minute,
+		X5C:        &X5CPolicy{Roots: ca.pool()},
+	})
+
+	if _, err := client.GetKey(context.Background(), "trusted"); err != nil {
+		t.Fatalf("GetKey(trusted) unexpected error: %v", err)
+	}
+
+	if _, err := client.GetKey(context.Background(), "rogue"); err == nil {
+		t.Error("GetKey(rogue) expected error for key without x5c chain, got nil")
+	}
+}
+
+func TestLoadCertPool(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t)
+	dir := t.TempDir()
+
+	validPath := filepath.Join(dir, "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	if err := os.WriteFile(validPath, pemData, 0o600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	emptyPath := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(emptyPath, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Failed to write empty bundle: %v", err)
+	}
+
+	if pool, err := LoadCertPool(validPath); err != nil || pool == nil {
+		t.Errorf("LoadCertPool(valid) = %v, %v; want pool, nil", pool, err)
+	}
+
+	if _, err := LoadCertPool(emptyPath); err == nil {
+		t.Error("LoadCertPool(empty) expected error, got nil")
+	}
+
+	if _, err := LoadCertPool(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("LoadCertPool(missing) expected error, got nil")
+	}
+}
@@ -0,0 +1,62 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// registeredParameters are the metadata parameter names defined by RFC 9728
+// and its referenced specifications. Extensions must not redefine them.
+var registeredParameters = map[string]bool{
+	"resource":                                   true,
+	"authorization_servers":                      true,
+	"jwks_uri":                                   true,
+	"scopes_supported":                           true,
+	"bearer_methods_supported":                   true,
+	"resource_signing_alg_values_supported":      true,
+	"resource_name":                              true,
+	"resource_documentation":                     true,
+	"resource_policy_uri":                        true,
+	"resource_tos_uri":                           true,
+	"tls_client_certificate_bound_access_tokens": true,
+	"authorization_details_types_supported":      true,
+	"dpop_signing_alg_values_supported":          true,
+	"dpop_bound_access_tokens_required":          true,
+	"signed_metadata":                            true,
+}
+
+// IsRegisteredParameter reports whether name is a registered metadata parameter.
+func IsRegisteredParameter(name string) bool {
+	return registeredParameters[name]
+}
+
+// MarshalWithExtensions encodes v, which must encode to a JSON object, and
+// adds the extension parameters to it. Registered parameters already present
+// in v take precedence over extensions with the same name.
+func MarshalWithExtensions(v any, extensions map[string]any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("metadata must encode to a JSON object: %w", err)
+	}
+
+	for name, value := range extensions {
+		if _, exists := fields[name]; exists || IsRegisteredParameter(name) {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode extension %q: %w", name, err)
+		}
+		fields[name] = encoded
+	}
+
+	return json.Marshal(fields)
+}
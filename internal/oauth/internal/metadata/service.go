@@ -3,16 +3,96 @@ package metadata
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
 )
 
+// WellKnownPath is the well-known URI suffix for protected resource metadata.
+const WellKnownPath = "/.well-known/oauth-protected-resource"
+
 // ProtectedResourceMetadata represents the OAuth 2.0 Protected Resource
 // Metadata as defined in RFC 9728.
 type ProtectedResourceMetadata struct {
-	Resource               string   `json:"resource"`
-	AuthorizationServers   []string `json:"authorization_servers"`
-	ScopesSupported        []string `json:"scopes_supported,omitempty"`
-	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+	Resource                              string   `json:"resource"`
+	AuthorizationServers                  []string `json:"authorization_servers"`
+	JWKSURI                               string   `json:"jwks_uri,omitempty"`
+	ScopesSupported                       []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported                []string `json:"bearer_methods_supported,omitempty"`
+	ResourceSigningAlgValuesSupported     []string `json:"resource_signing_alg_values_supported,omitempty"`
+	ResourceName                          string   `json:"resource_name,omitempty"`
+	ResourceDocumentation                 string   `json:"resource_documentation,omitempty"`
+	ResourcePolicyURI                     string   `json:"resource_policy_uri,omitempty"`
+	ResourceTOSURI                        string   `json:"resource_tos_uri,omitempty"`
+	TLSClientCertificateBoundAccessTokens bool     `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	AuthorizationDetailsTypesSupported    []string `json:"authorization_details_types_supported,omitempty"`
+	DPoPSigningAlgValuesSupported         []string `json:"dpop_signing_alg_values_supported,omitempty"`
+	DPoPBoundAccessTokensRequired         bool     `json:"dpop_bound_access_tokens_required,omitempty"`
+	SignedMetadata                        string   `json:"signed_metadata,omitempty"`
+
+	// Extensions holds additional metadata parameters, serialized alongside
+	// the registered ones. Names must not collide with registered parameters.
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON encodes the metadata with its extension parameters inlined.
+func (m ProtectedResourceMetadata) MarshalJSON() ([]byte, error) {
+	type plain ProtectedResourceMetadata
+	return MarshalWithExtensions(plain(m), m.Extensions)
+}
+
+// ScopeProvider reports the OAuth scopes a component of the server enforces.
+type ScopeProvider interface {
+	// RequiredScopes returns the scopes the component requires.
+	RequiredScopes() []string
+}
+
+// Options holds the optional RFC 9728 metadata parameters of a protected resource.
+type Options struct {
+	// ResourceName is a human-readable name of the protected resource.
+	ResourceName string
+
+	// ResourceDocumentation is a URL of developer documentation.
+	ResourceDocumentation string
+
+	// ResourcePolicyURI is a URL describing how client data is used.
+	ResourcePolicyURI string
+
+	// ResourceTOSURI is a URL of the terms of service.
+	ResourceTOSURI string
+
+	// JWKSURI is the URL of the resource's own JSON Web Key Set.
+	JWKSURI string
+
+	// ResourceSigningAlgValuesSupported lists the JWS algorithms the resource
+	// uses to sign its responses.
+	ResourceSigningAlgValuesSupported []string
+
+	// AuthorizationDetailsTypesSupported lists the RFC 9396 authorization
+	// details types the resource accepts.
+	AuthorizationDetailsTypesSupported []string
+
+	// TLSClientCertificateBoundAccessTokens advertises support for RFC 8705
+	// certificate-bound access tokens.
+	TLSClientCertificateBoundAccessTokens bool
+
+	// DPoPSigningAlgValuesSupported lists the JWS algorithms accepted for DPoP proofs.
+	DPoPSigningAlgValuesSupported []string
+
+	// DPoPBoundAccessTokensRequired advertises that DPoP-bound access tokens are always required.
+	DPoPBoundAccessTokensRequired bool
+
+	// Extensions holds additional metadata parameters.
+	Extensions map[string]any
+
+	// SigningKey signs the document into the signed_metadata parameter (optional).
+	SigningKey *SigningKey
+
+	// ScopeProviders derive scopes_supported from what the server enforces.
+	// When set, scopes_supported is the sorted union of the configured
+	// scopes and the scopes reported by each provider at request time.
+	ScopeProviders []ScopeProvider
 }
 
 // Service provides Protected Resource Metadata per RFC 9728.
@@ -21,7 +101,11 @@ type Service struct {
 	authorizationServers   []string
 	scopesSupported        []string
 	bearerMethodsSupported []string
+	options                Options
 	metadataURL            string
+
+	signedMu sync.Mutex
+	signed   *signedMetadata
 }
 
 // NewService creates a new metadata service.
@@ -31,29 +115,103 @@ type Service struct {
 //   - authorizationServers: array of authorization server URLs
 //   - scopesSupported: array of supported OAuth scopes (optional)
 func NewService(baseURL string, authorizationServers []string, scopesSupported []string) *Service {
+	return NewServiceWithOptions(baseURL, authorizationServers, scopesSupported, Options{})
+}
+
+// NewServiceWithOptions creates a new metadata service that also advertises
+// the optional RFC 9728 parameters in opts.
+func NewServiceWithOptions(baseURL string, authorizationServers []string, scopesSupported []string, opts Options) *Service {
 	// RFC 9728 requires Authorization header only for OAuth 2.1
 	bearerMethods := []string{"header"}
 
-	// Construct metadata URL: {baseURL}/.well-known/oauth-protected-resource
-	metadataURL := normalizeBaseURL(baseURL) + "/.well-known/oauth-protected-resource"
+	metadataURL := MetadataURL(baseURL)
 
-	return &Service{
+	service := &Service{
 		resource:               normalizeBaseURL(baseURL),
 		authorizationServers:   authorizationServers,
 		scopesSupported:        scopesSupported,
 		bearerMethodsSupported: bearerMethods,
+		options:                opts,
 		metadataURL:            metadataURL,
 	}
+	if opts.SigningKey != nil {
+		service.signed = &signedMetadata{key: opts.SigningKey}
+	}
+
+	return service
 }
 
 // GetMetadata returns the protected resource metadata document.
+// When a signing key is configured, the document includes signed_metadata.
 func (s *Service) GetMetadata(ctx context.Context) (*ProtectedResourceMetadata, error) {
-	return &ProtectedResourceMetadata{
-		Resource:               s.resource,
-		AuthorizationServers:   s.authorizationServers,
-		ScopesSupported:        s.scopesSupported,
-		BearerMethodsSupported: s.bearerMethodsSupported,
-	}, nil
+	metadata := &ProtectedResourceMetadata{
+		Resource:                              s.resource,
+		AuthorizationServers:                  s.authorizationServers,
+		JWKSURI:                               s.options.JWKSURI,
+		ScopesSupported:                       s.supportedScopes(),
+		BearerMethodsSupported:                s.bearerMethodsSupported,
+		ResourceSigningAlgValuesSupported:     s.options.ResourceSigningAlgValuesSupported,
+		ResourceName:                          s.options.ResourceName,
+		ResourceDocumentation:                 s.options.ResourceDocumentation,
+		ResourcePolicyURI:                     s.options.ResourcePolicyURI,
+		ResourceTOSURI:                        s.options.ResourceTOSURI,
+		TLSClientCertificateBoundAccessTokens: s.options.TLSClientCertificateBoundAccessTokens,
+		AuthorizationDetailsTypesSupported:    s.options.AuthorizationDetailsTypesSupported,
+		DPoPSigningAlgValuesSupported:         s.options.DPoPSigningAlgValuesSupported,
+		DPoPBoundAccessTokensRequired:         s.options.DPoPBoundAccessTokensRequired,
+		Extensions:                            s.options.Extensions,
+	}
+
+	if s.signed != nil {
+		s.signedMu.Lock()
+		token, err := s.signed.tokenFor(metadata)
+		s.signedMu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		metadata.SignedMetadata = token
+	}
+
+	return metadata, nil
+}
+
+// supportedScopes returns the configured scopes merged with those reported by
+// the scope providers. Without providers, the configured scopes are returned as is.
+func (s *Service) supportedScopes() []string {
+	if len(s.options.ScopeProviders) == 0 {
+		return s.scopesSupported
+	}
+
+	seen := make(map[string]bool)
+	var scopes []string
+	add := func(list []string) {
+		for _, scope := range list {
+			if scope == "" || seen[scope] {
+				continue
+			}
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	add(s.scopesSupported)
+	for _, provider := range s.options.ScopeProviders {
+		add(provider.RequiredScopes())
+	}
+
+	// A stable order keeps the document, and its signature, unchanged
+	// until the set of scopes changes
+	sort.Strings(scopes)
+	return scopes
+}
+
+// JWKS returns the public key set of the metadata signing key,
+// or nil when metadata is not signed.
+func (s *Service) JWKS() *JSONWebKeySet {
+	if s.options.SigningKey == nil {
+		return nil
+	}
+	return &JSONWebKeySet{Keys: []JSONWebKey{s.options.SigningKey.PublicJWK()}}
 }
 
 // GetMetadataURL returns the canonical URL where this metadata is served.
@@ -61,6 +219,25 @@ func (s *Service) GetMetadataURL() string {
 	return s.metadataURL
 }
 
+// MetadataURL returns the metadata URL of a protected resource per RFC 9728
+// section 3.1: the well-known path is inserted between the host and the path
+// of the resource identifier, so "https://host/mcp" is described at
+// "https://host/.well-known/oauth-protected-resource/mcp".
+func MetadataURL(resource string) string {
+	normalized := normalizeBaseURL(resource)
+
+	parsed, err := url.Parse(normalized)
+	if err != nil || parsed.Host == "" {
+		return normalized + WellKnownPath
+	}
+
+	metadataURL := parsed.Scheme + "://" + parsed.Host + WellKnownPath + parsed.EscapedPath()
+	if parsed.RawQuery != "" {
+		metadataURL += "?" + parsed.RawQuery
+	}
+	return metadataURL
+}
+
 // normalizeBaseURL ensures the base URL has no trailing slash unless semantically significant.
 // Per RFC 8707, resource identifiers should not have trailing slashes unless they are
 // semantically meaningful (e.g., representing a collection vs. a specific resource).
@@ -83,10 +260,95 @@ func ValidateMetadata(metadata *ProtectedResourceMetadata) error {
 		if server == "" {
 			return fmt.Errorf("authorization server URL cannot be empty")
 		}
-		if !strings.HasPrefix(server, "https://") && !strings.HasPrefix(server, "http://localhost") {
+		if !strings.HasPrefix(server, "https://") && !isLocalHTTP(server) {
 			return fmt.Errorf("authorization server URL must use HTTPS (or http://localhost for testing): %s", server)
 		}
 	}
 
+	// Validate optional URL parameters
+	urlFields := []struct {
+		name  string
+		value string
+	}{
+		{"jwks_uri", metadata.JWKSURI},
+		{"resource_documentation", metadata.ResourceDocumentation},
+		{"resource_policy_uri", metadata.ResourcePolicyURI},
+		{"resource_tos_uri", metadata.ResourceTOSURI},
+	}
+	for _, field := range urlFields {
+		if field.value == "" {
+			continue
+		}
+		if err := validateURL(field.value); err != nil {
+			return fmt.Errorf("%s %w", field.name, err)
+		}
+	}
+
+	// Validate algorithm lists; "none" is never acceptable for signatures
+	algFields := []struct {
+		name   string
+		values []string
+	}{
+		{"resource_signing_alg_values_supported", metadata.ResourceSigningAlgValuesSupported},
+		{"dpop_signing_alg_values_supported", metadata.DPoPSigningAlgValuesSupported},
+	}
+	for _, field := range algFields {
+		for _, alg := range field.values {
+			if alg == "" {
+				return fmt.Errorf("%s cannot contain empty values", field.name)
+			}
+			if alg == "none" {
+				return fmt.Errorf("%s must not include \"none\"", field.name)
+			}
+		}
+	}
+
+	for _, detailsType := range metadata.AuthorizationDetailsTypesSupported {
+		if detailsType == "" {
+			return fmt.Errorf("authorization_details_types_supported cannot contain empty values")
+		}
+	}
+
+	if metadata.DPoPBoundAccessTokensRequired && len(metadata.DPoPSigningAlgValuesSupported) == 0 {
+		return fmt.Errorf("dpop_signing_alg_values_supported is required when dpop_bound_access_tokens_required is set")
+	}
+
+	if metadata.SignedMetadata != "" && strings.Count(metadata.SignedMetadata, ".") != 2 {
+		return fmt.Errorf("signed_metadata must be a JWT in compact serialization")
+	}
+
+	// Validate extension parameter names
+	for name := range metadata.Extensions {
+		if name == "" {
+			return fmt.Errorf("extension parameter name cannot be empty")
+		}
+		if IsRegisteredParameter(name) {
+			return fmt.Errorf("extension parameter %q collides with a registered metadata parameter", name)
+		}
+	}
+
 	return nil
 }
+
+// validateURL checks that a metadata URL is absolute and uses HTTPS
+// (or http://localhost for testing).
+func validateURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return fmt.Errorf("must be an absolute URL: %s", value)
+	}
+	if parsed.Scheme != "https" && !isLocalHTTP(value) {
+		return fmt.Errorf("must use HTTPS (or http://localhost for testing): %s", value)
+	}
+	return nil
+}
+
+// isLocalHTTP reports whether value is an http URL on localhost or
+// 127.0.0.1, the hosts the server configuration accepts for testing.
+func isLocalHTTP(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "http" {
+		return false
+	}
+	return parsed.Hostname() == "localhost" || parsed.Hostname() == "127.0.0.1"
+}
@@ -5,6 +5,7 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -37,12 +38,8 @@ func (s *mockService) GetMetadataURL() string {
 }
 
 // getMetadataURL computes the well-known URL for the protected resource metadata.
-// This matches the implementation in service.go.
 func getMetadataURL(resource string) string {
-	const wellKnownPath = "/.well-known/oauth-protected-resource"
-	// Remove trailing slash (normalizeBaseURL equivalent)
-	normalized := strings.TrimRight(resource, "/")
-	return normalized + wellKnownPath
+	return MetadataURL(resource)
 }
 
 func TestService_GetMetadata(t *testing.T) {
@@ -201,12 +198,12 @@ func TestGetMetadataURL(t *testing.T) {
 		{
 			name:     "with path",
 			resource: "https://example.com/mcp",
-			wantURL:  "https://example.com/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "https://example.com/.well-known/oauth-protected-resource/mcp",
 		},
 		{
 			name:     "with multi-level path",
 			resource: "https://example.com/api/v1/mcp",
-			wantURL:  "https://example.com/api/v1/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "https://example.com/.well-known/oauth-protected-resource/api/v1/mcp",
 		},
 		{
 			name:     "with port no path",
@@ -216,27 +213,32 @@ func TestGetMetadataURL(t *testing.T) {
 		{
 			name:     "with port and path",
 			resource: "https://example.com:8443/mcp",
-			wantURL:  "https://example.com:8443/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "https://example.com:8443/.well-known/oauth-protected-resource/mcp",
 		},
 		{
 			name:     "http scheme",
 			resource: "http://localhost:8080/mcp",
-			wantURL:  "http://localhost:8080/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "http://localhost:8080/.well-known/oauth-protected-resource/mcp",
 		},
 		{
 			name:     "trailing slash removed",
 			resource: "https://example.com/mcp/",
-			wantURL:  "https://example.com/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "https://example.com/.well-known/oauth-protected-resource/mcp",
 		},
 		{
 			name:     "root path only",
 			resource: "https://example.com/",
 			wantURL:  "https://example.com/.well-known/oauth-protected-resource",
 		},
+		{
+			name:     "with query",
+			resource: "https://example.com/mcp?tenant=a",
+			wantURL:  "https://example.com/.well-known/oauth-protected-resource/mcp?tenant=a",
+		},
 		{
 			name:     "subdomain",
 			resource: "https://api.example.com/mcp",
-			wantURL:  "https://api.example.com/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "https://api.example.com/.well-known/oauth-protected-resource/mcp",
 		},
 	}
 
@@ -268,7 +270,7 @@ func TestService_GetMetadataURL(t *testing.T) {
 		{
 			name:     "service with path",
 			resource: "https://example.com/mcp",
-			wantURL:  "https://example.com/mcp/.well-known/oauth-protected-resource",
+			wantURL:  "https://example.com/.well-known/oauth-protected-resource/mcp",
 		},
 	}
 
@@ -430,6 +432,23 @@ func TestValidateMetadata(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid loopback IP URL",
+			metadata: &ProtectedResourceMetadata{
+				Resource:             "http://localhost:8080/mcp",
+				AuthorizationServers: []string{"http://127.0.0.1:9090"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "HTTP host with localhost prefix",
+			metadata: &ProtectedResourceMetadata{
+				Resource:             "https://example.com/mcp",
+				AuthorizationServers: []string{"http://localhost.example.com"},
+			},
+			wantErr:         true,
+			wantErrContains: "HTTPS",
+		},
 		{
 			name: "multiple authorization servers with one invalid",
 			metadata: &ProtectedResourceMetadata{
@@ -447,6 +466,85 @@ func TestValidateMetadata(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "all optional parameters",
+			metadata: &ProtectedResourceMetadata{
+				Resource:                              "https://example.com/mcp",
+				AuthorizationServers:                  []string{"https://auth.example.com"},
+				JWKSURI:                               "https://example.com/jwks.json",
+				ResourceSigningAlgValuesSupported:     []string{"RS256", "ES256"},
+				ResourceName:                          "Example MCP",
+				ResourceDocumentation:                 "https://example.com/docs",
+				ResourcePolicyURI:                     "https://example.com/policy",
+				ResourceTOSURI:                        "https://example.com/tos",
+				TLSClientCertificateBoundAccessTokens: true,
+				AuthorizationDetailsTypesSupported:    []string{"mcp_tool_invocation"},
+				DPoPSigningAlgValuesSupported:         []string{"ES256"},
+				DPoPBoundAccessTokensRequired:         true,
+				Extensions:                            map[string]any{"x_region": "eu"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "jwks_uri without https",
+			metadata: &ProtectedResourceMetadata{
+				Resource:             "https://example.com/mcp",
+				AuthorizationServers: []string{"https://auth.example.com"},
+				JWKSURI:              "http://example.com/jwks.json",
+			},
+			wantErr:         true,
+			wantErrContains: "jwks_uri",
+		},
+		{
+			name: "relative documentation URL",
+			metadata: &ProtectedResourceMetadata{
+				Resource:              "https://example.com/mcp",
+				AuthorizationServers:  []string{"https://auth.example.com"},
+				ResourceDocumentation: "/docs",
+			},
+			wantErr:         true,
+			wantErrContains: "resource_documentation",
+		},
+		{
+			name: "signing algorithm none",
+			metadata: &ProtectedResourceMetadata{
+				Resource:                          "https://example.com/mcp",
+				AuthorizationServers:              []string{"https://auth.example.com"},
+				ResourceSigningAlgValuesSupported: []string{"RS256", "none"},
+			},
+			wantErr:         true,
+			wantErrContains: "resource_signing_alg_values_supported",
+		},
+		{
+			name: "DPoP required without algorithms",
+			metadata: &ProtectedResourceMetadata{
+				Resource:                      "https://example.com/mcp",
+				AuthorizationServers:          []string{"https://auth.example.com"},
+				DPoPBoundAccessTokensRequired: true,
+			},
+			wantErr:         true,
+			wantErrContains: "dpop_signing_alg_values_supported",
+		},
+		{
+			name: "empty authorization details type",
+			metadata: &ProtectedResourceMetadata{
+				Resource:                           "https://example.com/mcp",
+				AuthorizationServers:               []string{"https://auth.example.com"},
+				AuthorizationDetailsTypesSupported: []string{""},
+			},
+			wantErr:         true,
+			wantErrContains: "authorization_details_types_supported",
+		},
+		{
+			name: "extension collides with registered parameter",
+			metadata: &ProtectedResourceMetadata{
+				Resource:             "https://example.com/mcp",
+				AuthorizationServers: []string{"https://auth.example.com"},
+				Extensions:           map[string]any{"resource": "https://evil.example.com"},
+			},
+			wantErr:         true,
+			wantErrContains: "collides",
+		},
 	}
 
 	for _, tt := range tests {
@@ -565,9 +663,131 @@ func TestService_GetMetadataURL_Integration(t *testing.T) {
 	service := NewService("https://example.com/mcp/", []string{"https://auth.example.com"}, nil)
 
 	metadataURL := service.GetMetadataURL()
-	expectedURL := "https://example.com/mcp/.well-known/oauth-protected-resource"
+	expectedURL := "https://example.com/.well-known/oauth-protected-resource/mcp"
 
 	if metadataURL != expectedURL {
 		t.Errorf("GetMetadataURL() = %q, want %q", metadataURL, expectedURL)
 	}
 }
+
+func TestProtectedResourceMetadata_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	metadata := ProtectedResourceMetadata{
+		Resource:                      "https://example.com/mcp",
+		AuthorizationServers:          []string{"https://auth.example.com"},
+		ResourceName:                  "Example MCP",
+		DPoPSigningAlgValuesSupported: []string{"ES256"},
+		Extensions: map[string]any{
+			"x_region": "eu",
+			"resource": "https://evil.example.com",
+		},
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	if got["resource_name"] != "Example MCP" {
+		t.Errorf("resource_name = %v, want %q", got["resource_name"], "Example MCP")
+	}
+	if got["x_region"] != "eu" {
+		t.Errorf("x_region = %v, want %q", got["x_region"], "eu")
+	}
+	if got["resource"] != "https://example.com/mcp" {
+		t.Errorf("resource = %v, extensions must not override registered parameters", got["resource"])
+	}
+	if _, ok := got["dpop_bound_access_tokens_required"]; ok {
+		t.Error("unset boolean parameters should be omitted")
+	}
+	if _, ok := got["Extensions"]; ok {
+		t.Error("Extensions field should not be serialized by name")
+	}
+}
+
+func TestNewServiceWithOptions(t *testing.T) {
+	t.Parallel()
+
+	service := NewServiceWithOptions("https://example.com/mcp", []string{"https://auth.example.com"}, nil, Options{
+		ResourceName:                          "Example MCP",
+		ResourceTOSURI:                        "https://example.com/tos",
+		JWKSURI:                               "https://example.com/jwks.json",
+		TLSClientCertificateBoundAccessTokens: true,
+		AuthorizationDetailsTypesSupported:    []string{"mcp_tool_invocation"},
+		Extensions:                            map[string]any{"x_region": "eu"},
+	})
+
+	metadata, err := service.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+
+	if metadata.ResourceName != "Example MCP" {
+		t.Errorf("ResourceName = %q, want %q", metadata.ResourceName, "Example MCP")
+	}
+	if metadata.ResourceTOSURI != "https://example.com/tos" {
+		t.Errorf("ResourceTOSURI = %q, want %q", metadata.ResourceTOSURI, "https://example.com/tos")
+	}
+	if metadata.JWKSURI != "https://example.com/jwks.json" {
+		t.Errorf("JWKSURI = %q, want %q", metadata.JWKSURI, "https://example.com/jwks.json")
+	}
+	if !metadata.TLSClientCertificateBoundAccessTokens {
+		t.Error("TLSClientCertificateBoundAccessTokens = false, want true")
+	}
+	if len(metadata.AuthorizationDetailsTypesSupported) != 1 {
+		t.Errorf("AuthorizationDetailsTypesSupported = %v, want one type", metadata.AuthorizationDetailsTypesSupported)
+	}
+	if metadata.Extensions["x_region"] != "eu" {
+		t.Errorf("Extensions = %v, want x_region", metadata.Extensions)
+	}
+	if err := ValidateMetadata(metadata); err != nil {
+		t.Errorf("ValidateMetadata() unexpected error: %v", err)
+	}
+}
+
+// scopeList is a ScopeProvider whose scopes can change between calls.
+type scopeList struct {
+	scopes []string
+}
+
+func (s *scopeList) RequiredScopes() []string {
+	return s.scopes
+}
+
+func TestService_DynamicScopes(t *testing.T) {
+	t.Parallel()
+
+	tools := &scopeList{scopes: []string{"mcp:write"}}
+	service := NewServiceWithOptions(
+		"https://example.com/mcp",
+		[]string{"https://auth.example.com"},
+		[]string{"mcp:extra"},
+		Options{ScopeProviders: []ScopeProvider{tools, &scopeList{scopes: []string{"mcp:read", "mcp:write"}}}},
+	)
+
+	metadata, err := service.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	want := []string{"mcp:extra", "mcp:read", "mcp:write"}
+	if strings.Join(metadata.ScopesSupported, " ") != strings.Join(want, " ") {
+		t.Errorf("ScopesSupported = %v, want %v", metadata.ScopesSupported, want)
+	}
+
+	// Scopes declared after construction appear in the next document
+	tools.scopes = []string{"mcp:admin", "mcp:write"}
+	metadata, err = service.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	want = []string{"mcp:admin", "mcp:extra", "mcp:read", "mcp:write"}
+	if strings.Join(metadata.ScopesSupported, " ") != strings.Join(want, " ") {
+		t.Errorf("ScopesSupported = %v, want %v", metadata.ScopesSupported, want)
+	}
+}
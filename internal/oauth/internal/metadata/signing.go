@@ -0,0 +1,349 @@
+package metadata
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is the private key used to sign the metadata document.
+type SigningKey struct {
+	// Key is an *rsa.PrivateKey or *ecdsa.PrivateKey.
+	Key crypto.Signer
+
+	// KeyID is the "kid" of the key. It defaults to the RFC 7638 thumbprint.
+	KeyID string
+
+	// Algorithm is the JWS algorithm derived from the key type.
+	Algorithm string
+}
+
+// JSONWebKey is a public key in JWK format (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys in JWKS format (RFC 7517).
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// privateJWK holds the members of a private RSA or EC key in JWK format.
+type privateJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d"`
+	P   string `json:"p"`
+	Q   string `json:"q"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadSigningKey reads a private key from a PEM file (PKCS#8, PKCS#1 or SEC 1)
+// or a JWK file. RSA keys sign with RS256 and EC keys with ES256, ES384 or
+// ES512 depending on the curve.
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	var key crypto.Signer
+	var kid string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		key, kid, err = parsePrivateJWK(trimmed)
+	} else {
+		key, err = parsePrivatePEM(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigningKey(key, kid)
+}
+
+// NewSigningKey wraps key for metadata signing. An empty kid is replaced by
+// the key's RFC 7638 thumbprint.
+func NewSigningKey(key crypto.Signer, kid string) (*SigningKey, error) {
+	alg, err := signingAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey := &SigningKey{Key: key, KeyID: kid, Algorithm: alg}
+	if signingKey.KeyID == "" {
+		thumbprint, err := signingKey.thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		signingKey.KeyID = thumbprint
+	}
+
+	return signingKey, nil
+}
+
+// PublicJWK returns the public half of the key in JWK format.
+func (k *SigningKey) PublicJWK() JSONWebKey {
+	jwk := JSONWebKey{Kid: k.KeyID, Use: "sig", Alg: k.Algorithm}
+
+	switch pub := k.Key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBase64URL(pub.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encodeBase64URL(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64URL(pub.Y.FillBytes(make([]byte, size)))
+	}
+
+	return jwk
+}
+
+// sign mints a JWT carrying claims, with the key ID in the header.
+func (k *SigningKey) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.KeyID
+	return token.SignedString(k.Key)
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of the public key.
+func (k *SigningKey) thumbprint() (string, error) {
+	jwk := k.PublicJWK()
+
+	// Required members only, in lexicographic order
+	var members any
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	default:
+		return "", fmt.Errorf("unsupported signing key type")
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return encodeBase64URL(sum[:]), nil
+}
+
+// signingAlgorithm selects the JWS algorithm for key.
+func signingAlgorithm(key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return "", fmt.Errorf("RSA signing key must be at least 2048 bits")
+		}
+		return "RS256", nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return "ES256", nil
+		case elliptic.P384():
+			return "ES384", nil
+		case elliptic.P521():
+			return "ES512", nil
+		}
+		return "", fmt.Errorf("unsupported EC curve %s", k.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported signing key type %T", key)
+	}
+}
+
+// parsePrivatePEM decodes the first private key block of a PEM file.
+func parsePrivatePEM(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no private key found in PEM data")
+		}
+
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse PKCS#8 private key: %w", err)
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, fmt.Errorf("unsupported signing key type %T", key)
+			}
+			return signer, nil
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+			}
+			return key, nil
+		case "EC PRIVATE KEY":
+			key, err := x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse EC private key: %w", err)
+			}
+			return key, nil
+		}
+	}
+}
+
+// parsePrivateJWK decodes a private RSA or EC key in JWK format and returns its kid.
+func parsePrivateJWK(data []byte) (crypto.Signer, string, error) {
+	var jwk privateJWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, "", fmt.Errorf("failed to decode JWK: %w", err)
+	}
+	if jwk.D == "" {
+		return nil, "", fmt.Errorf("JWK does not contain a private key")
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		key, err := parseRSAPrivateJWK(&jwk)
+		return key, jwk.Kid, err
+	case "EC":
+		key, err := parseECPrivateJWK(&jwk)
+		return key, jwk.Kid, err
+	default:
+		return nil, "", fmt.Errorf("unsupported JWK key type %q", jwk.Kty)
+	}
+}
+
+// parseRSAPrivateJWK builds an RSA private key from its JWK members.
+func parseRSAPrivateJWK(jwk *privateJWK) (*rsa.PrivateKey, error) {
+	values := make(map[string]*big.Int, 5)
+	for name, encoded := range map[string]string{"n": jwk.N, "e": jwk.E, "d": jwk.D, "p": jwk.P, "q": jwk.Q} {
+		decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) == 0 {
+			return nil, fmt.Errorf("invalid RSA JWK member %q", name)
+		}
+		values[name] = new(big.Int).SetBytes(decoded)
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: values["n"], E: int(values["e"].Int64())},
+		D:         values["d"],
+		Primes:    []*big.Int{values["p"], values["q"]},
+	}
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RSA JWK: %w", err)
+	}
+	key.Precompute()
+
+	return key, nil
+}
+
+// parseECPrivateJWK builds an EC private key from its JWK members.
+func parseECPrivateJWK(jwk *privateJWK) (*ecdsa.PrivateKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", jwk.Crv)
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(jwk.D)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EC JWK member \"d\"")
+	}
+
+	// Derive the public point from d and check it against x and y
+	key := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: curve}, D: new(big.Int).SetBytes(d)}
+	if key.D.Sign() <= 0 || key.D.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid EC JWK: private scalar out of range")
+	}
+	key.X, key.Y = curve.ScalarBaseMult(d)
+	size := (curve.Params().BitSize + 7) / 8
+	if jwk.X != encodeBase64URL(key.X.FillBytes(make([]byte, size))) ||
+		jwk.Y != encodeBase64URL(key.Y.FillBytes(make([]byte, size))) {
+		return nil, fmt.Errorf("invalid EC JWK: public point does not match private key")
+	}
+
+	return key, nil
+}
+
+// encodeBase64URL encodes data as unpadded base64url.
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signedMetadata caches the signed_metadata JWT for the current document.
+// The token is re-minted only when the document changes.
+type signedMetadata struct {
+	key    *SigningKey
+	digest [sha256.Size]byte
+	token  string
+}
+
+// tokenFor returns a JWT over the registered and extension parameters of m.
+// Per RFC 9728 the JWT carries the metadata values as claims, with the
+// resource identifier as the issuer.
+func (s *signedMetadata) tokenFor(m *ProtectedResourceMetadata) (string, error) {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata for signing: %w", err)
+	}
+
+	digest := sha256.Sum256(payload)
+	if s.token != "" && digest == s.digest {
+		return s.token, nil
+	}
+
+	claims := jwt.MapClaims{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("failed to decode metadata for signing: %w", err)
+	}
+	claims["iss"] = m.Resource
+	claims["iat"] = time.Now().Unix()
+
+	token, err := s.key.sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign metadata: %w", err)
+	}
+
+	s.digest = digest
+	s.token = token
+	return token, nil
+}
@@ -0,0 +1,303 @@
+package metadata
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeFile writes data to a file in a temporary directory and returns its path.
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() unexpected error: %v", err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() unexpected error: %v", err)
+	}
+
+	ecJWK, err := json.Marshal(map[string]string{
+		"kty": "EC",
+		"kid": "metadata-key",
+		"crv": "P-384",
+		"x":   encodeBase64URL(ecKey.X.FillBytes(make([]byte, 48))),
+		"y":   encodeBase64URL(ecKey.Y.FillBytes(make([]byte, 48))),
+		"d":   encodeBase64URL(ecKey.D.FillBytes(make([]byte, 48))),
+	})
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	rsaJWK, err := json.Marshal(map[string]string{
+		"kty": "RSA",
+		"n":   encodeBase64URL(rsaKey.N.Bytes()),
+		"e":   "AQAB",
+		"d":   encodeBase64URL(rsaKey.D.Bytes()),
+		"p":   encodeBase64URL(rsaKey.Primes[0].Bytes()),
+		"q":   encodeBase64URL(rsaKey.Primes[1].Bytes()),
+	})
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantAlg string
+		wantKid string
+		wantErr bool
+	}{
+		{
+			name:    "PKCS#8 RSA",
+			data:    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+			wantAlg: "RS256",
+		},
+		{
+			name:    "PKCS#1 RSA",
+			data:    pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+			wantAlg: "RS256",
+		},
+		{
+			name:    "SEC 1 EC",
+			data:    pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}),
+			wantAlg: "ES384",
+		},
+		{
+			name:    "EC JWK with kid",
+			data:    ecJWK,
+			wantAlg: "ES384",
+			wantKid: "metadata-key",
+		},
+		{
+			name:    "RSA JWK",
+			data:    rsaJWK,
+			wantAlg: "RS256",
+		},
+		{
+			name:    "RSA key too small",
+			data:    pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(smallKey)}),
+			wantErr: true,
+		},
+		{
+			name:    "public JWK",
+			data:    []byte(`{"kty":"EC","crv":"P-256","x":"AA","y":"AA"}`),
+			wantErr: true,
+		},
+		{
+			name:    "no PEM block",
+			data:    []byte("not a key"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := LoadSigningKey(writeFile(t, "key", tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadSigningKey() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSigningKey() unexpected error: %v", err)
+			}
+
+			if key.Algorithm != tt.wantAlg {
+				t.Errorf("Algorithm = %q, want %q", key.Algorithm, tt.wantAlg)
+			}
+			if tt.wantKid != "" && key.KeyID != tt.wantKid {
+				t.Errorf("KeyID = %q, want %q", key.KeyID, tt.wantKid)
+			}
+			if key.KeyID == "" {
+				t.Error("KeyID should default to the key thumbprint")
+			}
+		})
+	}
+}
+
+func TestSigningKey_ThumbprintIsStable(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	first, err := NewSigningKey(key, "")
+	if err != nil {
+		t.Fatalf("NewSigningKey() unexpected error: %v", err)
+	}
+	second, err := NewSigningKey(key, "")
+	if err != nil {
+		t.Fatalf("NewSigningKey() unexpected error: %v", err)
+	}
+
+	if first.KeyID != second.KeyID {
+		t.Errorf("thumbprints differ: %q vs %q", first.KeyID, second.KeyID)
+	}
+	if jwk := first.PublicJWK(); jwk.Kty != "EC" || jwk.Crv != "P-256" || jwk.Kid != first.KeyID {
+		t.Errorf("PublicJWK() = %+v, want EC P-256 key with kid %q", jwk, first.KeyID)
+	}
+}
+
+func TestService_SignedMetadata(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	signingKey, err := NewSigningKey(ecKey, "")
+	if err != nil {
+		t.Fatalf("NewSigningKey() unexpected error: %v", err)
+	}
+
+	service := NewServiceWithOptions(
+		"https://example.com/mcp",
+		[]string{"https://auth.example.com"},
+		[]string{"mcp:read"},
+		Options{
+			JWKSURI:    "https://example.com/.well-known/jwks.json",
+			SigningKey: signingKey,
+			Extensions: map[string]any{"x_region": "eu"},
+		},
+	)
+
+	metadata, err := service.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	if metadata.SignedMetadata == "" {
+		t.Fatal("GetMetadata() should include signed_metadata")
+	}
+	if err := ValidateMetadata(metadata); err != nil {
+		t.Errorf("ValidateMetadata() unexpected error: %v", err)
+	}
+
+	// The JWT verifies with the published key and carries the metadata values
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(metadata.SignedMetadata, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["kid"] != signingKey.KeyID {
+			t.Errorf("kid = %v, want %q", token.Header["kid"], signingKey.KeyID)
+		}
+		return &ecKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil || !token.Valid {
+		t.Fatalf("signed_metadata did not verify: %v", err)
+	}
+	if claims["iss"] != "https://example.com/mcp" {
+		t.Errorf("iss = %v, want the resource identifier", claims["iss"])
+	}
+	if claims["resource"] != "https://example.com/mcp" {
+		t.Errorf("resource = %v, want %q", claims["resource"], "https://example.com/mcp")
+	}
+	if servers, ok := claims["authorization_servers"].([]any); !ok || len(servers) != 1 || servers[0] != "https://auth.example.com" {
+		t.Errorf("authorization_servers = %v, want [https://auth.example.com]", claims["authorization_servers"])
+	}
+	if claims["x_region"] != "eu" {
+		t.Errorf("x_region = %v, want %q", claims["x_region"], "eu")
+	}
+
+	// Unchanged metadata reuses the cached JWT
+	again, err := service.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	if again.SignedMetadata != metadata.SignedMetadata {
+		t.Error("signed_metadata should be cached while metadata is unchanged")
+	}
+
+	// The published key set contains the signing key
+	jwks := service.JWKS()
+	if jwks == nil || len(jwks.Keys) != 1 || jwks.Keys[0].Kid != signingKey.KeyID {
+		t.Errorf("JWKS() = %+v, want the signing key", jwks)
+	}
+}
+
+func TestSignedMetadata_ReMintsOnChange(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	signingKey, err := NewSigningKey(ecKey, "metadata-key")
+	if err != nil {
+		t.Fatalf("NewSigningKey() unexpected error: %v", err)
+	}
+
+	signed := &signedMetadata{key: signingKey}
+	metadata := &ProtectedResourceMetadata{
+		Resource:             "https://example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+	}
+
+	first, err := signed.tokenFor(metadata)
+	if err != nil {
+		t.Fatalf("tokenFor() unexpected error: %v", err)
+	}
+
+	metadata.AuthorizationServers = []string{"https://auth2.example.com"}
+	second, err := signed.tokenFor(metadata)
+	if err != nil {
+		t.Fatalf("tokenFor() unexpected error: %v", err)
+	}
+	if first == second {
+		t.Error("tokenFor() should mint a new JWT when metadata changes")
+	}
+}
+
+func TestService_UnsignedMetadata(t *testing.T) {
+	t.Parallel()
+
+	service := NewService("https://example.com/mcp", []string{"https://auth.example.com"}, nil)
+
+	metadata, err := service.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	if metadata.SignedMetadata != "" {
+		t.Error("signed_metadata should be omitted without a signing key")
+	}
+	if service.JWKS() != nil {
+		t.Error("JWKS() should be nil without a signing key")
+	}
+}
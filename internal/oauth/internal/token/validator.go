@@ -26,6 +26,7 @@ type TokenClaims struct {
 	ExpiresAt time.Time
 	IssuedAt  time.Time
 	JTI       string
+	ClientID  string
 }
 
 // HasScope returns true if the token has the specified scope.
@@ -224,6 +225,13 @@ func (v *Validator) extractClaims(mapClaims jwt.MapClaims) (*TokenClaims, error)
 		claims.JTI = jti
 	}
 
+	// Extract client ID (optional): client_id per RFC 9068, or azp
+	if clientID, ok := mapClaims["client_id"].(string); ok && clientID != "" {
+		claims.ClientID = clientID
+	} else if azp, ok := mapClaims["azp"].(string); ok {
+		claims.ClientID = azp
+	}
+
 	// Extract scopes (optional but important for OAuth)
 	if scopeStr, ok := mapClaims["scope"].(string); ok {
 		claims.Scopes = parseScopes(scopeStr)
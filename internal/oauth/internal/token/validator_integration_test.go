@@ -667,6 +667,55 @@ func TestValidator_ValidateToken_WithoutOptionalClaims(t *testing.T) {
 	}
 }
 
+func TestValidator_ValidateToken_ClientID(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	jwksClient := newMockJWKSClient()
+	jwksClient.addKey("test-key-1", &privateKey.PublicKey)
+
+	validator := NewValidator(jwksClient, "https://api.example.com", 5*time.Minute)
+
+	tests := []struct {
+		name   string
+		extra  jwt.MapClaims
+		wantID string
+	}{
+		{name: "client_id claim", extra: jwt.MapClaims{"client_id": "client-a"}, wantID: "client-a"},
+		{name: "azp claim", extra: jwt.MapClaims{"azp": "client-b"}, wantID: "client-b"},
+		{name: "client_id preferred over azp", extra: jwt.MapClaims{"client_id": "client-a", "azp": "client-b"}, wantID: "client-a"},
+		{name: "no client claims", extra: nil, wantID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			claims := jwt.MapClaims{
+				"sub": "user123",
+				"iss": "https://auth.example.com",
+				"aud": []string{"https://api.example.com"},
+				"exp": time.Now().Add(1 * time.Hour).Unix(),
+			}
+			for name, value := range tt.extra {
+				claims[name] = value
+			}
+
+			result, err := validator.ValidateToken(context.Background(), createSignedToken(t, privateKey, "test-key-1", claims))
+			if err != nil {
+				t.Fatalf("ValidateToken() unexpected error: %v", err)
+			}
+			if result.ClientID != tt.wantID {
+				t.Errorf("ClientID = %q, want %q", result.ClientID, tt.wantID)
+			}
+		})
+	}
+}
+
 func TestParseScopes(t *testing.T) {
 	t.Parallel()
 
@@ -5,6 +5,8 @@ package oauth
 import (
 	"context"
 	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/metadata"
 )
 
 // TokenValidator validates OAuth 2.1 access tokens.
@@ -45,6 +47,10 @@ type TokenClaims struct {
 
 	// JTI is the JWT ID (jti) claim - a unique identifier for this token.
 	JTI string
+
+	// ClientID is the OAuth client the token was issued to, from the
+	// client_id claim (RFC 9068) or, failing that, the azp claim.
+	ClientID string
 }
 
 // HasScope returns true if the token has the specified scope.
@@ -98,7 +104,8 @@ type MetadataService interface {
 	GetMetadata(ctx context.Context) (*ProtectedResourceMetadata, error)
 
 	// GetMetadataURL returns the canonical URL where this metadata is served.
-	// Typically: {baseURL}/.well-known/oauth-protected-resource
+	// Per RFC 9728 the well-known path is inserted before the resource path:
+	// https://host/mcp is described at https://host/.well-known/oauth-protected-resource/mcp
 	GetMetadataURL() string
 }
 
@@ -121,6 +128,57 @@ type ProtectedResourceMetadata struct {
 	// BearerMethodsSupported indicates supported methods for presenting
 	// bearer tokens. OAuth 2.1 requires "header" (Authorization header only).
 	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+
+	// JWKSURI is the URL of this resource's own JSON Web Key Set, used to
+	// verify responses or metadata signed by the resource.
+	JWKSURI string `json:"jwks_uri,omitempty"`
+
+	// ResourceSigningAlgValuesSupported lists the JWS algorithms this
+	// resource uses to sign its responses.
+	ResourceSigningAlgValuesSupported []string `json:"resource_signing_alg_values_supported,omitempty"`
+
+	// ResourceName is a human-readable name of the protected resource.
+	ResourceName string `json:"resource_name,omitempty"`
+
+	// ResourceDocumentation is a URL of developer documentation for the resource.
+	ResourceDocumentation string `json:"resource_documentation,omitempty"`
+
+	// ResourcePolicyURI is a URL describing how client data is used.
+	ResourcePolicyURI string `json:"resource_policy_uri,omitempty"`
+
+	// ResourceTOSURI is a URL of the resource's terms of service.
+	ResourceTOSURI string `json:"resource_tos_uri,omitempty"`
+
+	// TLSClientCertificateBoundAccessTokens indicates support for mutual-TLS
+	// certificate-bound access tokens (RFC 8705).
+	TLSClientCertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+
+	// AuthorizationDetailsTypesSupported lists the authorization details
+	// types accepted by this resource (RFC 9396).
+	AuthorizationDetailsTypesSupported []string `json:"authorization_details_types_supported,omitempty"`
+
+	// DPoPSigningAlgValuesSupported lists the JWS algorithms accepted for
+	// DPoP proof JWTs (RFC 9449).
+	DPoPSigningAlgValuesSupported []string `json:"dpop_signing_alg_values_supported,omitempty"`
+
+	// DPoPBoundAccessTokensRequired indicates that DPoP-bound access tokens
+	// are always required (RFC 9449).
+	DPoPBoundAccessTokensRequired bool `json:"dpop_bound_access_tokens_required,omitempty"`
+
+	// SignedMetadata is a JWT over the metadata values signed by the
+	// resource's key, so clients can detect tampering by intermediaries.
+	// Its claims take precedence over the plain values.
+	SignedMetadata string `json:"signed_metadata,omitempty"`
+
+	// Extensions holds additional metadata parameters. They are serialized
+	// at the top level of the document alongside the registered parameters.
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON encodes the metadata with its extension parameters inlined.
+func (m ProtectedResourceMetadata) MarshalJSON() ([]byte, error) {
+	type plain ProtectedResourceMetadata
+	return metadata.MarshalWithExtensions(plain(m), m.Extensions)
 }
 
 // JWKSClient fetches and caches JSON Web Key Sets (JWKS) from authorization servers.
@@ -138,9 +196,46 @@ type JWKSClient interface {
 	// RefreshKeys forces a refresh of the JWKS cache from all configured
 	// authorization servers. This is useful after receiving an "invalid_token"
 	// error that might be due to key rotation.
+	//
+	// Keys are cached per authorization server, and a server's keys are only
+	// replaced when its new key set is fetched successfully. If any server
+	// fails, the returned error is a *JWKSRefreshError listing each failure.
 	RefreshKeys(ctx context.Context) error
 }
 
+// JWKSInspector exposes the JWKS state of each authorization server for
+// operators. The client returned by NewJWKSClient implements it.
+type JWKSInspector interface {
+	// Status returns the key set state of every configured authorization
+	// server: keys, fetch and expiry times, discovered jwks_uri and the
+	// last fetch error.
+	Status() []JWKSIssuerStatus
+
+	// RefreshIssuer re-fetches the key set of one authorization server
+	// without affecting the cached keys of the others.
+	RefreshIssuer(ctx context.Context, issuer string) error
+}
+
+// AuthorizationServerDirectory exposes the RFC 8414 metadata of each trusted
+// authorization server, as downloaded for JWKS discovery. The client
+// returned by NewJWKSClient implements it.
+type AuthorizationServerDirectory interface {
+	// ServerMetadata returns the cached metadata document of every
+	// configured authorization server with its health and capabilities.
+	// Servers not yet discovered are fetched first, with failed attempts
+	// retried no more than once a minute.
+	ServerMetadata(ctx context.Context) []AuthorizationServerStatus
+}
+
+// ResourceKeySet exposes the public keys the protected resource signs with,
+// to be served at its jwks_uri. The service returned by NewMetadataService
+// implements it.
+type ResourceKeySet interface {
+	// JWKS returns the resource's public key set, or nil when the resource
+	// has no signing key.
+	JWKS() *JSONWebKeySet
+}
+
 // ScopeChecker validates token scopes against required scopes.
 // It provides methods for both "all required" and "any required" scope checks,
 // returning appropriate OAuth errors per RFC 6750.
@@ -80,3 +80,10 @@ func NewInvalidMetadataError(op string, serverURL string, err error) *ierrors.Do
 	return ierrors.New(domainOAuth, op, ierrors.ErrInternal, fmt.Errorf("invalid metadata: %v", err)).
 		WithContext("authorization_server", serverURL)
 }
+
+// NewUnknownAuthorizationServerError creates a DomainError for an authorization
+// server that is not in the configured list.
+func NewUnknownAuthorizationServerError(op string, serverURL string) *ierrors.DomainError {
+	return ierrors.New(domainOAuth, op, ierrors.ErrNotFound, fmt.Errorf("authorization server is not configured")).
+		WithContext("authorization_server", serverURL)
+}
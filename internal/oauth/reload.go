@@ -0,0 +1,245 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Services is the set of OAuth services built from one configuration.
+type Services struct {
+	// TokenValidator validates access tokens.
+	TokenValidator TokenValidator
+
+	// MetadataService describes the primary protected resource.
+	MetadataService MetadataService
+
+	// ResourceMetadataServices describe additional protected resources.
+	ResourceMetadataServices []MetadataService
+
+	// ResourceTokenValidators validate the access tokens of the additional
+	// protected resources, in the order of ResourceMetadataServices.
+	ResourceTokenValidators []TokenValidator
+
+	// JWKSClient fetches the key sets of the authorization servers.
+	JWKSClient JWKSClient
+}
+
+// ReloadableServices holds a set of OAuth services that can be replaced
+// while the server runs. The services returned by its accessors delegate
+// every call to the set current at the time of the call, so they can be
+// wired into handlers once and follow each configuration reload.
+type ReloadableServices struct {
+	current atomic.Pointer[Services]
+}
+
+// NewReloadableServices creates reloadable services serving services.
+// Panics if services or one of its services is nil.
+func NewReloadableServices(services *Services) *ReloadableServices {
+	if err := checkServices(services); err != nil {
+		panic(err.Error())
+	}
+	r := &ReloadableServices{}
+	r.current.Store(services)
+	return r
+}
+
+// Current returns the services in use.
+func (r *ReloadableServices) Current() *Services {
+	return r.current.Load()
+}
+
+// Swap atomically replaces the services in use. Metadata documents are
+// routed by their URL, so every metadata service must keep the URL of the
+// service it replaces; otherwise Swap returns an error and keeps the
+// current services.
+func (r *ReloadableServices) Swap(services *Services) error {
+	if err := checkServices(services); err != nil {
+		return err
+	}
+
+	current := r.current.Load()
+	if len(services.ResourceMetadataServices) != len(current.ResourceMetadataServices) {
+		return fmt.Errorf("number of protected resources changed from %d to %d",
+			len(current.ResourceMetadataServices), len(services.ResourceMetadataServices))
+	}
+	if err := checkMetadataURL(current.MetadataService, services.MetadataService); err != nil {
+		return err
+	}
+	for i, service := range services.ResourceMetadataServices {
+		if err := checkMetadataURL(current.ResourceMetadataServices[i], service); err != nil {
+			return err
+		}
+	}
+
+	r.current.Store(services)
+	return nil
+}
+
+// TokenValidator returns the primary protected resource token validator,
+// delegating to the current services.
+func (r *ReloadableServices) TokenValidator() TokenValidator {
+	return reloadableValidator{services: r, index: -1}
+}
+
+// ResourceTokenValidators returns the token validators of the additional
+// protected resources, delegating to the current services.
+func (r *ReloadableServices) ResourceTokenValidators() []TokenValidator {
+	current := r.current.Load()
+	validators := make([]TokenValidator, len(current.ResourceTokenValidators))
+	for i := range validators {
+		validators[i] = reloadableValidator{services: r, index: i}
+	}
+	return validators
+}
+
+// MetadataService returns the primary protected resource metadata service,
+// delegating to the current services. It implements ResourceKeySet.
+func (r *ReloadableServices) MetadataService() MetadataService {
+	return &reloadableMetadata{services: r, index: -1}
+}
+
+// ResourceMetadataServices returns the metadata services of the additional
+// protected resources, delegating to the current services.
+func (r *ReloadableServices) ResourceMetadataServices() []MetadataService {
+	current := r.current.Load()
+	services := make([]MetadataService, len(current.ResourceMetadataServices))
+	for i := range services {
+		services[i] = &reloadableMetadata{services: r, index: i}
+	}
+	return services
+}
+
+// JWKSClient returns a JWKS client delegating to the current services. It
+// implements JWKSInspector and AuthorizationServerDirectory.
+func (r *ReloadableServices) JWKSClient() JWKSClient {
+	return reloadableJWKS{r}
+}
+
+// checkServices verifies that no service of the set is nil.
+func checkServices(services *Services) error {
+	if services == nil {
+		return fmt.Errorf("services cannot be nil")
+	}
+	if services.TokenValidator == nil {
+		return fmt.Errorf("token validator cannot be nil")
+	}
+	if services.MetadataService == nil {
+		return fmt.Errorf("metadata service cannot be nil")
+	}
+	if services.JWKSClient == nil {
+		return fmt.Errorf("jwks client cannot be nil")
+	}
+	for _, service := range services.ResourceMetadataServices {
+		if service == nil {
+			return fmt.Errorf("resource metadata service cannot be nil")
+		}
+	}
+	if len(services.ResourceTokenValidators) != len(services.ResourceMetadataServices) {
+		return fmt.Errorf("got %d resource token validators for %d protected resources",
+			len(services.ResourceTokenValidators), len(services.ResourceMetadataServices))
+	}
+	for _, validator := range services.ResourceTokenValidators {
+		if validator == nil {
+			return fmt.Errorf("resource token validator cannot be nil")
+		}
+	}
+	return nil
+}
+
+// checkMetadataURL verifies that a replacement metadata service is served
+// at the same URL.
+func checkMetadataURL(current, replacement MetadataService) error {
+	if current.GetMetadataURL() != replacement.GetMetadataURL() {
+		return fmt.Errorf("metadata URL changed from %s to %s",
+			current.GetMetadataURL(), replacement.GetMetadataURL())
+	}
+	return nil
+}
+
+// reloadableValidator delegates to the current primary token validator, or
+// to the additional one at index when index is not negative.
+type reloadableValidator struct {
+	services *ReloadableServices
+	index    int
+}
+
+func (v reloadableValidator) ValidateToken(ctx context.Context, token string) (*TokenClaims, error) {
+	current := v.services.Current()
+	if v.index < 0 {
+		return current.TokenValidator.ValidateToken(ctx, token)
+	}
+	return current.ResourceTokenValidators[v.index].ValidateToken(ctx, token)
+}
+
+// reloadableMetadata delegates to the current primary metadata service, or
+// to the additional one at index when index is not negative.
+type reloadableMetadata struct {
+	services *ReloadableServices
+	index    int
+}
+
+func (m *reloadableMetadata) service() MetadataService {
+	current := m.services.Current()
+	if m.index < 0 {
+		return current.MetadataService
+	}
+	return current.ResourceMetadataServices[m.index]
+}
+
+func (m *reloadableMetadata) GetMetadata(ctx context.Context) (*ProtectedResourceMetadata, error) {
+	return m.service().GetMetadata(ctx)
+}
+
+func (m *reloadableMetadata) GetMetadataURL() string {
+	return m.service().GetMetadataURL()
+}
+
+func (m *reloadableMetadata) JWKS() *JSONWebKeySet {
+	keySet, ok := m.service().(ResourceKeySet)
+	if !ok {
+		return nil
+	}
+	return keySet.JWKS()
+}
+
+// reloadableJWKS delegates to the current JWKS client.
+type reloadableJWKS struct {
+	services *ReloadableServices
+}
+
+func (c reloadableJWKS) client() JWKSClient {
+	return c.services.Current().JWKSClient
+}
+
+func (c reloadableJWKS) GetKey(ctx context.Context, keyID string) (any, error) {
+	return c.client().GetKey(ctx, keyID)
+}
+
+func (c reloadableJWKS) RefreshKeys(ctx context.Context) error {
+	return c.client().RefreshKeys(ctx)
+}
+
+func (c reloadableJWKS) Status() []JWKSIssuerStatus {
+	inspector, ok := c.client().(JWKSInspector)
+	if !ok {
+		return nil
+	}
+	return inspector.Status()
+}
+
+func (c reloadableJWKS) RefreshIssuer(ctx context.Context, issuer string) error {
+	inspector, ok := c.client().(JWKSInspector)
+	if !ok {
+		return fmt.Errorf("jwks client does not support inspection")
+	}
+	return inspector.RefreshIssuer(ctx, issuer)
+}
+
+func (c reloadableJWKS) ServerMetadata(ctx context.Context) []AuthorizationServerStatus {
+	directory, ok := c.client().(AuthorizationServerDirectory)
+	if !ok {
+		return nil
+	}
+	return directory.ServerMetadata(ctx)
+}
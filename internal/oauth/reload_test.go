@@ -0,0 +1,150 @@
+package oauth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServices builds services for audience with the given additional
+// protected resources.
+func testServices(audience string, resources ...string) *Services {
+	cfg := &Config{
+		BaseURL:              "https://example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+		Audience:             audience,
+		JWKSCacheTTL:         5 * time.Minute,
+	}
+	validator, metadataService, _, jwksClient := NewOAuthServices(cfg)
+
+	services := &Services{
+		TokenValidator:  validator,
+		MetadataService: metadataService,
+		JWKSClient:      jwksClient,
+	}
+	for _, resource := range resources {
+		resourceCfg := *cfg
+		resourceCfg.BaseURL = resource
+		resourceCfg.Audience = resource
+		services.ResourceMetadataServices = append(services.ResourceMetadataServices, NewMetadataService(&resourceCfg))
+		services.ResourceTokenValidators = append(services.ResourceTokenValidators, NewTokenValidator(&resourceCfg, jwksClient))
+	}
+	return services
+}
+
+func TestReloadableServices_Swap(t *testing.T) {
+	t.Parallel()
+
+	first := testServices("https://example.com/mcp", "https://example.com/reports")
+	reloadable := NewReloadableServices(first)
+
+	metadataService := reloadable.MetadataService()
+	resourceServices := reloadable.ResourceMetadataServices()
+	resourceValidators := reloadable.ResourceTokenValidators()
+	jwksClient := reloadable.JWKSClient()
+
+	second := testServices("https://example.com/mcp", "https://example.com/reports")
+	second.MetadataService = NewMetadataService(&Config{
+		BaseURL:              "https://example.com/mcp",
+		AuthorizationServers: []string{"https://login.example.com"},
+	})
+	if err := reloadable.Swap(second); err != nil {
+		t.Fatalf("Swap() error = %v", err)
+	}
+	if reloadable.Current() != second {
+		t.Error("Current() did not return the swapped services")
+	}
+
+	// Views obtained before the swap follow it
+	metadata, err := metadataService.GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() error = %v", err)
+	}
+	if len(metadata.AuthorizationServers) != 1 || metadata.AuthorizationServers[0] != "https://login.example.com" {
+		t.Errorf("AuthorizationServers = %v, want the swapped service's", metadata.AuthorizationServers)
+	}
+	if len(resourceServices) != 1 || resourceServices[0].GetMetadataURL() != second.ResourceMetadataServices[0].GetMetadataURL() {
+		t.Errorf("ResourceMetadataServices() = %v", resourceServices)
+	}
+	if len(resourceValidators) != 1 {
+		t.Errorf("ResourceTokenValidators() returned %d validators, want 1", len(resourceValidators))
+	}
+	if _, ok := jwksClient.(JWKSInspector); !ok {
+		t.Error("JWKSClient() does not implement JWKSInspector")
+	}
+	if _, ok := jwksClient.(AuthorizationServerDirectory); !ok {
+		t.Error("JWKSClient() does not implement AuthorizationServerDirectory")
+	}
+	if _, ok := metadataService.(ResourceKeySet); !ok {
+		t.Error("MetadataService() does not implement ResourceKeySet")
+	}
+}
+
+func TestReloadableServices_SwapErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		services    *Services
+		errContains string
+	}{
+		{
+			name:        "nil services",
+			services:    nil,
+			errContains: "services cannot be nil",
+		},
+		{
+			name:        "nil validator",
+			services:    &Services{MetadataService: testServices("a").MetadataService, JWKSClient: testServices("a").JWKSClient},
+			errContains: "token validator cannot be nil",
+		},
+		{
+			name: "resource without validator",
+			services: func() *Services {
+				services := testServices("https://example.com/mcp", "https://example.com/reports")
+				services.ResourceTokenValidators = nil
+				return services
+			}(),
+			errContains: "got 0 resource token validators for 1 protected resources",
+		},
+		{
+			name:        "resource added",
+			services:    testServices("https://example.com/mcp", "https://example.com/reports", "https://example.com/admin"),
+			errContains: "number of protected resources changed",
+		},
+		{
+			name:        "resource moved",
+			services:    testServices("https://example.com/mcp", "https://example.com/billing"),
+			errContains: "metadata URL changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			original := testServices("https://example.com/mcp", "https://example.com/reports")
+			reloadable := NewReloadableServices(original)
+
+			err := reloadable.Swap(tt.services)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Swap() error = %v, want error containing %q", err, tt.errContains)
+			}
+			if reloadable.Current() != original {
+				t.Error("failed Swap() replaced the current services")
+			}
+		})
+	}
+}
+
+func TestNewReloadableServices_NilPanics(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewReloadableServices(nil) did not panic")
+		}
+	}()
+	NewReloadableServices(nil)
+}
@@ -2,9 +2,12 @@ package oauth
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/devserver"
 	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/jwks"
 	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/metadata"
 	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/internal/token"
@@ -29,6 +32,7 @@ func (a *tokenValidatorAdapter) ValidateToken(ctx context.Context, tokenString s
 		ExpiresAt: claims.ExpiresAt,
 		IssuedAt:  claims.IssuedAt,
 		JTI:       claims.JTI,
+		ClientID:  claims.ClientID,
 	}, nil
 }
 
@@ -44,10 +48,22 @@ func (a *metadataServiceAdapter) GetMetadata(ctx context.Context) (*ProtectedRes
 	}
 	// Convert metadata.ProtectedResourceMetadata to oauth.ProtectedResourceMetadata
 	return &ProtectedResourceMetadata{
-		Resource:               meta.Resource,
-		AuthorizationServers:   meta.AuthorizationServers,
-		ScopesSupported:        meta.ScopesSupported,
-		BearerMethodsSupported: meta.BearerMethodsSupported,
+		Resource:                              meta.Resource,
+		AuthorizationServers:                  meta.AuthorizationServers,
+		ScopesSupported:                       meta.ScopesSupported,
+		BearerMethodsSupported:                meta.BearerMethodsSupported,
+		JWKSURI:                               meta.JWKSURI,
+		ResourceSigningAlgValuesSupported:     meta.ResourceSigningAlgValuesSupported,
+		ResourceName:                          meta.ResourceName,
+		ResourceDocumentation:                 meta.ResourceDocumentation,
+		ResourcePolicyURI:                     meta.ResourcePolicyURI,
+		ResourceTOSURI:                        meta.ResourceTOSURI,
+		TLSClientCertificateBoundAccessTokens: meta.TLSClientCertificateBoundAccessTokens,
+		AuthorizationDetailsTypesSupported:    meta.AuthorizationDetailsTypesSupported,
+		DPoPSigningAlgValuesSupported:         meta.DPoPSigningAlgValuesSupported,
+		DPoPBoundAccessTokensRequired:         meta.DPoPBoundAccessTokensRequired,
+		SignedMetadata:                        meta.SignedMetadata,
+		Extensions:                            meta.Extensions,
 	}, nil
 }
 
@@ -55,6 +71,10 @@ func (a *metadataServiceAdapter) GetMetadataURL() string {
 	return a.service.GetMetadataURL()
 }
 
+func (a *metadataServiceAdapter) JWKS() *JSONWebKeySet {
+	return a.service.JWKS()
+}
+
 // scopeCheckerAdapter adapts token.ScopeChecker to oauth.ScopeChecker interface.
 type scopeCheckerAdapter struct {
 	checker *token.ScopeChecker
@@ -73,6 +93,7 @@ func (a *scopeCheckerAdapter) RequireScopes(claims *TokenClaims, required ...str
 		ExpiresAt: claims.ExpiresAt,
 		IssuedAt:  claims.IssuedAt,
 		JTI:       claims.JTI,
+		ClientID:  claims.ClientID,
 	}
 	return a.checker.RequireScopes(tokenClaims, required...)
 }
@@ -90,6 +111,7 @@ func (a *scopeCheckerAdapter) RequireAnyScope(claims *TokenClaims, scopes ...str
 		ExpiresAt: claims.ExpiresAt,
 		IssuedAt:  claims.IssuedAt,
 		JTI:       claims.JTI,
+		ClientID:  claims.ClientID,
 	}
 	return a.checker.RequireAnyScope(tokenClaims, scopes...)
 }
@@ -106,6 +128,7 @@ type Config struct {
 	Audience string
 
 	// ScopesSupported is a list of OAuth scopes this server supports.
+	// It is merged with the scopes of ResourceMetadata.ScopeProviders.
 	ScopesSupported []string
 
 	// JWKSCacheTTL is how long to cache JWKS keys.
@@ -113,13 +136,143 @@ type Config struct {
 
 	// ClockSkew is the allowed clock skew for token expiration validation.
 	ClockSkew time.Duration
+
+	// X5CRoots is the CA pool used to verify x5c certificate chains on JWKS keys.
+	// Setting it implies RequireX5C. When nil, chains are still matched against
+	// the key but not verified to a trust anchor.
+	X5CRoots *x509.CertPool
+
+	// RequireX5C drops JWKS keys that do not carry an x5c certificate chain.
+	// It is implied by X5CRoots.
+	RequireX5C bool
+
+	// HTTPClient configures the outbound HTTP client used for discovery and
+	// JWKS fetches. A nil value selects hardened defaults.
+	HTTPClient *HTTPClientConfig
+
+	// AuthorizationServerHTTP overrides HTTPClient for individual
+	// authorization servers, keyed by server URL.
+	AuthorizationServerHTTP map[string]*HTTPClientConfig
+
+	// JWKSCacheFile persists the last good key sets to disk so tokens can be
+	// validated before the first successful fetch after a restart (optional).
+	JWKSCacheFile string
+
+	// JWKSCacheKey authenticates the persisted key sets with HMAC-SHA256.
+	JWKSCacheKey []byte
+
+	// JWKSCacheMaxStale is how long persisted keys remain usable after they were fetched.
+	JWKSCacheMaxStale time.Duration
+
+	// ResourceMetadata holds the optional RFC 9728 parameters advertised in
+	// the protected resource metadata document.
+	ResourceMetadata ResourceMetadataOptions
 }
 
+// ResourceMetadataOptions holds the optional RFC 9728 protected resource
+// metadata parameters: descriptive URLs, signing and DPoP algorithms,
+// certificate binding, authorization details types and extensions.
+type ResourceMetadataOptions = metadata.Options
+
+// MetadataSigningKey is the private key that signs the protected resource
+// metadata into its signed_metadata parameter.
+type MetadataSigningKey = metadata.SigningKey
+
+// JSONWebKeySet is a set of public keys in JWKS format (RFC 7517).
+type JSONWebKeySet = metadata.JSONWebKeySet
+
+// JSONWebKey is a public key in JWK format (RFC 7517).
+type JSONWebKey = metadata.JSONWebKey
+
+// ScopeProvider reports the OAuth scopes a component of the server enforces,
+// such as the MCP tool and resource registries. Providers are listed in
+// ResourceMetadataOptions.ScopeProviders to derive scopes_supported.
+type ScopeProvider = metadata.ScopeProvider
+
+// StaticScopes is a ScopeProvider for a fixed list of scopes, such as the
+// defaults enforced by the authentication middleware.
+type StaticScopes []string
+
+// RequiredScopes returns the scopes in the list.
+func (s StaticScopes) RequiredScopes() []string {
+	return s
+}
+
+// HTTPClientConfig controls the outbound HTTP client used for authorization
+// server discovery and JWKS fetches: trust store, client certificates, proxy,
+// timeout, response size cap, redirect policy, SSRF protection and User-Agent.
+type HTTPClientConfig = jwks.HTTPConfig
+
+// JWKSIssuerStatus describes the JWKS state of one authorization server.
+type JWKSIssuerStatus = jwks.IssuerStatus
+
+// JWKSKeyStatus describes one key published by an authorization server.
+type JWKSKeyStatus = jwks.KeyStatus
+
+// AuthorizationServerStatus describes the cached RFC 8414 metadata of one
+// authorization server.
+type AuthorizationServerStatus = jwks.ServerMetadataStatus
+
+// AuthorizationServerCapabilities summarizes the features an authorization
+// server advertises.
+type AuthorizationServerCapabilities = jwks.ServerCapabilities
+
+// DevServerConfig configures the embedded development authorization server.
+type DevServerConfig = devserver.Config
+
+// JWKSRefreshError reports, per authorization server, the failures of a
+// JWKSClient.RefreshKeys call.
+type JWKSRefreshError = jwks.RefreshError
+
 // NewJWKSClient creates a new JWKS client with the provided configuration.
 // The client will fetch JWKS from the configured authorization servers
 // and cache keys for the specified TTL.
 func NewJWKSClient(cfg *Config) JWKSClient {
-	return jwks.NewClient(cfg.AuthorizationServers, cfg.JWKSCacheTTL)
+	clientCfg := &jwks.ClientConfig{
+		ServerURLs: cfg.AuthorizationServers,
+		CacheTTL:   cfg.JWKSCacheTTL,
+		HTTP:       cfg.HTTPClient,
+		ServerHTTP: cfg.AuthorizationServerHTTP,
+	}
+	if cfg.X5CRoots != nil || cfg.RequireX5C {
+		clientCfg.X5C = &jwks.X5CPolicy{
+			Roots:   cfg.X5CRoots,
+			Require: cfg.RequireX5C,
+		}
+	}
+	if cfg.JWKSCacheFile != "" {
+		clientCfg.Persistence = &jwks.PersistenceConfig{
+			Path:     cfg.JWKSCacheFile,
+			Key:      cfg.JWKSCacheKey,
+			MaxStale: cfg.JWKSCacheMaxStale,
+		}
+	}
+	return jwks.NewClientWithConfig(clientCfg)
+}
+
+// LoadCertPool reads a PEM-encoded CA bundle from disk into a certificate pool.
+// It is used to build X5CRoots from a configured file path.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	return jwks.LoadCertPool(path)
+}
+
+// LoadMetadataSigningKey reads a metadata signing key from a PEM or JWK file.
+// It is used to build ResourceMetadataOptions.SigningKey from a configured file path.
+func LoadMetadataSigningKey(path string) (*MetadataSigningKey, error) {
+	return metadata.LoadSigningKey(path)
+}
+
+// NewDevAuthorizationServer creates the embedded development authorization
+// server. It auto-approves every authorization request for cfg.User and
+// keeps clients and grants in memory, so it is for local testing only.
+// The returned handler serves every endpoint below the issuer path, plus
+// the RFC 8414 metadata location with the issuer path inserted.
+func NewDevAuthorizationServer(cfg *DevServerConfig) (http.Handler, error) {
+	server, err := devserver.NewServer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
 }
 
 // NewTokenValidator creates a new token validator with the provided configuration.
@@ -133,14 +286,38 @@ func NewTokenValidator(cfg *Config, jwksClient JWKSClient) TokenValidator {
 // NewMetadataService creates a new protected resource metadata service.
 // The service provides RFC 9728 compliant metadata at the well-known endpoint.
 func NewMetadataService(cfg *Config) MetadataService {
-	service := metadata.NewService(
+	service := metadata.NewServiceWithOptions(
 		cfg.BaseURL,
 		cfg.AuthorizationServers,
 		cfg.ScopesSupported,
+		cfg.ResourceMetadata,
 	)
 	return &metadataServiceAdapter{service: service}
 }
 
+// ValidateMetadata validates a protected resource metadata document per RFC 9728.
+// It is intended to be called at startup on the document a MetadataService serves.
+func ValidateMetadata(m *ProtectedResourceMetadata) error {
+	return metadata.ValidateMetadata(&metadata.ProtectedResourceMetadata{
+		Resource:                              m.Resource,
+		AuthorizationServers:                  m.AuthorizationServers,
+		ScopesSupported:                       m.ScopesSupported,
+		BearerMethodsSupported:                m.BearerMethodsSupported,
+		JWKSURI:                               m.JWKSURI,
+		ResourceSigningAlgValuesSupported:     m.ResourceSigningAlgValuesSupported,
+		ResourceName:                          m.ResourceName,
+		ResourceDocumentation:                 m.ResourceDocumentation,
+		ResourcePolicyURI:                     m.ResourcePolicyURI,
+		ResourceTOSURI:                        m.ResourceTOSURI,
+		TLSClientCertificateBoundAccessTokens: m.TLSClientCertificateBoundAccessTokens,
+		AuthorizationDetailsTypesSupported:    m.AuthorizationDetailsTypesSupported,
+		DPoPSigningAlgValuesSupported:         m.DPoPSigningAlgValuesSupported,
+		DPoPBoundAccessTokensRequired:         m.DPoPBoundAccessTokensRequired,
+		SignedMetadata:                        m.SignedMetadata,
+		Extensions:                            m.Extensions,
+	})
+}
+
 // NewScopeChecker creates a new scope checker.
 // The checker validates token scopes against required scopes for operations.
 func NewScopeChecker() ScopeChecker {
@@ -2,6 +2,9 @@ package oauth
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -20,6 +23,61 @@ func TestNewJWKSClient(t *testing.T) {
 	}
 }
 
+func TestNewJWKSClient_ImplementsInspector(t *testing.T) {
+	t.Parallel()
+
+	client := NewJWKSClient(&Config{
+		AuthorizationServers: []string{"https://auth.example.com"},
+		JWKSCacheTTL:         5 * time.Minute,
+	})
+
+	inspector, ok := client.(JWKSInspector)
+	if !ok {
+		t.Fatal("NewJWKSClient() result does not implement JWKSInspector")
+	}
+
+	statuses := inspector.Status()
+	if len(statuses) != 1 || statuses[0].Issuer != "https://auth.example.com" {
+		t.Errorf("Status() = %+v, want one entry for the configured server", statuses)
+	}
+}
+
+func TestNewJWKSClient_ImplementsAuthorizationServerDirectory(t *testing.T) {
+	t.Parallel()
+
+	client := NewJWKSClient(&Config{
+		AuthorizationServers: []string{"https://auth.example.com"},
+		JWKSCacheTTL:         5 * time.Minute,
+	})
+
+	if _, ok := client.(AuthorizationServerDirectory); !ok {
+		t.Fatal("NewJWKSClient() result does not implement AuthorizationServerDirectory")
+	}
+}
+
+func TestNewDevAuthorizationServer(t *testing.T) {
+	t.Parallel()
+
+	handler, err := NewDevAuthorizationServer(&DevServerConfig{
+		Issuer:   "http://localhost:8080/dev-as",
+		Audience: "http://localhost:8080/mcp",
+		User:     "dev-user",
+	})
+	if err != nil {
+		t.Fatalf("NewDevAuthorizationServer() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dev-as/.well-known/oauth-authorization-server", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("metadata status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if _, err := NewDevAuthorizationServer(&DevServerConfig{Issuer: "http://localhost:8080/dev-as"}); err == nil {
+		t.Error("NewDevAuthorizationServer() expected error without audience, got nil")
+	}
+}
+
 func TestNewTokenValidator(t *testing.T) {
 	t.Parallel()
 
@@ -153,12 +211,99 @@ func TestMetadataServiceAdapter(t *testing.T) {
 
 	// Test GetMetadataURL
 	metadataURL := service.GetMetadataURL()
-	expectedURL := "https://example.com/mcp/.well-known/oauth-protected-resource"
+	expectedURL := "https://example.com/.well-known/oauth-protected-resource/mcp"
 	if metadataURL != expectedURL {
 		t.Errorf("GetMetadataURL() = %q, want %q", metadataURL, expectedURL)
 	}
 }
 
+func TestMetadataServiceAdapter_ResourceMetadataOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		BaseURL:              "https://example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+		ResourceMetadata: ResourceMetadataOptions{
+			ResourceName:                  "Example MCP",
+			ResourcePolicyURI:             "https://example.com/policy",
+			DPoPSigningAlgValuesSupported: []string{"ES256"},
+			Extensions:                    map[string]any{"x_region": "eu"},
+		},
+	}
+
+	metadata, err := NewMetadataService(cfg).GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	if err := ValidateMetadata(metadata); err != nil {
+		t.Fatalf("ValidateMetadata() unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"resource":            "https://example.com/mcp",
+		"resource_name":       "Example MCP",
+		"resource_policy_uri": "https://example.com/policy",
+		"x_region":            "eu",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+
+	metadata.ResourcePolicyURI = "ftp://example.com/policy"
+	if err := ValidateMetadata(metadata); err == nil {
+		t.Error("ValidateMetadata() expected error for non-https policy URI, got nil")
+	}
+}
+
+func TestMetadataServiceAdapter_ImplementsResourceKeySet(t *testing.T) {
+	t.Parallel()
+
+	service := NewMetadataService(&Config{
+		BaseURL:              "https://example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+	})
+
+	keySet, ok := service.(ResourceKeySet)
+	if !ok {
+		t.Fatal("NewMetadataService() should return a ResourceKeySet")
+	}
+	if keySet.JWKS() != nil {
+		t.Error("JWKS() should be nil without a signing key")
+	}
+}
+
+func TestMetadataServiceAdapter_ScopeProviders(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		BaseURL:              "https://example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+		ScopesSupported:      []string{"mcp:write"},
+		ResourceMetadata: ResourceMetadataOptions{
+			ScopeProviders: []ScopeProvider{StaticScopes{"mcp:read", "mcp:write"}},
+		},
+	}
+
+	metadata, err := NewMetadataService(cfg).GetMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadata() unexpected error: %v", err)
+	}
+	if len(metadata.ScopesSupported) != 2 || metadata.ScopesSupported[0] != "mcp:read" || metadata.ScopesSupported[1] != "mcp:write" {
+		t.Errorf("ScopesSupported = %v, want [mcp:read mcp:write]", metadata.ScopesSupported)
+	}
+}
+
 func TestScopeCheckerAdapter(t *testing.T) {
 	t.Parallel()
 
@@ -34,7 +34,11 @@
 //   - Bearer tokens MUST be in Authorization header only (not query strings)
 //   - 401 responses include WWW-Authenticate header with resource_metadata parameter
 //   - 403 responses use error="insufficient_scope" with required scopes
-//   - Protected Resource Metadata is served at /.well-known/oauth-protected-resource
+//   - Protected Resource Metadata is served at /.well-known/oauth-protected-resource{/path}
+//     for the MCP resource and each additional protected resource, with the root
+//     location describing the MCP resource
+//   - Each additional protected resource only accepts tokens issued for it, and its
+//     401 responses point at its own metadata document
 //
 // # Middleware Chain
 //
@@ -96,10 +100,14 @@
 //
 // Public endpoints (no authentication):
 //   - GET /.well-known/oauth-protected-resource - Protected Resource Metadata (RFC 9728)
+//   - GET /.well-known/oauth-protected-resource/{path} - Metadata of a resource mounted under a path
 //   - GET /health - Health check
+//   - GET {AuthorizationServersPath} - Cached metadata, health and capabilities of the
+//     trusted authorization servers (optional, RFC 8414)
 //
 // Protected endpoints (authentication required):
 //   - POST /mcp - MCP protocol (JSON-RPC 2.0)
+//   - POST {path} - MCP protocol at the path of each additional protected resource
 //
 // # Context Values
 //
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// authorizationServersResponse represents the JSON response listing the
+// metadata of the trusted authorization servers.
+type authorizationServersResponse struct {
+	AuthorizationServers []oauth.AuthorizationServerStatus `json:"authorization_servers"`
+}
+
+// authorizationServersHandler serves the cached metadata of the trusted
+// authorization servers.
+type authorizationServersHandler struct {
+	directory oauth.AuthorizationServerDirectory
+}
+
+// NewAuthorizationServersHandler creates a handler that aggregates the RFC 8414
+// metadata of every trusted authorization server, with its health and
+// capabilities. An "issuer" query parameter restricts the response to one
+// server. The endpoint is public, like the protected resource metadata.
+func NewAuthorizationServersHandler(directory oauth.AuthorizationServerDirectory) http.Handler {
+	if directory == nil {
+		panic("directory cannot be nil")
+	}
+
+	return &authorizationServersHandler{directory: directory}
+}
+
+// ServeHTTP handles GET requests for authorization server metadata.
+// Only GET method is allowed.
+func (h *authorizationServersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET method
+	if r.Method != http.MethodGet {
+		// Method not allowed - return 405
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses := h.directory.ServerMetadata(r.Context())
+
+	if issuer := r.URL.Query().Get("issuer"); issuer != "" {
+		var filtered []oauth.AuthorizationServerStatus
+		for _, status := range statuses {
+			if status.Issuer == issuer {
+				filtered = append(filtered, status)
+			}
+		}
+		if len(filtered) == 0 {
+			h.writeJSON(w, http.StatusNotFound, errorBody{
+				Error:   "not_found",
+				Message: "Unknown authorization server: " + issuer,
+			})
+			return
+		}
+		statuses = filtered
+	}
+
+	h.writeJSON(w, http.StatusOK, authorizationServersResponse{AuthorizationServers: statuses})
+}
+
+// writeJSON sends v as a JSON response with the given status code.
+func (h *authorizationServersHandler) writeJSON(w http.ResponseWriter, status int, v any) {
+	// Metadata is served from cache; let clients cache it briefly too
+	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode authorization server metadata", "error", err)
+		// Can't send error response here since headers are already written
+	}
+}
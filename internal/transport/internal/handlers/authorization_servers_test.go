@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+)
+
+// mockAuthorizationServerDirectory implements oauth.AuthorizationServerDirectory for testing.
+type mockAuthorizationServerDirectory struct {
+	statuses []oauth.AuthorizationServerStatus
+}
+
+func (m *mockAuthorizationServerDirectory) ServerMetadata(ctx context.Context) []oauth.AuthorizationServerStatus {
+	return m.statuses
+}
+
+func TestAuthorizationServersHandler(t *testing.T) {
+	t.Parallel()
+
+	directory := &mockAuthorizationServerDirectory{statuses: []oauth.AuthorizationServerStatus{
+		{
+			Issuer:   "https://auth.example.com",
+			Metadata: json.RawMessage(`{"issuer":"https://auth.example.com"}`),
+			Healthy:  true,
+			Capabilities: &oauth.AuthorizationServerCapabilities{
+				PKCES256:                 true,
+				TokenEndpointAuthMethods: []string{"none"},
+				GrantTypes:               []string{"authorization_code"},
+			},
+		},
+		{
+			Issuer: "https://down.example.com",
+			Error:  "authorization server metadata could not be fetched",
+		},
+	}}
+
+	tests := []struct {
+		name            string
+		method          string
+		target          string
+		wantStatus      int
+		wantIssuers     []string
+		wantAllowHeader bool
+	}{
+		{
+			name:        "GET lists all servers",
+			method:      http.MethodGet,
+			target:      "/.well-known/oauth-authorization-servers",
+			wantStatus:  http.StatusOK,
+			wantIssuers: []string{"https://auth.example.com", "https://down.example.com"},
+		},
+		{
+			name:        "GET filters by issuer",
+			method:      http.MethodGet,
+			target:      "/.well-known/oauth-authorization-servers?issuer=https://down.example.com",
+			wantStatus:  http.StatusOK,
+			wantIssuers: []string{"https://down.example.com"},
+		},
+		{
+			name:       "unknown issuer",
+			method:     http.MethodGet,
+			target:     "/.well-known/oauth-authorization-servers?issuer=https://other.example.com",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:            "POST not allowed",
+			method:          http.MethodPost,
+			target:          "/.well-known/oauth-authorization-servers",
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantAllowHeader: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewAuthorizationServersHandler(directory)
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantAllowHeader && w.Header().Get("Allow") != http.MethodGet {
+				t.Errorf("Allow = %q, want %q", w.Header().Get("Allow"), http.MethodGet)
+			}
+			if tt.wantIssuers == nil {
+				return
+			}
+
+			if got := w.Header().Get("Cache-Control"); got != "public, max-age=60" {
+				t.Errorf("Cache-Control = %q, want %q", got, "public, max-age=60")
+			}
+
+			var got struct {
+				AuthorizationServers []oauth.AuthorizationServerStatus `json:"authorization_servers"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(got.AuthorizationServers) != len(tt.wantIssuers) {
+				t.Fatalf("authorization_servers = %d, want %d", len(got.AuthorizationServers), len(tt.wantIssuers))
+			}
+			for i, issuer := range tt.wantIssuers {
+				if got.AuthorizationServers[i].Issuer != issuer {
+					t.Errorf("authorization_servers[%d].issuer = %q, want %q", i, got.AuthorizationServers[i].Issuer, issuer)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAuthorizationServersHandler_PanicsOnNil(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewAuthorizationServersHandler(nil) should panic")
+		}
+	}()
+	NewAuthorizationServersHandler(nil)
+}
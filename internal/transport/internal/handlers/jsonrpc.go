@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
+)
+
+var (
+	// errEmptyBatch rejects a batch without messages.
+	errEmptyBatch = errors.New("empty batch")
+
+	// errBatchNotSupported rejects a batch outside of a session whose
+	// protocol version supports batching.
+	errBatchNotSupported = errors.New("batch requests are not supported by the negotiated protocol version")
+
+	// errInitializeInBatch rejects initialize as part of a batch.
+	errInitializeInBatch = errors.New("initialize cannot be part of a batch")
+
+	// errBatchTooLarge rejects a batch of more than maxBatchSize messages.
+	errBatchTooLarge = fmt.Errorf("batch exceeds %d messages", maxBatchSize)
+)
+
+const (
+	// maxRequestBodyBytes caps the size of a POSTed message or batch.
+	maxRequestBodyBytes = 4 << 20
+
+	// maxBatchSize caps the number of messages in a batch.
+	maxBatchSize = 100
+
+	// maxBatchWorkers caps the number of requests of a batch handled at
+	// the same time.
+	maxBatchWorkers = 8
+)
+
+// decodeMessages parses a request body holding one JSON-RPC message or a
+// batch array of messages. Batch entries that are not JSON objects decode
+// to empty messages, which are answered as invalid requests.
+func decodeMessages(body []byte) ([]*mcp.Message, bool, error) {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		var msg mcp.Message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return nil, false, err
+		}
+		return []*mcp.Message{&msg}, false, nil
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, true, err
+	}
+
+	messages := make([]*mcp.Message, len(entries))
+	for i, entry := range entries {
+		messages[i] = &mcp.Message{}
+		if err := json.Unmarshal(entry, messages[i]); err != nil {
+			messages[i] = &mcp.Message{}
+		}
+	}
+	return messages, true, nil
+}
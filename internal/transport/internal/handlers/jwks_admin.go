@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	ierrors "github.com/jamesprial/mcp-oauth-2.1/internal/errors"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/transportcore"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// jwksStatusResponse represents the JSON response listing JWKS state.
+type jwksStatusResponse struct {
+	Issuers []oauth.JWKSIssuerStatus `json:"issuers"`
+}
+
+// jwksRefreshResponse represents the JSON response of a single-issuer refresh.
+type jwksRefreshResponse struct {
+	Issuer oauth.JWKSIssuerStatus `json:"issuer"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// jwksAdminHandler exposes JWKS state and per-issuer refresh to operators.
+type jwksAdminHandler struct {
+	inspector oauth.JWKSInspector
+	responder transportcore.ErrorResponder
+}
+
+// NewJWKSAdminHandler creates a handler for the /admin/jwks endpoint.
+// GET lists the key set state of every authorization server.
+// POST with an "issuer" query parameter refreshes that server's key set only.
+// The handler must be mounted behind authentication and an admin scope check.
+func NewJWKSAdminHandler(inspector oauth.JWKSInspector, responder transportcore.ErrorResponder) http.Handler {
+	if inspector == nil {
+		panic("inspector cannot be nil")
+	}
+	if responder == nil {
+		panic("responder cannot be nil")
+	}
+
+	return &jwksAdminHandler{
+		inspector: inspector,
+		responder: responder,
+	}
+}
+
+// ServeHTTP handles GET (status) and POST (refresh) requests.
+func (h *jwksAdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeJSON(w, http.StatusOK, jwksStatusResponse{Issuers: h.inspector.Status()})
+	case http.MethodPost:
+		h.refresh(w, r)
+	default:
+		// Method not allowed - return 405
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// refresh re-fetches the key set of the issuer named in the query string.
+func (h *jwksAdminHandler) refresh(w http.ResponseWriter, r *http.Request) {
+	issuer := r.URL.Query().Get("issuer")
+	if issuer == "" {
+		h.responder.BadRequest(w, errors.New("issuer query parameter is required"))
+		return
+	}
+
+	err := h.inspector.RefreshIssuer(r.Context(), issuer)
+	if errors.Is(err, ierrors.ErrNotFound) {
+		h.writeJSON(w, http.StatusNotFound, errorBody{
+			Error:   "not_found",
+			Message: "Unknown authorization server: " + issuer,
+		})
+		return
+	}
+
+	resp := jwksRefreshResponse{}
+	for _, status := range h.inspector.Status() {
+		if status.Issuer == issuer {
+			resp.Issuer = status
+			break
+		}
+	}
+
+	if err != nil {
+		slog.Warn("jwks refresh failed", "authorization_server", issuer, "error", err)
+		resp.Error = err.Error()
+		h.writeJSON(w, http.StatusBadGateway, resp)
+		return
+	}
+
+	slog.Info("jwks refreshed", "authorization_server", issuer, "keys", len(resp.Issuer.Keys))
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// errorBody represents a JSON error response body.
+type errorBody struct {
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+}
+
+// writeJSON sends v as a JSON response with the given status code.
+func (h *jwksAdminHandler) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode jwks admin response", "error", err)
+		// Can't send error response here since headers are already written
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth/oautherr"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/internal/mocks"
+)
+
+// mockJWKSInspector implements oauth.JWKSInspector for testing.
+type mockJWKSInspector struct {
+	statuses  []oauth.JWKSIssuerStatus
+	refreshed []string
+	refreshFn func(issuer string) error
+}
+
+func (m *mockJWKSInspector) Status() []oauth.JWKSIssuerStatus {
+	return m.statuses
+}
+
+func (m *mockJWKSInspector) RefreshIssuer(ctx context.Context, issuer string) error {
+	m.refreshed = append(m.refreshed, issuer)
+	if m.refreshFn != nil {
+		return m.refreshFn(issuer)
+	}
+	return nil
+}
+
+func TestJWKSAdminHandler_GET(t *testing.T) {
+	t.Parallel()
+
+	inspector := &mockJWKSInspector{statuses: []oauth.JWKSIssuerStatus{
+		{
+			Issuer:  "https://auth.example.com",
+			JWKSURI: "https://auth.example.com/jwks",
+			Keys: []oauth.JWKSKeyStatus{
+				{KeyID: "key-1", KeyType: "RSA", Algorithm: "RS256", Size: 2048, Trusted: true},
+			},
+		},
+	}}
+	handler := NewJWKSAdminHandler(inspector, &mocks.ErrorResponder{})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/jwks", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	var body jwksStatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Issuers) != 1 || body.Issuers[0].JWKSURI != "https://auth.example.com/jwks" {
+		t.Fatalf("Issuers = %+v, want single issuer with jwks_uri", body.Issuers)
+	}
+	if key := body.Issuers[0].Keys[0]; key.KeyID != "key-1" || key.Size != 2048 || !key.Trusted {
+		t.Errorf("Keys[0] = %+v, want trusted 2048-bit key-1", key)
+	}
+	if len(inspector.refreshed) != 0 {
+		t.Errorf("GET should not refresh, refreshed %v", inspector.refreshed)
+	}
+}
+
+func TestJWKSAdminHandler_POST(t *testing.T) {
+	t.Parallel()
+
+	const issuer = "https://auth.example.com"
+
+	tests := []struct {
+		name           string
+		target         string
+		refreshErr     error
+		wantStatus     int
+		wantBadRequest bool
+	}{
+		{
+			name:       "refresh succeeds",
+			target:     "/admin/jwks?issuer=" + issuer,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "missing issuer",
+			target:         "/admin/jwks",
+			wantStatus:     http.StatusBadRequest,
+			wantBadRequest: true,
+		},
+		{
+			name:       "unknown issuer",
+			target:     "/admin/jwks?issuer=https://other.example.com",
+			refreshErr: oautherr.NewUnknownAuthorizationServerError("RefreshIssuer", "https://other.example.com"),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "fetch fails",
+			target:     "/admin/jwks?issuer=" + issuer,
+			refreshErr: oautherr.NewJWKSFetchError("fetchJWKS", issuer, errors.New("connection refused")),
+			wantStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inspector := &mockJWKSInspector{
+				statuses:  []oauth.JWKSIssuerStatus{{Issuer: issuer}},
+				refreshFn: func(string) error { return tt.refreshErr },
+			}
+			responder := &mocks.ErrorResponder{}
+			handler := NewJWKSAdminHandler(inspector, responder)
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("POST status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if responder.BadRequestCalled != tt.wantBadRequest {
+				t.Errorf("BadRequestCalled = %v, want %v", responder.BadRequestCalled, tt.wantBadRequest)
+			}
+
+			if tt.wantStatus == http.StatusOK || tt.wantStatus == http.StatusBadGateway {
+				var body jwksRefreshResponse
+				if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if body.Issuer.Issuer != issuer {
+					t.Errorf("Issuer = %q, want %q", body.Issuer.Issuer, issuer)
+				}
+				if (body.Error != "") != (tt.refreshErr != nil) {
+					t.Errorf("Error = %q, want error %v", body.Error, tt.refreshErr != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestJWKSAdminHandler_MethodNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	handler := NewJWKSAdminHandler(&mockJWKSInspector{}, &mocks.ErrorResponder{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/jwks", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want 405", w.Code)
+	}
+}
+
+func TestNewJWKSAdminHandler_NilInspector(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewJWKSAdminHandler(nil) should panic")
+		}
+	}()
+	NewJWKSAdminHandler(nil, &mocks.ErrorResponder{})
+}
@@ -1,56 +1,81 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
 	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/transportcore"
 	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
 )
 
-// mcpHandler handles MCP protocol requests over HTTP.
+// mcpHandler serves the MCP Streamable HTTP transport: JSON-RPC requests
+// are POSTed, GET opens a stream of server-initiated messages for a
+// session, and DELETE terminates a session.
 type mcpHandler struct {
 	handler   mcp.Handler
+	sessions  mcp.SessionManager
 	responder transportcore.ErrorResponder
 }
 
-// NewMCPHandler creates a handler for MCP JSON-RPC requests.
-// It parses JSON-RPC requests, delegates to the MCP handler, and returns JSON-RPC responses.
-func NewMCPHandler(handler mcp.Handler, responder transportcore.ErrorResponder) http.Handler {
+// NewMCPHandler creates a handler for the MCP Streamable HTTP transport.
+// It parses JSON-RPC requests, delegates to the MCP handler, and returns
+// JSON-RPC responses, as a server-sent event stream when the handler sends
+// messages to the client before its response and the client accepts one.
+// Sessions are created by initialize and tracked in sessions.
+func NewMCPHandler(handler mcp.Handler, sessions mcp.SessionManager, responder transportcore.ErrorResponder) http.Handler {
 	if handler == nil {
 		panic("handler cannot be nil")
 	}
+	if sessions == nil {
+		panic("sessions cannot be nil")
+	}
 	if responder == nil {
 		panic("responder cannot be nil")
 	}
 
 	return &mcpHandler{
 		handler:   handler,
+		sessions:  sessions,
 		responder: responder,
 	}
 }
 
-// ServeHTTP handles POST requests for MCP protocol.
-// Only POST method is allowed for JSON-RPC requests.
+// ServeHTTP routes POST, GET and DELETE requests.
 func (h *mcpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Only allow POST method
-	if r.Method != http.MethodPost {
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
+	default:
 		// Method not allowed - return 405
-		w.Header().Set("Allow", http.MethodPost)
+		w.Header().Set("Allow", "GET, POST, DELETE")
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
 	}
+}
 
+// handlePost handles a JSON-RPC message or batch of messages. Requests are
+// answered in the response body, in order for a batch; a body of only
+// notifications and responses to server-initiated requests is accepted
+// with 202 and no body.
+func (h *mcpHandler) handlePost(w http.ResponseWriter, r *http.Request) {
 	// Check Content-Type header
 	contentType := r.Header.Get(pkgoauth.HeaderContentType)
 	if contentType != pkgoauth.ContentTypeJSON && contentType != "" {
 		slog.Warn("unexpected content type", "content_type", contentType)
 	}
 
-	// Read request body
+	// Read request body, up to maxRequestBodyBytes
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		slog.Error("failed to read request body", "error", err)
@@ -63,58 +88,309 @@ func (h *mcpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// Parse JSON-RPC request
-	var req mcp.Request
-	if err := json.Unmarshal(body, &req); err != nil {
+	// Parse JSON-RPC message or batch
+	messages, batch, err := decodeMessages(body)
+	if err != nil {
 		slog.Error("failed to parse JSON-RPC request", "error", err)
 		// Return JSON-RPC parse error
 		h.sendJSONRPCError(w, nil, mcp.CodeParseError, "Parse error", err)
 		return
 	}
+	if len(messages) == 0 {
+		h.sendJSONRPCError(w, nil, mcp.CodeInvalidRequest, "Invalid request", errEmptyBatch)
+		return
+	}
+	if len(messages) > maxBatchSize {
+		h.sendJSONRPCError(w, nil, mcp.CodeInvalidRequest, "Invalid request", errBatchTooLarge)
+		return
+	}
+
+	// Tools, resources and prompts are offered by the scopes granted to the caller
+	ctx := r.Context()
+	if claims, ok := transportcore.ClaimsFromContext(ctx); ok {
+		ctx = mcp.ContextWithScopes(ctx, claims.Scopes)
+	}
+
+	// initialize starts a new session; other messages continue the session
+	// named by the header, which is required: a missing header is answered
+	// with 400 and an unknown or expired session with 404
+	var session, created *mcp.Session
+	if !batch && messages[0].Method == "initialize" {
+		created, err = h.sessions.Create(sessionOwner(r))
+		if err != nil {
+			slog.Error("failed to create session", "error", err)
+			h.sendJSONRPCError(w, messages[0].Request().ID, mcp.CodeInternalError, "Internal error", err)
+			return
+		}
+		session = created
+		w.Header().Set(pkgoauth.HeaderMCPSessionID, created.ID())
+	} else {
+		var ok bool
+		if session, ok = h.session(w, r); !ok {
+			return
+		}
+	}
+	ctx = mcp.ContextWithSession(ctx, session)
+
+	// Batches are only part of some protocol revisions
+	if batch && !session.Features().Batching {
+		h.sendJSONRPCError(w, nil, mcp.CodeInvalidRequest, "Invalid request", errBatchNotSupported)
+		return
+	}
+
+	// Messages sent while requests are handled switch the response to an
+	// event stream, if the client accepts one
+	stream := newEventStream(w)
+	defer stream.close()
+	if acceptsEventStream(r) {
+		ctx = mcp.ContextWithSender(ctx, stream)
+	}
+
+	// Handle the requests of a batch in parallel, up to maxBatchWorkers at
+	// a time, collecting their responses in order
+	responses := make([]*mcp.Response, len(messages))
+	var pending []int
+	for i, msg := range messages {
+		switch {
+		case msg.IsResponse():
+			h.deliverResponse(session, msg)
+		case msg.IsNotification():
+			h.handleNotification(ctx, msg)
+		case batch && msg.Method == "initialize":
+			responses[i] = errorResponse(msg.Request().ID, mcp.CodeInvalidRequest, "Invalid request", errInitializeInBatch)
+		default:
+			pending = append(pending, i)
+		}
+	}
+	jobs := make(chan int, len(pending))
+	for _, i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for range min(len(pending), maxBatchWorkers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				responses[i] = h.handleRequest(ctx, messages[i])
+			}
+		}()
+	}
+	wg.Wait()
+	responses = slices.DeleteFunc(responses, func(resp *mcp.Response) bool { return resp == nil })
+
+	// A failed initialize leaves no session behind
+	if created != nil && (len(responses) == 0 || responses[0].IsError()) {
+		h.sessions.Delete(created.ID())
+		w.Header().Del(pkgoauth.HeaderMCPSessionID)
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Send JSON-RPC response, an array for a batch
+	var result any = responses[0]
+	if batch {
+		result = responses
+	}
+	if err := stream.finish(result); err != nil {
+		slog.Error("failed to encode JSON-RPC response", "error", err)
+		// Can't send error response here since headers are already written
+	}
+}
+
+// handleRequest validates a request and passes it to the MCP handler.
+func (h *mcpHandler) handleRequest(ctx context.Context, msg *mcp.Message) *mcp.Response {
+	req := msg.Request()
 
 	// Validate request
 	if err := req.Validate(); err != nil {
 		slog.Error("invalid JSON-RPC request", "error", err)
-		h.sendJSONRPCError(w, req.ID, mcp.CodeInvalidRequest, "Invalid request", err)
-		return
+		return errorResponse(req.ID, mcp.CodeInvalidRequest, "Invalid request", err)
 	}
 
 	// Handle request
-	resp, err := h.handler.HandleRequest(r.Context(), &req)
+	resp, err := h.handler.HandleRequest(ctx, req)
 	if err != nil {
 		slog.Error("MCP handler error", "error", err, "method", req.Method)
 		// If the handler returned an error, send it as JSON-RPC error
-		h.sendJSONRPCError(w, req.ID, mcp.CodeInternalError, "Internal error", err)
+		return errorResponse(req.ID, mcp.CodeInternalError, "Internal error", err)
+	}
+	return resp
+}
+
+// handleNotification passes a notification to the MCP handler, if it
+// handles notifications. Notifications are never answered.
+func (h *mcpHandler) handleNotification(ctx context.Context, msg *mcp.Message) {
+	notification := msg.Request()
+	if err := notification.Validate(); err != nil {
+		slog.Warn("invalid JSON-RPC notification", "error", err)
+		return
+	}
+
+	handler, ok := h.handler.(mcp.NotificationHandler)
+	if !ok {
 		return
 	}
+	if err := handler.HandleNotification(ctx, notification); err != nil {
+		slog.Warn("MCP notification error", "error", err, "method", notification.Method)
+	}
+}
 
-	// Send JSON-RPC response
-	h.sendJSONRPCResponse(w, resp)
+// deliverResponse routes a client's response to the server-initiated
+// request of session it answers.
+func (h *mcpHandler) deliverResponse(session *mcp.Session, msg *mcp.Message) {
+	if session == nil || !session.Respond(msg) {
+		slog.Warn("dropping response to unknown request", "id", string(msg.ID))
+	}
 }
 
-// sendJSONRPCResponse sends a JSON-RPC response to the client.
-func (h *mcpHandler) sendJSONRPCResponse(w http.ResponseWriter, resp *mcp.Response) {
-	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
-	w.WriteHeader(http.StatusOK)
+// handleGet opens the standalone stream of a session, carrying the
+// messages the server sends outside of a request. It stays open until the
+// client disconnects, the session is terminated or the access token of the
+// request expires.
+func (h *mcpHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		h.sendSessionError(w, http.StatusNotAcceptable, "Accept must include "+pkgoauth.ContentTypeEventStream)
+		return
+	}
 
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		slog.Error("failed to encode JSON-RPC response", "error", err)
-		// Can't send error response here since headers are already written
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	stream := newEventStream(w)
+	defer stream.close()
+
+	release, err := session.OpenStream(stream)
+	if err != nil {
+		h.sendSessionError(w, http.StatusConflict, "Session already has an open stream")
+		return
+	}
+	defer release()
+
+	if err := stream.open(); err != nil {
+		slog.Warn("failed to open event stream", "error", err)
+		return
+	}
+
+	// The stream lasts no longer than the token that opened it, so a
+	// client must authenticate again to keep receiving messages
+	var expired <-chan time.Time
+	if claims, ok := transportcore.ClaimsFromContext(r.Context()); ok && !claims.ExpiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(claims.ExpiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-r.Context().Done():
+	case <-session.Done():
+	case <-expired:
 	}
 }
 
-// sendJSONRPCError sends a JSON-RPC error response to the client.
-func (h *mcpHandler) sendJSONRPCError(w http.ResponseWriter, id any, code int, message string, cause error) {
+// handleDelete terminates a session at the client's request.
+func (h *mcpHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	h.sessions.Delete(session.ID())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// session returns the session named by the request header. It writes an
+// error response and returns false if the header is missing or the
+// session does not exist. A session owned by another caller is reported
+// as not found, so session IDs cannot be probed across callers.
+func (h *mcpHandler) session(w http.ResponseWriter, r *http.Request) (*mcp.Session, bool) {
+	id := r.Header.Get(pkgoauth.HeaderMCPSessionID)
+	if id == "" {
+		h.sendSessionError(w, http.StatusBadRequest, pkgoauth.HeaderMCPSessionID+" header is required")
+		return nil, false
+	}
+
+	session, ok := h.sessions.Get(id)
+	if !ok || session.Owner() != sessionOwner(r) {
+		h.sendSessionError(w, http.StatusNotFound, "Session not found")
+		return nil, false
+	}
+
+	if message := checkProtocolVersion(r, session); message != "" {
+		h.sendSessionError(w, http.StatusBadRequest, message)
+		return nil, false
+	}
+	return session, true
+}
+
+// checkProtocolVersion validates the MCP-Protocol-Version header of a
+// request in session against the version negotiated by initialize. The
+// header is required from revisions that define it; older clients may
+// omit it. Returns the error message, or "" if the header is acceptable.
+func checkProtocolVersion(r *http.Request, session *mcp.Session) string {
+	negotiated := session.ProtocolVersion()
+	if negotiated == "" {
+		// initialize has not completed yet
+		return ""
+	}
+
+	version := r.Header.Get(pkgoauth.HeaderMCPProtocolVersion)
+	switch {
+	case version == "" && session.Features().ProtocolVersionHeader:
+		return pkgoauth.HeaderMCPProtocolVersion + " header is required"
+	case version == "":
+		return ""
+	case !mcp.IsSupportedProtocolVersion(version):
+		return "Unsupported " + pkgoauth.HeaderMCPProtocolVersion + ": " + version
+	case version != negotiated:
+		return pkgoauth.HeaderMCPProtocolVersion + " " + version + " does not match the negotiated version " + negotiated
+	}
+	return ""
+}
+
+// sessionOwner returns the caller authenticated for the request. Requests
+// without token claims share the zero owner.
+func sessionOwner(r *http.Request) mcp.SessionOwner {
+	claims, ok := transportcore.ClaimsFromContext(r.Context())
+	if !ok {
+		return mcp.SessionOwner{}
+	}
+	return mcp.SessionOwner{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		ClientID: claims.ClientID,
+	}
+}
+
+// sendSessionError sends a JSON-RPC error without an ID and the given HTTP
+// status, for requests rejected by the transport before reaching MCP.
+func (h *mcpHandler) sendSessionError(w http.ResponseWriter, status int, message string) {
 	resp := &mcp.Response{
 		JSONRPC: mcp.JSONRPCVersion,
-		ID:      id,
 		Error: &mcp.Error{
-			Code:    code,
+			Code:    mcp.CodeInvalidRequest,
 			Message: message,
-			Cause:   cause,
 		},
 	}
 
+	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("failed to encode JSON-RPC error response", "error", err)
+	}
+}
+
+// sendJSONRPCError sends a JSON-RPC error response to the client.
+func (h *mcpHandler) sendJSONRPCError(w http.ResponseWriter, id any, code int, message string, cause error) {
+	resp := errorResponse(id, code, message, cause)
+
 	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
 	w.WriteHeader(http.StatusOK) // JSON-RPC errors still return 200 OK
 
@@ -122,3 +398,16 @@ func (h *mcpHandler) sendJSONRPCError(w http.ResponseWriter, id any, code int, m
 		slog.Error("failed to encode JSON-RPC error response", "error", err)
 	}
 }
+
+// errorResponse creates a JSON-RPC error response.
+func errorResponse(id any, code int, message string, cause error) *mcp.Response {
+	return &mcp.Response{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      id,
+		Error: &mcp.Error{
+			Code:    code,
+			Message: message,
+			Cause:   cause,
+		},
+	}
+}
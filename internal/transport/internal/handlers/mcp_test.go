@@ -2,17 +2,24 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
 	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/internal/mocks"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/transportcore"
 )
 
 // mockMCPHandler implements mcp.Handler for testing.
@@ -27,6 +34,18 @@ func (m *mockMCPHandler) HandleRequest(ctx context.Context, req *mcp.Request) (*
 	return nil, errors.New("not implemented")
 }
 
+// newSession starts a session owned by unauthenticated requests and
+// returns its ID.
+func newSession(t *testing.T, sessions mcp.SessionManager) string {
+	t.Helper()
+
+	session, err := sessions.Create(mcp.SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return session.ID()
+}
+
 func TestMCPHandler_ValidRequest(t *testing.T) {
 	t.Parallel()
 
@@ -43,7 +62,7 @@ func TestMCPHandler_ValidRequest(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
@@ -78,35 +97,17 @@ func TestMCPHandler_ValidRequest(t *testing.T) {
 	}
 }
 
-func TestMCPHandler_GET(t *testing.T) {
-	t.Parallel()
-
-	handler := &mockMCPHandler{}
-	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
-
-	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
-	w := httptest.NewRecorder()
-
-	mcpHandler.ServeHTTP(w, req)
-
-	if w.Code != http.StatusMethodNotAllowed {
-		t.Errorf("MCPHandler GET status = %v, want 405", w.Code)
-	}
-}
-
 func TestMCPHandler_OtherMethods(t *testing.T) {
 	t.Parallel()
 
 	methods := []string{
 		http.MethodPut,
-		http.MethodDelete,
 		http.MethodPatch,
 	}
 
 	handler := &mockMCPHandler{}
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
 
 	for _, method := range methods {
 		t.Run(method, func(t *testing.T) {
@@ -120,6 +121,9 @@ func TestMCPHandler_OtherMethods(t *testing.T) {
 			if w.Code != http.StatusMethodNotAllowed {
 				t.Errorf("MCPHandler %s status = %v, want 405", method, w.Code)
 			}
+			if allow := w.Header().Get("Allow"); allow != "GET, POST, DELETE" {
+				t.Errorf("MCPHandler %s Allow = %q, want %q", method, allow, "GET, POST, DELETE")
+			}
 		})
 	}
 }
@@ -129,7 +133,7 @@ func TestMCPHandler_InvalidJSON(t *testing.T) {
 
 	handler := &mockMCPHandler{}
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
 
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("not valid json"))
 	req.Header.Set("Content-Type", "application/json")
@@ -160,7 +164,7 @@ func TestMCPHandler_EmptyBody(t *testing.T) {
 
 	handler := &mockMCPHandler{}
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
 
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(""))
 	req.Header.Set("Content-Type", "application/json")
@@ -193,10 +197,12 @@ func TestMCPHandler_HandlerError(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"test"}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -228,10 +234,12 @@ func TestMCPHandler_JSONRPCError(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"unknown"}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -273,10 +281,12 @@ func TestMCPHandler_ContextPassed(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"test"}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -304,10 +314,12 @@ func TestMCPHandler_RequestParsing(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":"test-id-123","method":"tools/list","params":{"cursor":"abc"}}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -344,10 +356,12 @@ func TestMCPHandler_NumericID(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":42,"method":"test"}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -382,10 +396,12 @@ func TestMCPHandler_NullID(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	reqBody := `{"jsonrpc":"2.0","id":null,"method":"test"}`
 	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(reqBody))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -411,7 +427,8 @@ func TestMCPHandler_LargeRequest(t *testing.T) {
 	}
 
 	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
-	mcpHandler := NewMCPHandler(handler, responder)
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
 
 	// Create a large params object
 	largeParams := make(map[string]string)
@@ -426,6 +443,7 @@ func TestMCPHandler_LargeRequest(t *testing.T) {
 	reqBody.WriteString(`}`)
 
 	req := httptest.NewRequest(http.MethodPost, "/mcp", &reqBody)
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -436,3 +454,726 @@ func TestMCPHandler_LargeRequest(t *testing.T) {
 		t.Errorf("MCPHandler large request status = %v", w.Code)
 	}
 }
+
+// notifyingHandler sends a progress notification before responding.
+func notifyingHandler(notifyErr *error) *mockMCPHandler {
+	return &mockMCPHandler{
+		handleFunc: func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+			*notifyErr = mcp.Notify(ctx, mcp.MethodProgress, mcp.ProgressParams{ProgressToken: "t", Progress: 1})
+			return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{"done": true}}, nil
+		},
+	}
+}
+
+// readEvents parses the data lines of a server-sent event stream.
+func readEvents(t *testing.T, body string) []map[string]any {
+	t.Helper()
+
+	var events []map[string]any
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var event map[string]any
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("invalid event data %q: %v", data, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestMCPHandler_EventStreamResponse(t *testing.T) {
+	t.Parallel()
+
+	var notifyErr error
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(notifyingHandler(&notifyErr), sessions, responder)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call"}`))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	w := httptest.NewRecorder()
+
+	mcpHandler.ServeHTTP(w, req)
+
+	if notifyErr != nil {
+		t.Fatalf("Notify() error = %v", notifyErr)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", contentType)
+	}
+
+	events := readEvents(t, w.Body.String())
+	if len(events) != 2 {
+		t.Fatalf("events = %v, want a notification and the response", events)
+	}
+	if events[0]["method"] != mcp.MethodProgress {
+		t.Errorf("first event = %v, want a progress notification", events[0])
+	}
+	if events[1]["id"] != float64(7) || events[1]["result"] == nil {
+		t.Errorf("last event = %v, want the response to request 7", events[1])
+	}
+}
+
+func TestMCPHandler_JSONWithoutEventStream(t *testing.T) {
+	t.Parallel()
+
+	var notifyErr error
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	sessions := mcp.NewSessionManager(time.Hour)
+	mcpHandler := NewMCPHandler(notifyingHandler(&notifyErr), sessions, responder)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`))
+	req.Header.Set("Mcp-Session-Id", newSession(t, sessions))
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	mcpHandler.ServeHTTP(w, req)
+
+	if !errors.Is(notifyErr, mcp.ErrNoStream) {
+		t.Errorf("Notify() error = %v, want ErrNoStream", notifyErr)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+}
+
+func TestMCPHandler_InitializeCreatesSession(t *testing.T) {
+	t.Parallel()
+
+	sessions := mcp.NewSessionManager(time.Hour)
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+
+	var sessionInContext bool
+	handler := &mockMCPHandler{
+		handleFunc: func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+			_, sessionInContext = mcp.SessionFromContext(ctx)
+			if req.Method == "initialize" {
+				return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}, nil
+			}
+			return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Error: &mcp.Error{Code: mcp.CodeInvalidParams, Message: "bad"}}, nil
+		},
+	}
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		w := httptest.NewRecorder()
+		mcpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "")
+	id := w.Header().Get("Mcp-Session-Id")
+	if id == "" {
+		t.Fatal("initialize response has no Mcp-Session-Id header")
+	}
+	if _, ok := sessions.Get(id); !ok {
+		t.Errorf("session %q was not stored", id)
+	}
+	if !sessionInContext {
+		t.Error("initialize was not handled with its session in the context")
+	}
+
+	sessionInContext = false
+	if w := post(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, id); w.Code != http.StatusOK || !sessionInContext {
+		t.Errorf("request in session status = %d, session in context = %v", w.Code, sessionInContext)
+	}
+
+	if w := post(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, "unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	sessionInContext = false
+	if w := post(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`, ""); w.Code != http.StatusBadRequest || sessionInContext {
+		t.Errorf("request without session status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := post(`{"jsonrpc":"2.0","method":"notifications/initialized"}`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("notification without session status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMCPHandler_FailedInitializeDeletesSession(t *testing.T) {
+	t.Parallel()
+
+	var created *mcp.Session
+	handler := &mockMCPHandler{
+		handleFunc: func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+			created, _ = mcp.SessionFromContext(ctx)
+			return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Error: &mcp.Error{Code: mcp.CodeInvalidParams, Message: "bad"}}, nil
+		},
+	}
+	sessions := mcp.NewSessionManager(time.Hour)
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, sessions, responder)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+	w := httptest.NewRecorder()
+	mcpHandler.ServeHTTP(w, req)
+
+	if id := w.Header().Get("Mcp-Session-Id"); id != "" {
+		t.Errorf("failed initialize returned Mcp-Session-Id %q", id)
+	}
+	if created == nil {
+		t.Fatal("initialize was handled without a session")
+	}
+	if _, ok := sessions.Get(created.ID()); ok {
+		t.Error("failed initialize left its session behind")
+	}
+}
+
+func TestMCPHandler_SessionStream(t *testing.T) {
+	t.Parallel()
+
+	sessions := mcp.NewSessionManager(time.Hour)
+	session, err := sessions.Create(mcp.SessionOwner{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	ts := httptest.NewServer(NewMCPHandler(&mockMCPHandler{}, sessions, responder))
+	t.Cleanup(ts.Close)
+
+	request := func(method, sessionID, accept string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return resp
+	}
+
+	rejected := []struct {
+		name       string
+		method     string
+		sessionID  string
+		accept     string
+		wantStatus int
+	}{
+		{"GET without event stream", http.MethodGet, session.ID(), "application/json", http.StatusNotAcceptable},
+		{"GET without session", http.MethodGet, "", "text/event-stream", http.StatusBadRequest},
+		{"GET unknown session", http.MethodGet, "unknown", "text/event-stream", http.StatusNotFound},
+		{"DELETE without session", http.MethodDelete, "", "", http.StatusBadRequest},
+		{"DELETE unknown session", http.MethodDelete, "unknown", "", http.StatusNotFound},
+	}
+	for _, tt := range rejected {
+		resp := request(tt.method, tt.sessionID, tt.accept)
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s status = %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+	}
+
+	stream := request(http.MethodGet, session.ID(), "text/event-stream")
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK || stream.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET status = %d, Content-Type = %q", stream.StatusCode, stream.Header.Get("Content-Type"))
+	}
+
+	// A session has one standalone stream at a time
+	second := request(http.MethodGet, session.ID(), "text/event-stream")
+	second.Body.Close()
+	if second.StatusCode != http.StatusConflict {
+		t.Errorf("second GET status = %d, want %d", second.StatusCode, http.StatusConflict)
+	}
+
+	if err := session.Notify("notifications/message", map[string]any{"data": "hello"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	// Terminating the session ends the stream
+	deleted := request(http.MethodDelete, session.ID(), "")
+	deleted.Body.Close()
+	if deleted.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", deleted.StatusCode, http.StatusNoContent)
+	}
+
+	body, err := io.ReadAll(stream.Body)
+	if err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	events := readEvents(t, string(body))
+	if len(events) != 1 || events[0]["method"] != "notifications/message" {
+		t.Errorf("stream events = %v, want the notification", events)
+	}
+
+	if _, ok := sessions.Get(session.ID()); ok {
+		t.Error("DELETE did not remove the session")
+	}
+}
+
+func TestMCPHandler_SessionStreamTokenExpiry(t *testing.T) {
+	t.Parallel()
+
+	sessions := mcp.NewSessionManager(time.Hour)
+	t.Cleanup(sessions.Close)
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(&mockMCPHandler{}, sessions, responder)
+
+	claims := &oauth.TokenClaims{
+		Issuer:    "https://auth.example.com",
+		Subject:   "alice",
+		ClientID:  "client-1",
+		ExpiresAt: time.Now().Add(50 * time.Millisecond),
+	}
+	session, err := sessions.Create(mcp.SessionOwner{Issuer: claims.Issuer, Subject: claims.Subject, ClientID: claims.ClientID})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req = req.WithContext(transportcore.ContextWithClaims(req.Context(), claims))
+	req.Header.Set("Mcp-Session-Id", session.ID())
+	req.Header.Set("Accept", "text/event-stream")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mcpHandler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	// The stream ends with the token, leaving the session without a stream
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream stayed open after the token expired")
+	}
+	if err := session.Notify("notifications/message", nil); !errors.Is(err, mcp.ErrNoStream) {
+		t.Errorf("Notify() after expiry error = %v, want ErrNoStream", err)
+	}
+}
+
+func TestMCPHandler_SessionOwner(t *testing.T) {
+	t.Parallel()
+
+	var owner mcp.SessionOwner
+	handler := &mockMCPHandler{
+		handleFunc: func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+			if session, ok := mcp.SessionFromContext(ctx); ok {
+				owner = session.Owner()
+			}
+			return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}, nil
+		},
+	}
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	alice := &oauth.TokenClaims{Issuer: "https://auth.example.com", Subject: "alice", ClientID: "client-1"}
+	post := func(claims *oauth.TokenClaims, sessionID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req = req.WithContext(transportcore.ContextWithClaims(req.Context(), claims))
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		w := httptest.NewRecorder()
+		mcpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	id := post(alice, "", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`).Header().Get("Mcp-Session-Id")
+	want := mcp.SessionOwner{Issuer: "https://auth.example.com", Subject: "alice", ClientID: "client-1"}
+	if owner != want {
+		t.Fatalf("session owner = %+v, want %+v", owner, want)
+	}
+
+	tests := []struct {
+		name       string
+		claims     *oauth.TokenClaims
+		wantStatus int
+	}{
+		{name: "same caller", claims: alice, wantStatus: http.StatusOK},
+		{name: "other subject", claims: &oauth.TokenClaims{Issuer: alice.Issuer, Subject: "bob", ClientID: alice.ClientID}, wantStatus: http.StatusNotFound},
+		{name: "other client", claims: &oauth.TokenClaims{Issuer: alice.Issuer, Subject: alice.Subject, ClientID: "client-2"}, wantStatus: http.StatusNotFound},
+		{name: "other issuer", claims: &oauth.TokenClaims{Issuer: "https://other.example.com", Subject: alice.Subject, ClientID: alice.ClientID}, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := post(tt.claims, id, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`); w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestMCPHandler_ProtocolVersionHeader(t *testing.T) {
+	t.Parallel()
+
+	handler, _, _, _ := mcp.NewMCPServices(&mcp.Config{ServerName: "test", ServerVersion: "1.0.0"})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	post := func(sessionID, version, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		if version != "" {
+			req.Header.Set("MCP-Protocol-Version", version)
+		}
+		w := httptest.NewRecorder()
+		mcpHandler.ServeHTTP(w, req)
+		return w
+	}
+	initialize := func(version string) string {
+		w := post("", "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+version+`"}}`)
+		id := w.Header().Get("Mcp-Session-Id")
+		if id == "" {
+			t.Fatalf("initialize %s did not start a session: %s", version, w.Body.String())
+		}
+		return id
+	}
+
+	latest := initialize("2025-06-18")
+	legacy := initialize("2024-11-05")
+
+	tests := []struct {
+		name       string
+		sessionID  string
+		version    string
+		wantStatus int
+	}{
+		{name: "latest with header", sessionID: latest, version: "2025-06-18", wantStatus: http.StatusOK},
+		{name: "latest without header", sessionID: latest, version: "", wantStatus: http.StatusBadRequest},
+		{name: "latest with other version", sessionID: latest, version: "2025-03-26", wantStatus: http.StatusBadRequest},
+		{name: "unsupported version", sessionID: latest, version: "1999-01-01", wantStatus: http.StatusBadRequest},
+		{name: "legacy without header", sessionID: legacy, version: "", wantStatus: http.StatusOK},
+		{name: "legacy with header", sessionID: legacy, version: "2024-11-05", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := post(tt.sessionID, tt.version, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.wantStatus, w.Body.String())
+		}
+	}
+}
+
+// sessionHandler answers initialize with the MCP handler, so sessions
+// negotiate a protocol version, and other requests with handleFunc.
+type sessionHandler struct {
+	mcp.Handler
+	handleFunc    func(ctx context.Context, req *mcp.Request) (*mcp.Response, error)
+	notifications chan *mcp.Request
+}
+
+func newSessionHandler(handleFunc func(ctx context.Context, req *mcp.Request) (*mcp.Response, error)) *sessionHandler {
+	handler, _, _, _ := mcp.NewMCPServices(&mcp.Config{ServerName: "test", ServerVersion: "1.0.0"})
+	return &sessionHandler{
+		Handler:       handler,
+		handleFunc:    handleFunc,
+		notifications: make(chan *mcp.Request, 10),
+	}
+}
+
+func (h *sessionHandler) HandleRequest(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+	if req.Method == "initialize" {
+		return h.Handler.HandleRequest(ctx, req)
+	}
+	return h.handleFunc(ctx, req)
+}
+
+func (h *sessionHandler) HandleNotification(ctx context.Context, notification *mcp.Request) error {
+	h.notifications <- notification
+	return nil
+}
+
+// postSession POSTs body to handler in the session sessionID, negotiated
+// at version.
+func postSession(handler http.Handler, sessionID, version, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		req.Header.Set("MCP-Protocol-Version", version)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// initializeSession starts a session at version and returns its ID.
+func initializeSession(t *testing.T, handler http.Handler, version string) string {
+	t.Helper()
+
+	w := postSession(handler, "", "", `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"`+version+`"}}`)
+	id := w.Header().Get("Mcp-Session-Id")
+	if id == "" {
+		t.Fatalf("initialize %s did not start a session: %s", version, w.Body.String())
+	}
+	return id
+}
+
+func TestMCPHandler_Batch(t *testing.T) {
+	t.Parallel()
+
+	// Both requests must run at the same time to complete, and the first
+	// finishes last
+	var running sync.WaitGroup
+	running.Add(2)
+	handler := newSessionHandler(func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+		running.Done()
+		waited := make(chan struct{})
+		go func() { running.Wait(); close(waited) }()
+		select {
+		case <-waited:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("batch requests did not run in parallel")
+		}
+		if req.ID == float64(1) {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{"method": req.Method}}, nil
+	})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	sessionID := initializeSession(t, mcpHandler, "2025-03-26")
+
+	w := postSession(mcpHandler, sessionID, "2025-03-26", `[
+		{"jsonrpc":"2.0","id":1,"method":"tools/list"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":2,"method":"resources/list"},
+		{"jsonrpc":"2.0","id":3,"method":"initialize"},
+		42
+	]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var responses []struct {
+		ID     any            `json:"id"`
+		Result map[string]any `json:"result"`
+		Error  *mcp.Error     `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("batch response is not an array: %v: %s", err, w.Body.String())
+	}
+	if len(responses) != 4 {
+		t.Fatalf("batch responses = %s, want four responses", w.Body.String())
+	}
+	if responses[0].ID != float64(1) || responses[0].Result["method"] != "tools/list" {
+		t.Errorf("first response = %+v, want tools/list", responses[0])
+	}
+	if responses[1].ID != float64(2) || responses[1].Result["method"] != "resources/list" {
+		t.Errorf("second response = %+v, want resources/list", responses[1])
+	}
+	if responses[2].ID != float64(3) || responses[2].Error == nil || responses[2].Error.Code != mcp.CodeInvalidRequest {
+		t.Errorf("third response = %+v, want initialize rejected", responses[2])
+	}
+	if responses[3].ID != nil || responses[3].Error == nil || responses[3].Error.Code != mcp.CodeInvalidRequest {
+		t.Errorf("fourth response = %+v, want an invalid request", responses[3])
+	}
+
+	select {
+	case notification := <-handler.notifications:
+		if notification.Method != "notifications/initialized" {
+			t.Errorf("notification method = %q", notification.Method)
+		}
+	default:
+		t.Error("batched notification was not handled")
+	}
+}
+
+func TestMCPHandler_BatchRejected(t *testing.T) {
+	t.Parallel()
+
+	handler := newSessionHandler(func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+		return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}, nil
+	})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	batching := initializeSession(t, mcpHandler, "2025-03-26")
+	tests := []struct {
+		name      string
+		sessionID string
+		version   string
+		body      string
+	}{
+		{name: "without session", body: `[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`},
+		{name: "2024-11-05 session", sessionID: initializeSession(t, mcpHandler, "2024-11-05"), version: "2024-11-05", body: `[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`},
+		{name: "2025-06-18 session", sessionID: initializeSession(t, mcpHandler, "2025-06-18"), version: "2025-06-18", body: `[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`},
+		{name: "empty batch", sessionID: batching, version: "2025-03-26", body: `[]`},
+		{name: "malformed batch", sessionID: batching, version: "2025-03-26", body: `[{"jsonrpc":"2.0",`},
+		{name: "oversized batch", sessionID: batching, version: "2025-03-26", body: "[" + strings.Repeat(`{"jsonrpc":"2.0","id":1,"method":"tools/list"},`, maxBatchSize) + `{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`},
+	}
+
+	for _, tt := range tests {
+		w := postSession(mcpHandler, tt.sessionID, tt.version, tt.body)
+
+		var resp mcp.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == nil {
+			t.Errorf("%s: response = %s, want a single error", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestMCPHandler_BatchWorkers(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var running, peak int
+	handler := newSessionHandler(func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}, nil
+	})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	sessionID := initializeSession(t, mcpHandler, "2025-03-26")
+	entries := make([]string, maxBatchSize)
+	for i := range entries {
+		entries[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/list"}`, i)
+	}
+	w := postSession(mcpHandler, sessionID, "2025-03-26", "["+strings.Join(entries, ",")+"]")
+
+	var responses []mcp.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &responses); err != nil || len(responses) != maxBatchSize {
+		t.Fatalf("batch response = %s, want %d responses", w.Body.String(), maxBatchSize)
+	}
+	if peak > maxBatchWorkers {
+		t.Errorf("%d requests ran at once, want at most %d", peak, maxBatchWorkers)
+	}
+}
+
+func TestMCPHandler_BodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	handler := newSessionHandler(func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+		return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}, nil
+	})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"pad":"` + strings.Repeat("x", maxRequestBodyBytes) + `"}}`
+	w := postSession(mcpHandler, "", "", body)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("oversized body status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMCPHandler_Notification(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+	handler := newSessionHandler(func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+		requests++
+		return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}, nil
+	})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	mcpHandler := NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder)
+
+	sessionID := initializeSession(t, mcpHandler, "2025-06-18")
+	w := postSession(mcpHandler, sessionID, "2025-06-18", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("notification status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("notification response body = %q, want none", w.Body.String())
+	}
+	if requests != 0 {
+		t.Error("notification was handled as a request")
+	}
+
+	select {
+	case notification := <-handler.notifications:
+		if notification.Method != "notifications/initialized" {
+			t.Errorf("notification method = %q", notification.Method)
+		}
+	default:
+		t.Error("notification was not passed to the handler")
+	}
+}
+
+func TestMCPHandler_ClientResponse(t *testing.T) {
+	t.Parallel()
+
+	// The tool asks the client for input and returns its answer
+	handler := newSessionHandler(func(ctx context.Context, req *mcp.Request) (*mcp.Response, error) {
+		result, err := mcp.Call(ctx, "elicitation/create", map[string]any{"message": "Name?"})
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.Response{JSONRPC: "2.0", ID: req.ID, Result: result}, nil
+	})
+	responder := &mocks.ErrorResponder{MetadataURL: "https://example.com/.well-known/oauth-protected-resource"}
+	ts := httptest.NewServer(NewMCPHandler(handler, mcp.NewSessionManager(time.Hour), responder))
+	t.Cleanup(ts.Close)
+
+	sessionID := initializeSession(t, ts.Config.Handler, "2025-06-18")
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	req.Header.Set("MCP-Protocol-Version", "2025-06-18")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The server's request arrives on the response stream
+	reader := bufio.NewReader(resp.Body)
+	var serverRequest map[string]any
+	for serverRequest == nil {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the server request: %v", err)
+		}
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: "); ok {
+			if err := json.Unmarshal([]byte(data), &serverRequest); err != nil {
+				t.Fatalf("invalid event data %q: %v", data, err)
+			}
+		}
+	}
+	if serverRequest["method"] != "elicitation/create" {
+		t.Fatalf("server request = %v, want elicitation/create", serverRequest)
+	}
+
+	answer, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      serverRequest["id"],
+		"result":  map[string]any{"action": "accept", "content": map[string]any{"name": "alice"}},
+	})
+	w := postSession(ts.Config.Handler, sessionID, "2025-06-18", string(answer))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("client response status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	events := readEvents(t, string(rest))
+	if len(events) != 1 {
+		t.Fatalf("events = %v, want the tools/call response", events)
+	}
+	result, _ := events[0]["result"].(map[string]any)
+	if result["action"] != "accept" {
+		t.Errorf("tools/call response = %v, want the client's answer", events[0])
+	}
+}
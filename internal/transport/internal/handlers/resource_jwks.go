@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/transportcore"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// resourceJWKSHandler serves the protected resource's own public keys.
+type resourceJWKSHandler struct {
+	keySet    oauth.ResourceKeySet
+	responder transportcore.ErrorResponder
+}
+
+// NewResourceJWKSHandler creates a handler for the resource's jwks_uri.
+// It serves the public keys clients use to verify signed_metadata.
+func NewResourceJWKSHandler(keySet oauth.ResourceKeySet, responder transportcore.ErrorResponder) http.Handler {
+	if keySet == nil {
+		panic("keySet cannot be nil")
+	}
+	if responder == nil {
+		panic("responder cannot be nil")
+	}
+
+	return &resourceJWKSHandler{
+		keySet:    keySet,
+		responder: responder,
+	}
+}
+
+// ServeHTTP handles GET requests for the resource key set.
+// Only GET method is allowed.
+func (h *resourceJWKSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET method
+	if r.Method != http.MethodGet {
+		// Method not allowed - return 405
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	jwks := h.keySet.JWKS()
+	if jwks == nil {
+		h.responder.InternalError(w, errors.New("resource has no signing key"))
+		return
+	}
+
+	// Set response headers; keys change rarely, so let clients cache briefly
+	w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(jwks); err != nil {
+		slog.Error("failed to encode resource jwks", "error", err)
+		// Can't send error response here since headers are already written
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/internal/mocks"
+)
+
+// mockResourceKeySet implements oauth.ResourceKeySet for testing.
+type mockResourceKeySet struct {
+	jwks *oauth.JSONWebKeySet
+}
+
+func (m *mockResourceKeySet) JWKS() *oauth.JSONWebKeySet {
+	return m.jwks
+}
+
+func TestResourceJWKSHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		method          string
+		jwks            *oauth.JSONWebKeySet
+		wantStatus      int
+		wantInternal    bool
+		wantKeyIDs      []string
+		wantAllowHeader bool
+	}{
+		{
+			name:       "GET returns key set",
+			method:     http.MethodGet,
+			jwks:       testKeySet("metadata-key"),
+			wantStatus: http.StatusOK,
+			wantKeyIDs: []string{"metadata-key"},
+		},
+		{
+			name:         "no signing key",
+			method:       http.MethodGet,
+			jwks:         nil,
+			wantStatus:   http.StatusInternalServerError,
+			wantInternal: true,
+		},
+		{
+			name:            "POST not allowed",
+			method:          http.MethodPost,
+			jwks:            testKeySet("metadata-key"),
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantAllowHeader: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			responder := &mocks.ErrorResponder{}
+			handler := NewResourceJWKSHandler(&mockResourceKeySet{jwks: tt.jwks}, responder)
+
+			req := httptest.NewRequest(tt.method, "/.well-known/jwks.json", nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if responder.InternalCalled != tt.wantInternal {
+				t.Errorf("InternalError called = %v, want %v", responder.InternalCalled, tt.wantInternal)
+			}
+			if tt.wantAllowHeader && w.Header().Get("Allow") != http.MethodGet {
+				t.Errorf("Allow = %q, want %q", w.Header().Get("Allow"), http.MethodGet)
+			}
+			if tt.wantKeyIDs == nil {
+				return
+			}
+
+			var got oauth.JSONWebKeySet
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(got.Keys) != len(tt.wantKeyIDs) {
+				t.Fatalf("keys = %d, want %d", len(got.Keys), len(tt.wantKeyIDs))
+			}
+			for i, kid := range tt.wantKeyIDs {
+				if got.Keys[i].Kid != kid {
+					t.Errorf("keys[%d].kid = %q, want %q", i, got.Keys[i].Kid, kid)
+				}
+			}
+		})
+	}
+}
+
+func TestNewResourceJWKSHandler_PanicsOnNil(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewResourceJWKSHandler(nil, ...) should panic")
+		}
+	}()
+	NewResourceJWKSHandler(nil, &mocks.ErrorResponder{})
+}
+
+// testKeySet returns a key set containing one EC key with the given kid.
+func testKeySet(kid string) *oauth.JSONWebKeySet {
+	return &oauth.JSONWebKeySet{Keys: []oauth.JSONWebKey{
+		{Kty: "EC", Kid: kid, Use: "sig", Alg: "ES256", Crv: "P-256", X: "AA", Y: "AA"},
+	}}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// eventStream writes JSON-RPC messages to the client as server-sent events.
+// The response headers are written with the first event, so a response
+// can still be sent as plain JSON until then. It implements mcp.Sender.
+type eventStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+	closed  bool
+}
+
+// newEventStream creates an event stream writing to w.
+func newEventStream(w http.ResponseWriter) *eventStream {
+	return &eventStream{
+		w:  w,
+		rc: http.NewResponseController(w),
+	}
+}
+
+// Send writes msg as a message event.
+// Returns mcp.ErrStreamClosed once the stream is closed.
+func (s *eventStream) Send(msg any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return mcp.ErrStreamClosed
+	}
+	return s.writeEvent(msg)
+}
+
+// open writes the stream headers without an event, for streams that only
+// carry server-initiated messages.
+func (s *eventStream) open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.start()
+	return s.rc.Flush()
+}
+
+// finish writes the final response of a request, or the array of
+// responses of a batch, and closes the stream. If no event was sent, resp
+// is written as a plain JSON body instead.
+func (s *eventStream) finish(resp any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.started {
+		return s.writeEvent(resp)
+	}
+
+	s.w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+	s.w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(s.w).Encode(resp)
+}
+
+// close ends the stream. Later sends fail with mcp.ErrStreamClosed.
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+// start writes the stream headers once. Streams outlive the server's write
+// timeout, so the deadline is lifted where the writer supports it.
+func (s *eventStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+
+	_ = s.rc.SetWriteDeadline(time.Time{})
+
+	s.w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeEventStream)
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// writeEvent writes msg as a message event and flushes it to the client.
+func (s *eventStream) writeEvent(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	s.start()
+	if _, err := fmt.Fprintf(s.w, "event: message\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// acceptsEventStream reports whether the request's Accept header allows a
+// text/event-stream response.
+func acceptsEventStream(r *http.Request) bool {
+	for _, value := range r.Header.Values(pkgoauth.HeaderAccept) {
+		for _, mediaType := range strings.Split(value, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), pkgoauth.ContentTypeEventStream) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -32,6 +32,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Unwrap returns the wrapped writer, so http.ResponseController can flush
+// streaming responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // NewLoggingMiddleware creates middleware that logs HTTP requests.
 // It logs the request method, path, status code, and duration using structured logging.
 // If logger is nil, it uses the default slog logger.
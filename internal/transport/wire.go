@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/jamesprial/mcp-oauth-2.1/internal/config"
 	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
@@ -33,9 +35,13 @@ func NewAuthMiddleware(
 	responder ErrorResponder,
 	metadataURL string,
 ) AuthMiddleware {
-	// Use default scopes for authentication
-	defaultScopes := []string{pkgoauth.ScopeRead}
-	return middleware.NewAuthMiddleware(validator, responder, metadataURL, defaultScopes)
+	return middleware.NewAuthMiddleware(validator, responder, metadataURL, DefaultScopes())
+}
+
+// DefaultScopes returns the scopes the authentication middleware requires
+// on every protected route.
+func DefaultScopes() []string {
+	return []string{pkgoauth.ScopeRead}
 }
 
 // NewErrorResponder creates an error responder with the given metadata URL.
@@ -45,15 +51,28 @@ func NewErrorResponder(metadataURL string) ErrorResponder {
 }
 
 // NewMetadataHandler creates the OAuth protected resource metadata handler.
-// It serves metadata at /.well-known/oauth-protected-resource per RFC 9728.
+// It serves metadata at the resource's well-known location per RFC 9728.
 func NewMetadataHandler(service oauth.MetadataService, responder ErrorResponder) http.Handler {
 	return handlers.NewMetadataHandler(service, responder)
 }
 
+// NewResourceJWKSHandler creates the handler serving the resource's own key set.
+// It publishes the keys that verify signed protected resource metadata.
+func NewResourceJWKSHandler(keySet oauth.ResourceKeySet, responder ErrorResponder) http.Handler {
+	return handlers.NewResourceJWKSHandler(keySet, responder)
+}
+
+// NewAuthorizationServersHandler creates the handler aggregating the metadata
+// of the trusted authorization servers, with their health and capabilities.
+func NewAuthorizationServersHandler(directory oauth.AuthorizationServerDirectory) http.Handler {
+	return handlers.NewAuthorizationServersHandler(directory)
+}
+
 // NewMCPHandler creates the MCP protocol handler.
-// It handles JSON-RPC requests at the configured MCP endpoint.
-func NewMCPHandler(handler mcp.Handler, responder ErrorResponder) http.Handler {
-	return handlers.NewMCPHandler(handler, responder)
+// It serves the MCP Streamable HTTP transport at the configured MCP
+// endpoint, tracking the sessions started by initialize in sessions.
+func NewMCPHandler(handler mcp.Handler, sessions mcp.SessionManager, responder ErrorResponder) http.Handler {
+	return handlers.NewMCPHandler(handler, sessions, responder)
 }
 
 // NewHealthHandler creates the health check handler.
@@ -62,6 +81,12 @@ func NewHealthHandler(responder ErrorResponder) http.Handler {
 	return handlers.NewHealthHandler(responder)
 }
 
+// NewJWKSAdminHandler creates the JWKS administration handler.
+// It lists per-issuer key set state and refreshes a single issuer on demand.
+func NewJWKSAdminHandler(inspector oauth.JWKSInspector, responder ErrorResponder) http.Handler {
+	return handlers.NewJWKSAdminHandler(inspector, responder)
+}
+
 // NewLoggingMiddleware creates request logging middleware.
 // It logs HTTP request details using structured logging.
 // If logger is nil, it uses the default slog logger.
@@ -87,8 +112,73 @@ type Config struct {
 	// MetadataService provides protected resource metadata.
 	MetadataService oauth.MetadataService
 
+	// ProtectedResources are additional protected resources served next to
+	// the primary one (optional). Each serves MCPHandler at the path of its
+	// resource identifier and publishes its metadata at the path-specific
+	// location derived from that identifier.
+	ProtectedResources []ProtectedResource
+
 	// MCPHandler processes MCP protocol requests.
 	MCPHandler mcp.Handler
+
+	// Sessions tracks the MCP sessions of the Streamable HTTP transport
+	// (optional). A new in-memory session manager expiring sessions after
+	// ServerConfig.SessionTTL is used when nil.
+	Sessions mcp.SessionManager
+
+	// JWKSInspector exposes JWKS state at /admin/jwks (optional).
+	// The endpoint requires a token with the mcp:admin scope.
+	JWKSInspector oauth.JWKSInspector
+
+	// Metrics serves the server's metrics at /admin/metrics (optional).
+	// The endpoint requires a token with the mcp:admin scope.
+	Metrics http.Handler
+
+	// ResourceKeySet publishes the resource's signing keys (optional).
+	// It is served at ResourceJWKSPath, which must be set with it.
+	ResourceKeySet oauth.ResourceKeySet
+
+	// ResourceJWKSPath is the path of the resource's jwks_uri.
+	ResourceJWKSPath string
+
+	// AuthorizationServerDirectory publishes the cached metadata of the
+	// trusted authorization servers (optional). It is served at
+	// AuthorizationServersPath, which must be set with it.
+	AuthorizationServerDirectory oauth.AuthorizationServerDirectory
+
+	// AuthorizationServersPath is the path of the authorization server
+	// metadata aggregation endpoint.
+	AuthorizationServersPath string
+
+	// DevAuthorizationServer is the embedded development authorization
+	// server (optional). It is mounted below DevAuthorizationServerPath,
+	// which must be set with it.
+	DevAuthorizationServer http.Handler
+
+	// DevAuthorizationServerPath is the issuer path of the development
+	// authorization server.
+	DevAuthorizationServerPath string
+
+	// Middleware wraps every route after the built-in recovery and logging
+	// middleware (optional). The first middleware is the outermost layer.
+	Middleware []Middleware
+}
+
+// ProtectedResource is an additional protected resource. Its endpoint only
+// accepts tokens issued for it, and its 401 responses point clients at its
+// own metadata document.
+type ProtectedResource struct {
+	// MetadataService describes the resource.
+	MetadataService oauth.MetadataService
+
+	// TokenValidator validates access tokens for the resource.
+	TokenValidator oauth.TokenValidator
+
+	// Sessions tracks the MCP sessions of the resource (optional). A new
+	// in-memory session manager expiring sessions after
+	// ServerConfig.SessionTTL is used when nil. Sessions are not shared
+	// between resources.
+	Sessions mcp.SessionManager
 }
 
 // NewTransportServices creates all transport layer services from the configuration.
@@ -110,6 +200,20 @@ func NewTransportServices(cfg *Config) (Server, Router, error) {
 	if cfg.MCPHandler == nil {
 		return nil, nil, fmt.Errorf("mcp handler cannot be nil")
 	}
+	if cfg.ResourceKeySet != nil && cfg.ResourceJWKSPath == "" {
+		return nil, nil, fmt.Errorf("resource jwks path cannot be empty")
+	}
+	if cfg.AuthorizationServerDirectory != nil && cfg.AuthorizationServersPath == "" {
+		return nil, nil, fmt.Errorf("authorization servers path cannot be empty")
+	}
+	if cfg.DevAuthorizationServer != nil && strings.TrimRight(cfg.DevAuthorizationServerPath, "/") == "" {
+		return nil, nil, fmt.Errorf("dev authorization server path cannot be empty")
+	}
+	for _, resource := range cfg.ProtectedResources {
+		if resource.TokenValidator == nil {
+			return nil, nil, fmt.Errorf("resource token validator cannot be nil")
+		}
+	}
 
 	// Get metadata URL from service
 	metadataURL := cfg.MetadataService.GetMetadataURL()
@@ -124,7 +228,11 @@ func NewTransportServices(cfg *Config) (Server, Router, error) {
 
 	// Create handlers
 	metadataHandler := NewMetadataHandler(cfg.MetadataService, responder)
-	mcpHandler := NewMCPHandler(cfg.MCPHandler, responder)
+	sessions := cfg.Sessions
+	if sessions == nil {
+		sessions = mcp.NewSessionManager(cfg.ServerConfig.SessionTTL)
+	}
+	mcpHandler := NewMCPHandler(cfg.MCPHandler, sessions, responder)
 	healthHandler := NewHealthHandler(responder)
 
 	// Create router
@@ -132,19 +240,174 @@ func NewTransportServices(cfg *Config) (Server, Router, error) {
 
 	// Apply global middleware
 	router.Use(recoveryMiddleware, loggingMiddleware)
+	router.Use(cfg.Middleware...)
+
+	// Register metadata routes at each resource's RFC 9728 location.
+	// The root location falls back to the primary resource so clients that
+	// only probe /.well-known/oauth-protected-resource still discover it.
+	resourceMetadata := make([]oauth.MetadataService, len(cfg.ProtectedResources))
+	for i, resource := range cfg.ProtectedResources {
+		resourceMetadata[i] = resource.MetadataService
+	}
+	routes, err := metadataRoutes(cfg.MetadataService, resourceMetadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	for path, service := range routes {
+		if service == cfg.MetadataService {
+			router.Handle("GET "+path, metadataHandler)
+			continue
+		}
+		router.Handle("GET "+path, NewMetadataHandler(service, responder))
+	}
+	if _, ok := routes[wellKnownPath]; !ok {
+		router.Handle("GET "+wellKnownPath, metadataHandler)
+	}
 
 	// Register routes
 	// Public endpoints (no auth required)
-	router.Handle("GET /.well-known/oauth-protected-resource", metadataHandler)
 	router.Handle("GET /health", healthHandler)
+	if cfg.ResourceKeySet != nil {
+		router.Handle("GET "+cfg.ResourceJWKSPath, NewResourceJWKSHandler(cfg.ResourceKeySet, responder))
+	}
+	if cfg.AuthorizationServerDirectory != nil {
+		router.Handle("GET "+cfg.AuthorizationServersPath, NewAuthorizationServersHandler(cfg.AuthorizationServerDirectory))
+	}
+	if cfg.DevAuthorizationServer != nil {
+		// Everything below the issuer path, plus the RFC 8414 metadata location
+		devPath := strings.TrimRight(cfg.DevAuthorizationServerPath, "/")
+		router.Handle(devPath+"/", cfg.DevAuthorizationServer)
+		router.Handle("GET /.well-known/oauth-authorization-server"+devPath, cfg.DevAuthorizationServer)
+	}
 
 	// Protected endpoints (auth required)
-	// Apply authentication middleware for MCP endpoint
+	// Apply authentication middleware for MCP endpoint: requests, the
+	// session stream and session termination
 	authenticatedMCP := authMiddleware.Authenticate()(mcpHandler)
 	router.Handle("POST /mcp", authenticatedMCP)
+	router.Handle("GET /mcp", authenticatedMCP)
+	router.Handle("DELETE /mcp", authenticatedMCP)
+
+	// Each additional resource is served at its own path, authenticated
+	// against its own audience and metadata
+	reserved := reservedPaths(cfg)
+	mounted := make(map[string]bool, len(cfg.ProtectedResources))
+	for _, resource := range cfg.ProtectedResources {
+		path, err := resourcePath(resource.MetadataService)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reserved(path) || mounted[path] {
+			return nil, nil, fmt.Errorf("protected resource path %s conflicts with another endpoint", path)
+		}
+		mounted[path] = true
+
+		resourceMetadataURL := resource.MetadataService.GetMetadataURL()
+		resourceResponder := NewErrorResponder(resourceMetadataURL)
+		resourceSessions := resource.Sessions
+		if resourceSessions == nil {
+			resourceSessions = mcp.NewSessionManager(cfg.ServerConfig.SessionTTL)
+		}
+		resourceAuth := NewAuthMiddleware(resource.TokenValidator, resourceResponder, resourceMetadataURL)
+		resourceMCP := resourceAuth.Authenticate()(NewMCPHandler(cfg.MCPHandler, resourceSessions, resourceResponder))
+		router.Handle("POST "+path, resourceMCP)
+		router.Handle("GET "+path, resourceMCP)
+		router.Handle("DELETE "+path, resourceMCP)
+	}
+
+	// Admin endpoints (auth and admin scope required)
+	if cfg.JWKSInspector != nil {
+		jwksAdminHandler := NewJWKSAdminHandler(cfg.JWKSInspector, responder)
+		adminJWKS := authMiddleware.Authenticate()(
+			authMiddleware.RequireScopes(pkgoauth.ScopeAdmin)(jwksAdminHandler),
+		)
+		router.Handle("GET /admin/jwks", adminJWKS)
+		router.Handle("POST /admin/jwks", adminJWKS)
+	}
+	if cfg.Metrics != nil {
+		router.Handle("GET /admin/metrics", authMiddleware.Authenticate()(
+			authMiddleware.RequireScopes(pkgoauth.ScopeAdmin)(cfg.Metrics),
+		))
+	}
 
 	// Create server
 	server := NewServer(cfg.ServerConfig, router)
 
 	return server, router, nil
 }
+
+// wellKnownPath is the root protected resource metadata location.
+const wellKnownPath = "/.well-known/oauth-protected-resource"
+
+// metadataRoutes maps the request path of each metadata document to its service.
+// Two resources sharing one metadata location is a configuration error.
+func metadataRoutes(primary oauth.MetadataService, additional []oauth.MetadataService) (map[string]oauth.MetadataService, error) {
+	routes := make(map[string]oauth.MetadataService, len(additional)+1)
+	for _, service := range append([]oauth.MetadataService{primary}, additional...) {
+		if service == nil {
+			return nil, fmt.Errorf("resource metadata service cannot be nil")
+		}
+
+		parsed, err := url.Parse(service.GetMetadataURL())
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata URL %q: %w", service.GetMetadataURL(), err)
+		}
+		path := parsed.Path
+		if path == "" {
+			path = wellKnownPath
+		}
+
+		if _, exists := routes[path]; exists {
+			return nil, fmt.Errorf("duplicate protected resource metadata path %s", path)
+		}
+		routes[path] = service
+	}
+	return routes, nil
+}
+
+// resourcePath returns the path an additional protected resource is served
+// at: the path of its resource identifier, recovered from its metadata URL.
+// A resource at the root of the origin cannot be served next to the others.
+func resourcePath(service oauth.MetadataService) (string, error) {
+	parsed, err := url.Parse(service.GetMetadataURL())
+	if err != nil {
+		return "", fmt.Errorf("invalid metadata URL %q: %w", service.GetMetadataURL(), err)
+	}
+
+	path := strings.TrimRight(strings.TrimPrefix(parsed.Path, wellKnownPath), "/")
+	if path == "" || path == parsed.Path {
+		return "", fmt.Errorf("protected resource with metadata URL %s must name a sub-path", service.GetMetadataURL())
+	}
+	if strings.ContainsAny(path, "{}") {
+		return "", fmt.Errorf("protected resource path %s must not contain braces", path)
+	}
+	return path, nil
+}
+
+// reservedPaths reports whether a path is taken by an endpoint of the server
+// configured by cfg, so that no protected resource is mounted over it.
+func reservedPaths(cfg *Config) func(path string) bool {
+	exact := map[string]bool{
+		"/mcp":                       true,
+		"/health":                    true,
+		cfg.ResourceJWKSPath:         true,
+		cfg.AuthorizationServersPath: true,
+	}
+	prefixes := []string{"/.well-known/", "/admin/"}
+	if devPath := strings.TrimRight(cfg.DevAuthorizationServerPath, "/"); devPath != "" {
+		exact[devPath] = true
+		prefixes = append(prefixes, devPath+"/")
+	}
+
+	return func(path string) bool {
+		if exact[path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,353 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/config"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport/internal/mocks"
+)
+
+// metadataFor returns a mock metadata service for resource served at metadataURL.
+func metadataFor(resource, metadataURL string) *mocks.MetadataService {
+	return &mocks.MetadataService{
+		GetMetadataFunc: func(ctx context.Context) (*oauth.ProtectedResourceMetadata, error) {
+			return &oauth.ProtectedResourceMetadata{
+				Resource:             resource,
+				AuthorizationServers: []string{"https://auth.example.com"},
+			}, nil
+		},
+		GetMetadataURLFunc: func() string { return metadataURL },
+	}
+}
+
+// testTransportConfig returns a transport configuration with mock services.
+func testTransportConfig(primary oauth.MetadataService, additional ...oauth.MetadataService) *Config {
+	cfg := &Config{
+		ServerConfig: &config.Config{
+			Addr:         ":0",
+			ReadTimeout:  time.Second,
+			WriteTimeout: time.Second,
+		},
+		OAuthValidator:  &mocks.TokenValidator{},
+		MetadataService: primary,
+		MCPHandler:      &mocks.MCPHandler{},
+	}
+	for _, service := range additional {
+		cfg.ProtectedResources = append(cfg.ProtectedResources, ProtectedResource{
+			MetadataService: service,
+			TokenValidator:  &mocks.TokenValidator{},
+		})
+	}
+	return cfg
+}
+
+func TestNewTransportServices_MetadataRoutes(t *testing.T) {
+	t.Parallel()
+
+	cfg := testTransportConfig(
+		metadataFor("https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"),
+		metadataFor("https://example.com/reports", "https://example.com/.well-known/oauth-protected-resource/reports"),
+	)
+
+	_, router, err := NewTransportServices(cfg)
+	if err != nil {
+		t.Fatalf("NewTransportServices() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path         string
+		wantStatus   int
+		wantResource string
+	}{
+		{path: "/.well-known/oauth-protected-resource/mcp", wantStatus: http.StatusOK, wantResource: "https://example.com/mcp"},
+		{path: "/.well-known/oauth-protected-resource/reports", wantStatus: http.StatusOK, wantResource: "https://example.com/reports"},
+		{path: "/.well-known/oauth-protected-resource", wantStatus: http.StatusOK, wantResource: "https://example.com/mcp"},
+		{path: "/.well-known/oauth-protected-resource/unknown", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantResource == "" {
+				return
+			}
+
+			var got oauth.ProtectedResourceMetadata
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode metadata: %v", err)
+			}
+			if got.Resource != tt.wantResource {
+				t.Errorf("resource = %q, want %q", got.Resource, tt.wantResource)
+			}
+		})
+	}
+}
+
+func TestNewTransportServices_DuplicateMetadataPath(t *testing.T) {
+	t.Parallel()
+
+	metadataURL := "https://example.com/.well-known/oauth-protected-resource/mcp"
+	cfg := testTransportConfig(
+		metadataFor("https://example.com/mcp", metadataURL),
+		metadataFor("https://example.com/mcp", metadataURL),
+	)
+
+	if _, _, err := NewTransportServices(cfg); err == nil {
+		t.Error("NewTransportServices() expected error for duplicate metadata path, got nil")
+	}
+}
+
+func TestNewTransportServices_ProtectedResources(t *testing.T) {
+	t.Parallel()
+
+	const reportsMetadataURL = "https://example.com/.well-known/oauth-protected-resource/reports"
+	cfg := testTransportConfig(
+		metadataFor("https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"),
+		metadataFor("https://example.com/reports", reportsMetadataURL),
+	)
+	cfg.ProtectedResources[0].TokenValidator = &mocks.TokenValidator{
+		ValidateFunc: func(ctx context.Context, token string) (*oauth.TokenClaims, error) {
+			if token != "reports-token" {
+				return nil, oauth.ErrInvalidAudience
+			}
+			return &oauth.TokenClaims{Subject: "alice", Scopes: []string{"mcp:read"}}, nil
+		},
+	}
+
+	_, router, err := NewTransportServices(cfg)
+	if err != nil {
+		t.Fatalf("NewTransportServices() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "token for another resource", token: "mcp-token", wantStatus: http.StatusUnauthorized},
+		{name: "token for the resource", token: "reports-token", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/reports", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("POST /reports status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && !strings.Contains(w.Header().Get("WWW-Authenticate"), reportsMetadataURL) {
+				t.Errorf("WWW-Authenticate = %q, want resource_metadata of the reports resource", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestNewTransportServices_ProtectedResourcePathErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		metadataURL string
+	}{
+		{name: "root resource", metadataURL: "https://example.com/.well-known/oauth-protected-resource"},
+		{name: "mcp endpoint", metadataURL: "https://example.com/.well-known/oauth-protected-resource/mcp"},
+		{name: "admin endpoint", metadataURL: "https://example.com/.well-known/oauth-protected-resource/admin/jwks"},
+		{name: "well-known location", metadataURL: "https://example.com/.well-known/oauth-protected-resource/.well-known/reports"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := testTransportConfig(
+				metadataFor("https://example.com/api", "https://example.com/.well-known/oauth-protected-resource/api"),
+				metadataFor("https://example.com", tt.metadataURL),
+			)
+			if _, _, err := NewTransportServices(cfg); err == nil {
+				t.Errorf("NewTransportServices() expected error for metadata URL %s, got nil", tt.metadataURL)
+			}
+		})
+	}
+}
+
+// mockDirectory implements oauth.AuthorizationServerDirectory for testing.
+type mockDirectory []oauth.AuthorizationServerStatus
+
+func (m mockDirectory) ServerMetadata(ctx context.Context) []oauth.AuthorizationServerStatus {
+	return m
+}
+
+func TestNewTransportServices_AuthorizationServers(t *testing.T) {
+	t.Parallel()
+
+	cfg := testTransportConfig(metadataFor("https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"))
+	cfg.AuthorizationServerDirectory = mockDirectory{{Issuer: "https://auth.example.com", Healthy: true}}
+	cfg.AuthorizationServersPath = "/.well-known/oauth-authorization-servers"
+
+	_, router, err := NewTransportServices(cfg)
+	if err != nil {
+		t.Fatalf("NewTransportServices() unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/oauth-authorization-servers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (endpoint must not require authentication)", w.Code, http.StatusOK)
+	}
+
+	var got struct {
+		AuthorizationServers []oauth.AuthorizationServerStatus `json:"authorization_servers"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.AuthorizationServers) != 1 || got.AuthorizationServers[0].Issuer != "https://auth.example.com" {
+		t.Errorf("authorization_servers = %+v, want the configured server", got.AuthorizationServers)
+	}
+}
+
+func TestNewTransportServices_AuthorizationServersRequiresPath(t *testing.T) {
+	t.Parallel()
+
+	cfg := testTransportConfig(metadataFor("https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"))
+	cfg.AuthorizationServerDirectory = mockDirectory{}
+
+	if _, _, err := NewTransportServices(cfg); err == nil {
+		t.Error("NewTransportServices() expected error for missing authorization servers path, got nil")
+	}
+}
+
+func TestNewTransportServices_DevAuthorizationServer(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	cfg := testTransportConfig(metadataFor("http://localhost:8080/mcp", "http://localhost:8080/.well-known/oauth-protected-resource/mcp"))
+	cfg.DevAuthorizationServer = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+	})
+	cfg.DevAuthorizationServerPath = "/dev-as"
+
+	_, router, err := NewTransportServices(cfg)
+	if err != nil {
+		t.Fatalf("NewTransportServices() unexpected error: %v", err)
+	}
+
+	// Every endpoint is public and reaches the development server unchanged
+	requests := []struct{ method, path string }{
+		{http.MethodGet, "/.well-known/oauth-authorization-server/dev-as"},
+		{http.MethodGet, "/dev-as/.well-known/oauth-authorization-server"},
+		{http.MethodGet, "/dev-as/authorize"},
+		{http.MethodPost, "/dev-as/token"},
+		{http.MethodPost, "/dev-as/register"},
+	}
+	for _, req := range requests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(req.method, req.path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s %s status = %d, want %d", req.method, req.path, w.Code, http.StatusOK)
+		}
+	}
+	if len(paths) != len(requests) {
+		t.Errorf("dev server received %v, want %d requests", paths, len(requests))
+	}
+
+	cfg.DevAuthorizationServerPath = ""
+	if _, _, err := NewTransportServices(cfg); err == nil {
+		t.Error("NewTransportServices() expected error for missing dev server path, got nil")
+	}
+}
+
+func TestNewTransportServices_Middleware(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	cfg := testTransportConfig(metadataFor("https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"))
+	cfg.Middleware = []Middleware{tag("outer"), tag("inner")}
+
+	_, router, err := NewTransportServices(cfg)
+	if err != nil {
+		t.Fatalf("NewTransportServices() unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /health status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("middleware order = %v, want [outer inner]", order)
+	}
+}
+
+func TestNewTransportServices_Metrics(t *testing.T) {
+	t.Parallel()
+
+	cfg := testTransportConfig(metadataFor("https://example.com/mcp", "https://example.com/.well-known/oauth-protected-resource/mcp"))
+	cfg.OAuthValidator = &mocks.TokenValidator{
+		ValidateFunc: func(ctx context.Context, token string) (*oauth.TokenClaims, error) {
+			scopes := []string{"mcp:read"}
+			if token == "admin" {
+				scopes = append(scopes, "mcp:admin")
+			}
+			return &oauth.TokenClaims{Subject: "operator", Scopes: scopes}, nil
+		},
+	}
+	cfg.Metrics = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"config_reloads":{}}`))
+	})
+
+	_, router, err := NewTransportServices(cfg)
+	if err != nil {
+		t.Fatalf("NewTransportServices() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		token      string
+		wantStatus int
+	}{
+		{token: "", wantStatus: http.StatusUnauthorized},
+		{token: "reader", wantStatus: http.StatusForbidden},
+		{token: "admin", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin/metrics", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET /admin/metrics with token %q status = %d, want %d", tt.token, w.Code, tt.wantStatus)
+		}
+	}
+}
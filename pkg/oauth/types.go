@@ -59,6 +59,16 @@ const (
 
 	// HeaderContentType is the Content-Type HTTP header name.
 	HeaderContentType = "Content-Type"
+
+	// HeaderAccept is the Accept HTTP header name.
+	HeaderAccept = "Accept"
+
+	// HeaderMCPSessionID is the MCP Streamable HTTP session header name.
+	HeaderMCPSessionID = "Mcp-Session-Id"
+
+	// HeaderMCPProtocolVersion is the header carrying the negotiated MCP
+	// protocol version on requests after initialize.
+	HeaderMCPProtocolVersion = "MCP-Protocol-Version"
 )
 
 // Content type constants.
@@ -68,4 +78,8 @@ const (
 
 	// ContentTypeFormURLEncoded is the application/x-www-form-urlencoded content type.
 	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
+
+	// ContentTypeEventStream is the text/event-stream content type of
+	// server-sent events.
+	ContentTypeEventStream = "text/event-stream"
 )
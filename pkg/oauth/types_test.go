@@ -222,6 +222,24 @@ func TestHeaderConstants(t *testing.T) {
 			want:     "Content-Type",
 			constant: "HeaderContentType",
 		},
+		{
+			name:     "HeaderAccept",
+			got:      HeaderAccept,
+			want:     "Accept",
+			constant: "HeaderAccept",
+		},
+		{
+			name:     "HeaderMCPSessionID",
+			got:      HeaderMCPSessionID,
+			want:     "Mcp-Session-Id",
+			constant: "HeaderMCPSessionID",
+		},
+		{
+			name:     "HeaderMCPProtocolVersion",
+			got:      HeaderMCPProtocolVersion,
+			want:     "MCP-Protocol-Version",
+			constant: "HeaderMCPProtocolVersion",
+		},
 	}
 
 	for _, tt := range tests {
@@ -255,6 +273,12 @@ func TestContentTypeConstants(t *testing.T) {
 			want:     "application/x-www-form-urlencoded",
 			constant: "ContentTypeFormURLEncoded",
 		},
+		{
+			name:     "ContentTypeEventStream",
+			got:      ContentTypeEventStream,
+			want:     "text/event-stream",
+			constant: "ContentTypeEventStream",
+		},
 	}
 
 	for _, tt := range tests {
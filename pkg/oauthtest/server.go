@@ -0,0 +1,286 @@
+package oauthtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// Endpoint identifies an endpoint of the fake authorization server.
+type Endpoint string
+
+// Endpoints served by AuthorizationServer.
+const (
+	// EndpointMetadata is the RFC 8414 metadata document.
+	EndpointMetadata Endpoint = "/.well-known/oauth-authorization-server"
+
+	// EndpointJWKS is the JSON Web Key Set.
+	EndpointJWKS Endpoint = "/jwks"
+)
+
+// Fault alters the responses of an endpoint.
+type Fault struct {
+	// Delay is waited before responding, or until the request is cancelled.
+	Delay time.Duration
+
+	// Status replaces the response status code when non-zero.
+	Status int
+
+	// Body replaces the response body when non-empty.
+	Body string
+
+	// Times limits the fault to the next Times requests. Zero applies it
+	// until ClearFaults is called.
+	Times int
+}
+
+// SlowResponse delays responses by d.
+func SlowResponse(d time.Duration) Fault {
+	return Fault{Delay: d}
+}
+
+// ServerError answers with 500 Internal Server Error.
+func ServerError() Fault {
+	return Fault{Status: http.StatusInternalServerError, Body: `{"error":"server_error"}`}
+}
+
+// MalformedJSON answers 200 OK with a body that is not valid JSON.
+func MalformedJSON() Fault {
+	return Fault{Body: `{"keys": [`}
+}
+
+// Option configures an AuthorizationServer.
+type Option func(*AuthorizationServer)
+
+// WithKeys publishes keys instead of a generated RSA key. The first key
+// signs tokens.
+func WithKeys(keys ...*Key) Option {
+	return func(s *AuthorizationServer) {
+		s.keys = append([]*Key(nil), keys...)
+	}
+}
+
+// WithMetadata adds or replaces fields of the metadata document.
+func WithMetadata(fields map[string]any) Option {
+	return func(s *AuthorizationServer) {
+		for name, value := range fields {
+			s.extraMetadata[name] = value
+		}
+	}
+}
+
+// AuthorizationServer is an in-process fake authorization server serving
+// RFC 8414 metadata and a JWKS over HTTP. The server is closed when the
+// test finishes.
+type AuthorizationServer struct {
+	// Server is the underlying test server. Its URL is the issuer.
+	*httptest.Server
+
+	t             testing.TB
+	mu            sync.Mutex
+	keys          []*Key // published keys; keys[0] signs tokens
+	extraMetadata map[string]any
+	faults        map[Endpoint]*Fault
+	requests      map[Endpoint]int
+	nextKeyID     int
+}
+
+// NewAuthorizationServer starts a fake authorization server. Without
+// WithKeys it publishes a single RSA key with kid "key-1".
+func NewAuthorizationServer(t testing.TB, opts ...Option) *AuthorizationServer {
+	t.Helper()
+
+	s := &AuthorizationServer{
+		t:             t,
+		extraMetadata: make(map[string]any),
+		faults:        make(map[Endpoint]*Fault),
+		requests:      make(map[Endpoint]int),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.keys) == 0 {
+		s.keys = []*Key{s.newKey()}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+string(EndpointMetadata), s.handle(EndpointMetadata, s.metadata))
+	mux.HandleFunc("GET "+string(EndpointJWKS), s.handle(EndpointJWKS, s.jwks))
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Issuer returns the issuer identifier, which is the server URL.
+func (s *AuthorizationServer) Issuer() string {
+	return s.URL
+}
+
+// JWKSURI returns the URL of the key set.
+func (s *AuthorizationServer) JWKSURI() string {
+	return s.URL + string(EndpointJWKS)
+}
+
+// SigningKey returns the key that currently signs tokens.
+func (s *AuthorizationServer) SigningKey() *Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[0]
+}
+
+// Keys returns the published keys.
+func (s *AuthorizationServer) Keys() []*Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Key(nil), s.keys...)
+}
+
+// Token starts a token issued by this server: signed with the current key
+// and carrying its issuer.
+func (s *AuthorizationServer) Token() *TokenBuilder {
+	return NewToken(s.SigningKey()).Issuer(s.Issuer())
+}
+
+// RotateKey publishes a new RSA key and makes it the signing key. The old
+// keys stay published until RetireKey removes them, as during a real
+// rotation. It returns the new key.
+func (s *AuthorizationServer) RotateKey() *Key {
+	key := s.newKey()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append([]*Key{key}, s.keys...)
+	return key
+}
+
+// RetireKey removes the key with the given kid from the published set.
+// The signing key cannot be retired.
+func (s *AuthorizationServer) RetireKey(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := s.keys[:1]
+	for _, key := range s.keys[1:] {
+		if key.ID != kid {
+			keys = append(keys, key)
+		}
+	}
+	s.keys = keys
+}
+
+// SetKeys replaces the published keys. The first key signs tokens.
+func (s *AuthorizationServer) SetKeys(keys ...*Key) {
+	if len(keys) == 0 {
+		s.t.Fatalf("oauthtest: SetKeys requires at least one key")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append([]*Key(nil), keys...)
+}
+
+// Fail injects fault into the responses of endpoint, replacing any fault
+// already set for it.
+func (s *AuthorizationServer) Fail(endpoint Endpoint, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = &fault
+}
+
+// ClearFaults removes all injected faults.
+func (s *AuthorizationServer) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[Endpoint]*Fault)
+}
+
+// Requests returns how many requests endpoint has received.
+func (s *AuthorizationServer) Requests(endpoint Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// handle wraps an endpoint with request counting and fault injection.
+func (s *AuthorizationServer) handle(endpoint Endpoint, body func() any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[endpoint]++
+		var fault Fault
+		if f, ok := s.faults[endpoint]; ok {
+			fault = *f
+			if f.Times > 0 {
+				f.Times--
+				if f.Times == 0 {
+					delete(s.faults, endpoint)
+				}
+			}
+		}
+		response := body()
+		s.mu.Unlock()
+
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		status := http.StatusOK
+		if fault.Status != 0 {
+			status = fault.Status
+		}
+
+		w.Header().Set(pkgoauth.HeaderContentType, pkgoauth.ContentTypeJSON)
+		w.WriteHeader(status)
+		if fault.Body != "" {
+			_, _ = w.Write([]byte(fault.Body))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metadata returns the metadata document. The caller must hold s.mu.
+func (s *AuthorizationServer) metadata() any {
+	document := map[string]any{
+		"issuer":                           s.URL,
+		"jwks_uri":                         s.URL + string(EndpointJWKS),
+		"authorization_endpoint":           s.URL + "/authorize",
+		"token_endpoint":                   s.URL + "/token",
+		"response_types_supported":         []string{pkgoauth.ResponseTypeCode},
+		"grant_types_supported":            []string{pkgoauth.GrantTypeAuthorizationCode, pkgoauth.GrantTypeRefreshToken},
+		"code_challenge_methods_supported": []string{pkgoauth.CodeChallengeMethodS256},
+	}
+	for name, value := range s.extraMetadata {
+		document[name] = value
+	}
+	return document
+}
+
+// jwks returns the published key set. The caller must hold s.mu.
+func (s *AuthorizationServer) jwks() any {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
+
+// newKey generates the next RSA key, with kids "key-1", "key-2", ...
+func (s *AuthorizationServer) newKey() *Key {
+	s.mu.Lock()
+	s.nextKeyID++
+	kid := fmt.Sprintf("key-%d", s.nextKeyID)
+	s.mu.Unlock()
+
+	return NewRSAKey(s.t, kid)
+}
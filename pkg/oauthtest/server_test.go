@@ -0,0 +1,241 @@
+package oauthtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+)
+
+const testAudience = "https://api.example.com/mcp"
+
+// newValidator returns the resource server's token validator trusting as.
+func newValidator(as *AuthorizationServer) (oauth.TokenValidator, oauth.JWKSClient) {
+	cfg := &oauth.Config{
+		AuthorizationServers: []string{as.Issuer()},
+		Audience:             testAudience,
+		JWKSCacheTTL:         time.Hour,
+		ClockSkew:            time.Second,
+	}
+	client := oauth.NewJWKSClient(cfg)
+	return oauth.NewTokenValidator(cfg, client), client
+}
+
+// getJSON fetches url and decodes the JSON response body into v.
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestAuthorizationServer_Metadata(t *testing.T) {
+	t.Parallel()
+
+	as := NewAuthorizationServer(t, WithMetadata(map[string]any{
+		"registration_endpoint": "https://as.example.com/register",
+	}))
+
+	var document map[string]any
+	if status := getJSON(t, as.URL+string(EndpointMetadata), &document); status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+
+	want := map[string]any{
+		"issuer":                as.Issuer(),
+		"jwks_uri":              as.JWKSURI(),
+		"registration_endpoint": "https://as.example.com/register",
+	}
+	for name, value := range want {
+		if document[name] != value {
+			t.Errorf("%s = %v, want %v", name, document[name], value)
+		}
+	}
+
+	var set JWKS
+	getJSON(t, as.JWKSURI(), &set)
+	if len(set.Keys) != 1 || set.Keys[0].Kid != "key-1" || set.Keys[0].Kty != "RSA" {
+		t.Errorf("JWKS = %+v, want one RSA key with kid key-1", set.Keys)
+	}
+}
+
+func TestAuthorizationServer_ValidatesWithResourceServer(t *testing.T) {
+	t.Parallel()
+
+	ecKey := NewECKey(t, "ec-key")
+	as := NewAuthorizationServer(t, WithKeys(ecKey))
+	other := NewRSAKey(t, "other")
+
+	tests := []struct {
+		name    string
+		token   *TokenBuilder
+		wantErr bool
+	}{
+		{name: "valid token", token: as.Token().Audience(testAudience)},
+		{name: "wrong audience", token: as.Token().Audience("https://other.example.com"), wantErr: true},
+		{name: "expired", token: as.Token().Audience(testAudience).Expired(), wantErr: true},
+		{name: "unknown kid", token: as.Token().Audience(testAudience).KeyID("missing"), wantErr: true},
+		{name: "missing kid", token: as.Token().Audience(testAudience).WithoutKeyID(), wantErr: true},
+		{name: "wrong signing key", token: as.Token().Audience(testAudience).SignWith(other), wantErr: true},
+		{name: "alg none", token: as.Token().Audience(testAudience).Algorithm("none"), wantErr: true},
+		{name: "algorithm confusion", token: as.Token().Audience(testAudience).Algorithm("HS256"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			validator, _ := newValidator(as)
+			claims, err := validator.ValidateToken(context.Background(), tt.token.MustSign(t))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && claims.Issuer != as.Issuer() {
+				t.Errorf("Issuer = %q, want %q", claims.Issuer, as.Issuer())
+			}
+		})
+	}
+}
+
+func TestAuthorizationServer_RotateKey(t *testing.T) {
+	t.Parallel()
+
+	as := NewAuthorizationServer(t)
+	validator, client := newValidator(as)
+	ctx := context.Background()
+
+	oldToken := as.Token().Audience(testAudience).MustSign(t)
+	if _, err := validator.ValidateToken(ctx, oldToken); err != nil {
+		t.Fatalf("ValidateToken(old) error = %v", err)
+	}
+
+	newKey := as.RotateKey()
+	if newKey.ID != "key-2" || as.SigningKey() != newKey {
+		t.Fatalf("RotateKey() = %q, want key-2 as the signing key", newKey.ID)
+	}
+	if got := len(as.Keys()); got != 2 {
+		t.Fatalf("len(Keys()) = %d after rotation, want 2", got)
+	}
+
+	if err := client.RefreshKeys(ctx); err != nil {
+		t.Fatalf("RefreshKeys() error = %v", err)
+	}
+	newToken := as.Token().Audience(testAudience).MustSign(t)
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := validator.ValidateToken(ctx, token); err != nil {
+			t.Errorf("ValidateToken(%s) error = %v during rotation", name, err)
+		}
+	}
+
+	as.RetireKey("key-1")
+	if err := client.RefreshKeys(ctx); err != nil {
+		t.Fatalf("RefreshKeys() error = %v", err)
+	}
+	if _, err := validator.ValidateToken(ctx, oldToken); err == nil {
+		t.Error("ValidateToken(old) succeeded after the key was retired")
+	}
+	if _, err := validator.ValidateToken(ctx, newToken); err != nil {
+		t.Errorf("ValidateToken(new) error = %v after retiring the old key", err)
+	}
+}
+
+func TestAuthorizationServer_RetireSigningKey(t *testing.T) {
+	t.Parallel()
+
+	as := NewAuthorizationServer(t)
+	as.RetireKey(as.SigningKey().ID)
+
+	if got := len(as.Keys()); got != 1 {
+		t.Errorf("len(Keys()) = %d, want the signing key to stay published", got)
+	}
+}
+
+func TestAuthorizationServer_SetKeys(t *testing.T) {
+	t.Parallel()
+
+	as := NewAuthorizationServer(t)
+	first, second := NewECKey(t, "first"), NewRSAKey(t, "second")
+	as.SetKeys(first, second)
+
+	if as.SigningKey() != first {
+		t.Errorf("SigningKey() = %q, want first", as.SigningKey().ID)
+	}
+
+	var set JWKS
+	getJSON(t, as.JWKSURI(), &set)
+	if len(set.Keys) != 2 || set.Keys[0].Kid != "first" || set.Keys[1].Kid != "second" {
+		t.Errorf("JWKS = %+v, want keys first and second", set.Keys)
+	}
+}
+
+func TestAuthorizationServer_Faults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		fault    Fault
+	}{
+		{name: "metadata server error", endpoint: EndpointMetadata, fault: ServerError()},
+		{name: "metadata malformed JSON", endpoint: EndpointMetadata, fault: MalformedJSON()},
+		{name: "JWKS server error", endpoint: EndpointJWKS, fault: ServerError()},
+		{name: "JWKS malformed JSON", endpoint: EndpointJWKS, fault: MalformedJSON()},
+		{name: "JWKS slow response", endpoint: EndpointJWKS, fault: SlowResponse(5 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			as := NewAuthorizationServer(t)
+			as.Fail(tt.endpoint, tt.fault)
+			validator, _ := newValidator(as)
+			token := as.Token().Audience(testAudience).MustSign(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			if _, err := validator.ValidateToken(ctx, token); err == nil {
+				t.Fatal("ValidateToken() succeeded despite the fault")
+			}
+			if as.Requests(tt.endpoint) == 0 {
+				t.Errorf("Requests(%s) = 0, want the faulty endpoint to be hit", tt.endpoint)
+			}
+
+			as.ClearFaults()
+			if _, err := validator.ValidateToken(context.Background(), token); err != nil {
+				t.Errorf("ValidateToken() error = %v after ClearFaults", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizationServer_FaultTimes(t *testing.T) {
+	t.Parallel()
+
+	as := NewAuthorizationServer(t)
+	as.Fail(EndpointJWKS, Fault{Status: http.StatusServiceUnavailable, Body: "{}", Times: 1})
+
+	statuses := make([]int, 0, 2)
+	for range 2 {
+		var set JWKS
+		statuses = append(statuses, getJSON(t, as.JWKSURI(), &set))
+	}
+
+	if statuses[0] != http.StatusServiceUnavailable || statuses[1] != http.StatusOK {
+		t.Errorf("statuses = %v, want [503 200]", statuses)
+	}
+	if got := as.Requests(EndpointJWKS); got != 2 {
+		t.Errorf("Requests() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,240 @@
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// TokenBuilder builds signed JWT access tokens. Its methods modify the
+// builder and return it for chaining; Sign produces the token.
+//
+// A new builder carries sub "test-user", the mcp:read scope, a random jti,
+// iat of now and exp one hour from now. Issuer and audience are unset
+// unless the builder comes from an AuthorizationServer.
+type TokenBuilder struct {
+	key       *Key
+	signer    *Key
+	claims    jwt.MapClaims
+	header    map[string]any
+	alg       string
+	noKeyID   bool
+	expiresIn *time.Duration
+}
+
+// NewToken starts a token signed with key.
+func NewToken(key *Key) *TokenBuilder {
+	now := time.Now()
+	return &TokenBuilder{
+		key: key,
+		claims: jwt.MapClaims{
+			"sub":   "test-user",
+			"scope": pkgoauth.ScopeRead,
+			"jti":   randomID(),
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		},
+		header: map[string]any{},
+	}
+}
+
+// Issuer sets the iss claim.
+func (b *TokenBuilder) Issuer(iss string) *TokenBuilder {
+	return b.Claim("iss", iss)
+}
+
+// Subject sets the sub claim.
+func (b *TokenBuilder) Subject(sub string) *TokenBuilder {
+	return b.Claim("sub", sub)
+}
+
+// ClientID sets the client_id claim (RFC 9068).
+func (b *TokenBuilder) ClientID(clientID string) *TokenBuilder {
+	return b.Claim("client_id", clientID)
+}
+
+// Audience sets the aud claim: a string for one audience, an array otherwise.
+func (b *TokenBuilder) Audience(aud ...string) *TokenBuilder {
+	if len(aud) == 1 {
+		return b.Claim("aud", aud[0])
+	}
+	return b.Claim("aud", aud)
+}
+
+// Scopes sets the space-separated scope claim. No scopes removes the claim.
+func (b *TokenBuilder) Scopes(scopes ...string) *TokenBuilder {
+	if len(scopes) == 0 {
+		return b.Without("scope")
+	}
+	return b.Claim("scope", strings.Join(scopes, " "))
+}
+
+// ID sets the jti claim.
+func (b *TokenBuilder) ID(jti string) *TokenBuilder {
+	return b.Claim("jti", jti)
+}
+
+// IssuedAt sets the iat claim.
+func (b *TokenBuilder) IssuedAt(t time.Time) *TokenBuilder {
+	return b.Claim("iat", t.Unix())
+}
+
+// NotBefore sets the nbf claim.
+func (b *TokenBuilder) NotBefore(t time.Time) *TokenBuilder {
+	return b.Claim("nbf", t.Unix())
+}
+
+// ExpiresAt sets the exp claim.
+func (b *TokenBuilder) ExpiresAt(t time.Time) *TokenBuilder {
+	b.expiresIn = nil
+	return b.Claim("exp", t.Unix())
+}
+
+// ExpiresIn sets the exp claim to d after signing time. A negative d
+// produces an expired token.
+func (b *TokenBuilder) ExpiresIn(d time.Duration) *TokenBuilder {
+	b.expiresIn = &d
+	return b
+}
+
+// Expired makes the token expire one hour before signing time.
+func (b *TokenBuilder) Expired() *TokenBuilder {
+	return b.ExpiresIn(-time.Hour)
+}
+
+// Claim sets an arbitrary claim.
+func (b *TokenBuilder) Claim(name string, value any) *TokenBuilder {
+	b.claims[name] = value
+	return b
+}
+
+// Without removes claims, e.g. to test missing required claims.
+func (b *TokenBuilder) Without(names ...string) *TokenBuilder {
+	for _, name := range names {
+		delete(b.claims, name)
+	}
+	return b
+}
+
+// KeyID overrides the kid header, e.g. to reference an unknown key.
+func (b *TokenBuilder) KeyID(kid string) *TokenBuilder {
+	b.noKeyID = false
+	return b.Header("kid", kid)
+}
+
+// WithoutKeyID omits the kid header.
+func (b *TokenBuilder) WithoutKeyID() *TokenBuilder {
+	b.noKeyID = true
+	delete(b.header, "kid")
+	return b
+}
+
+// Header sets an arbitrary JOSE header parameter.
+func (b *TokenBuilder) Header(name string, value any) *TokenBuilder {
+	b.header[name] = value
+	return b
+}
+
+// Algorithm overrides the signing algorithm. HMAC algorithms sign with the
+// DER-encoded public key as secret, reproducing an algorithm confusion
+// attack; "none" produces an unsigned token.
+func (b *TokenBuilder) Algorithm(alg string) *TokenBuilder {
+	b.alg = alg
+	return b
+}
+
+// SignWith signs the token with another key, using its algorithm, while
+// keeping the kid of the original one, producing a token whose signature
+// does not verify.
+func (b *TokenBuilder) SignWith(key *Key) *TokenBuilder {
+	b.signer = key
+	return b
+}
+
+// Claims returns a copy of the claims the token will carry.
+func (b *TokenBuilder) Claims() jwt.MapClaims {
+	claims := make(jwt.MapClaims, len(b.claims))
+	for name, value := range b.claims {
+		claims[name] = value
+	}
+	if b.expiresIn != nil {
+		claims["exp"] = time.Now().Add(*b.expiresIn).Unix()
+	}
+	return claims
+}
+
+// Sign builds and signs the token.
+func (b *TokenBuilder) Sign() (string, error) {
+	if b.key == nil {
+		return "", fmt.Errorf("token builder has no key")
+	}
+
+	signer := b.key
+	if b.signer != nil {
+		signer = b.signer
+	}
+
+	alg := b.alg
+	if alg == "" {
+		alg = signer.Algorithm
+	}
+
+	var method jwt.SigningMethod
+	var signingKey any = signer.Private
+	switch {
+	case alg == "none":
+		method = jwt.SigningMethodNone
+		signingKey = jwt.UnsafeAllowNoneSignatureType
+	case strings.HasPrefix(alg, "HS"):
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			return "", fmt.Errorf("failed to encode public key: %w", err)
+		}
+		method = jwt.GetSigningMethod(alg)
+		signingKey = der
+	default:
+		method = jwt.GetSigningMethod(alg)
+	}
+	if method == nil {
+		return "", fmt.Errorf("unknown signing algorithm %q", alg)
+	}
+
+	token := jwt.NewWithClaims(method, b.Claims())
+	if !b.noKeyID {
+		token.Header["kid"] = b.key.ID
+	}
+	for name, value := range b.header {
+		token.Header[name] = value
+	}
+
+	signed, err := token.SignedString(signingKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// MustSign is Sign for tests. It fails t on error.
+func (b *TokenBuilder) MustSign(t testing.TB) string {
+	t.Helper()
+
+	signed, err := b.Sign()
+	if err != nil {
+		t.Fatalf("oauthtest: %v", err)
+	}
+	return signed
+}
+
+// randomID returns a random 128-bit hex identifier.
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
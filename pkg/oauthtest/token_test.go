@@ -0,0 +1,236 @@
+package oauthtest
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// parse verifies signed with key's public half and returns the token.
+func parse(t *testing.T, signed string, key *Key) (*jwt.Token, error) {
+	t.Helper()
+
+	return jwt.Parse(signed, func(*jwt.Token) (any, error) {
+		return key.Public(), nil
+	})
+}
+
+func TestTokenBuilder_Defaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		key  *Key
+	}{
+		{name: "RSA key", key: NewRSAKey(t, "rsa-key")},
+		{name: "EC key", key: NewECKey(t, "ec-key")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signed := tt.key.Token().MustSign(t)
+			token, err := parse(t, signed, tt.key)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if token.Method.Alg() != tt.key.Algorithm {
+				t.Errorf("alg = %q, want %q", token.Method.Alg(), tt.key.Algorithm)
+			}
+			if token.Header["kid"] != tt.key.ID {
+				t.Errorf("kid = %v, want %q", token.Header["kid"], tt.key.ID)
+			}
+
+			claims := token.Claims.(jwt.MapClaims)
+			if claims["sub"] != "test-user" {
+				t.Errorf("sub = %v, want test-user", claims["sub"])
+			}
+			if claims["scope"] != "mcp:read" {
+				t.Errorf("scope = %v, want mcp:read", claims["scope"])
+			}
+			if claims["jti"] == "" || claims["jti"] == nil {
+				t.Error("jti is empty")
+			}
+		})
+	}
+}
+
+func TestTokenBuilder_Claims(t *testing.T) {
+	t.Parallel()
+
+	key := NewRSAKey(t, "key")
+	notBefore := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	signed := key.Token().
+		Issuer("https://as.example.com").
+		Subject("alice").
+		Audience("https://api.example.com", "https://other.example.com").
+		Scopes("mcp:read", "mcp:write").
+		ID("token-1").
+		NotBefore(notBefore).
+		ClientID("client-1").
+		Without("iat").
+		MustSign(t)
+
+	token, err := parse(t, signed, key)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+
+	want := map[string]any{
+		"iss":       "https://as.example.com",
+		"sub":       "alice",
+		"scope":     "mcp:read mcp:write",
+		"jti":       "token-1",
+		"nbf":       float64(notBefore.Unix()),
+		"client_id": "client-1",
+	}
+	for name, value := range want {
+		if claims[name] != value {
+			t.Errorf("%s = %v, want %v", name, claims[name], value)
+		}
+	}
+	if aud, _ := claims.GetAudience(); len(aud) != 2 {
+		t.Errorf("aud = %v, want two audiences", aud)
+	}
+	if _, ok := claims["iat"]; ok {
+		t.Error("iat present after Without(\"iat\")")
+	}
+}
+
+func TestTokenBuilder_Expiry(t *testing.T) {
+	t.Parallel()
+
+	key := NewRSAKey(t, "key")
+
+	tests := []struct {
+		name    string
+		builder *TokenBuilder
+		expired bool
+	}{
+		{name: "default", builder: key.Token()},
+		{name: "expires in", builder: key.Token().ExpiresIn(time.Minute)},
+		{name: "expired", builder: key.Token().Expired(), expired: true},
+		{name: "expires at past", builder: key.Token().ExpiresAt(time.Now().Add(-time.Minute)), expired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parse(t, tt.builder.MustSign(t), key)
+			if tt.expired && err == nil {
+				t.Error("Parse() succeeded, want expired token")
+			}
+			if !tt.expired && err != nil {
+				t.Errorf("Parse() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenBuilder_Header(t *testing.T) {
+	t.Parallel()
+
+	key := NewRSAKey(t, "key")
+
+	tests := []struct {
+		name    string
+		builder *TokenBuilder
+		wantKid any
+	}{
+		{name: "key ID override", builder: key.Token().KeyID("unknown"), wantKid: "unknown"},
+		{name: "without key ID", builder: key.Token().WithoutKeyID(), wantKid: nil},
+		{name: "custom header", builder: key.Token().Header("kid", "custom"), wantKid: "custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			token, _, err := jwt.NewParser().ParseUnverified(tt.builder.MustSign(t), jwt.MapClaims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified() error = %v", err)
+			}
+			if token.Header["kid"] != tt.wantKid {
+				t.Errorf("kid = %v, want %v", token.Header["kid"], tt.wantKid)
+			}
+		})
+	}
+}
+
+func TestTokenBuilder_Algorithm(t *testing.T) {
+	t.Parallel()
+
+	key := NewRSAKey(t, "key")
+
+	t.Run("none", func(t *testing.T) {
+		t.Parallel()
+
+		signed := key.Token().Algorithm("none").MustSign(t)
+		token, err := jwt.Parse(signed, func(*jwt.Token) (any, error) {
+			return jwt.UnsafeAllowNoneSignatureType, nil
+		})
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if token.Method.Alg() != "none" {
+			t.Errorf("alg = %q, want none", token.Method.Alg())
+		}
+	})
+
+	t.Run("HMAC with public key", func(t *testing.T) {
+		t.Parallel()
+
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+		}
+
+		signed := key.Token().Algorithm("HS256").MustSign(t)
+		if _, err := jwt.Parse(signed, func(*jwt.Token) (any, error) { return der, nil }); err != nil {
+			t.Errorf("Parse() error = %v, want token signed with the public key", err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := key.Token().Algorithm("XX999").Sign(); err == nil {
+			t.Error("Sign() succeeded with an unknown algorithm")
+		}
+	})
+}
+
+func TestTokenBuilder_SignWith(t *testing.T) {
+	t.Parallel()
+
+	key := NewRSAKey(t, "key")
+	other := NewRSAKey(t, "other")
+
+	signed := key.Token().SignWith(other).MustSign(t)
+
+	if _, err := parse(t, signed, key); err == nil {
+		t.Error("Parse() with the original key succeeded, want signature failure")
+	}
+	token, err := parse(t, signed, other)
+	if err != nil {
+		t.Fatalf("Parse() with the signing key error = %v", err)
+	}
+	if token.Header["kid"] != "key" {
+		t.Errorf("kid = %v, want the original key ID", token.Header["kid"])
+	}
+}
+
+func TestTokenBuilder_NoKey(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewToken(nil).Sign(); err == nil {
+		t.Error("Sign() succeeded without a key")
+	}
+}
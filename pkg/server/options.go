@@ -0,0 +1,150 @@
+package server
+
+import (
+	"time"
+)
+
+// Defaults applied by New.
+const (
+	// DefaultName is the server name reported to MCP clients.
+	DefaultName = "mcp-oauth-2.1"
+
+	// DefaultVersion is the server version reported to MCP clients.
+	DefaultVersion = "1.0.0"
+
+	// DefaultShutdownTimeout bounds the graceful shutdown of Run.
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// options collects the settings applied by Option values.
+type options struct {
+	config               *Config
+	name                 string
+	version              string
+	baseURL              string
+	authorizationServers []string
+	audience             string
+	scopes               []string
+	validator            TokenValidator
+	tools                []Tool
+	resources            []ResourceProvider
+	resourceTemplates    []ResourceTemplateProvider
+	prompts              []Prompt
+	middleware           []Middleware
+	shutdownTimeout      time.Duration
+	loader               func() (*Config, error)
+}
+
+// Option configures a Server.
+type Option func(*options)
+
+// WithConfig sets the server configuration. New works on a copy, so cfg
+// can be reused. Without it, New starts from DefaultConfig.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithServerInfo sets the name and version reported to MCP clients.
+func WithServerInfo(name, version string) Option {
+	return func(o *options) {
+		o.name = name
+		o.version = version
+	}
+}
+
+// WithBaseURL sets the canonical base URL, overriding the configuration.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithAuthorizationServers sets the trusted authorization servers,
+// overriding the configuration.
+func WithAuthorizationServers(servers ...string) Option {
+	return func(o *options) {
+		o.authorizationServers = servers
+	}
+}
+
+// WithAudience sets the audience required in access tokens, overriding the
+// configuration.
+func WithAudience(audience string) Option {
+	return func(o *options) {
+		o.audience = audience
+	}
+}
+
+// WithScopes adds scopes to those advertised in the protected resource
+// metadata. Scopes declared by tools and resources are advertised anyway.
+func WithScopes(scopes ...string) Option {
+	return func(o *options) {
+		o.scopes = append(o.scopes, scopes...)
+	}
+}
+
+// WithTokenValidator replaces the JWKS-based access token validation of the
+// primary resource, for example to accept tokens from an introspection
+// endpoint. Additional protected resources keep validating their tokens
+// against their own audience.
+func WithTokenValidator(validator TokenValidator) Option {
+	return func(o *options) {
+		o.validator = validator
+	}
+}
+
+// WithTool registers a tool under the name of its definition.
+func WithTool(tool Tool) Option {
+	return func(o *options) {
+		o.tools = append(o.tools, tool)
+	}
+}
+
+// WithResource registers a resource under the URI of its definition.
+func WithResource(provider ResourceProvider) Option {
+	return func(o *options) {
+		o.resources = append(o.resources, provider)
+	}
+}
+
+// WithResourceTemplate registers a resource template provider under the URI
+// template of its definition.
+func WithResourceTemplate(provider ResourceTemplateProvider) Option {
+	return func(o *options) {
+		o.resourceTemplates = append(o.resourceTemplates, provider)
+	}
+}
+
+// WithPrompt registers a prompt under the name of its definition.
+func WithPrompt(prompt Prompt) Option {
+	return func(o *options) {
+		o.prompts = append(o.prompts, prompt)
+	}
+}
+
+// WithMiddleware wraps every route after the built-in recovery and logging
+// middleware. The first middleware is the outermost layer.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithShutdownTimeout bounds how long Run waits for in-flight requests
+// once its context is cancelled.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithConfigLoader sets the function Reload reads the new configuration
+// with, such as LoadConfig. The options of New still override the loaded
+// configuration.
+func WithConfigLoader(load func() (*Config, error)) Option {
+	return func(o *options) {
+		o.loader = load
+	}
+}
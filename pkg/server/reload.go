@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/config"
+)
+
+// reloadMetrics counts configuration reloads by outcome. /admin/metrics
+// serves it through metricsHandler.
+var reloadMetrics = expvar.NewMap("config_reloads")
+
+// metricsHandler serves the reload metrics as a JSON object keyed by
+// config_reloads. Other expvar variables, such as cmdline and memstats, are
+// left out: they can reveal flag values and need not be exposed.
+func metricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, "{%q: %s}\n", "config_reloads", reloadMetrics.String())
+	})
+}
+
+// Reload reads the configuration with the loader of WithConfigLoader,
+// validates it and swaps the token validator, protected resource metadata
+// and JWKS client for ones built from it. Requests in flight finish with
+// the services they started with, and no connection is closed.
+//
+// An invalid configuration, or one changing settings that need a restart
+// such as the listen address or base URL, is rejected: Reload logs and
+// returns the error, and the current configuration stays in use. Every
+// outcome is counted in the config_reloads metric.
+func (s *Server) Reload(ctx context.Context) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	cfg, err := s.reload(ctx)
+	recordReload(err)
+	if err != nil {
+		slog.Error("configuration reload rejected, keeping the current configuration", "error", err)
+		return err
+	}
+
+	slog.Info("configuration reloaded",
+		"auth_servers", cfg.AuthorizationServers,
+		"audience", cfg.Audience,
+		"scopes_supported", cfg.ScopesSupported,
+	)
+	return nil
+}
+
+// reload builds the services of the loaded configuration and swaps them in.
+func (s *Server) reload(ctx context.Context) (*Config, error) {
+	if s.opts.loader == nil {
+		return nil, errors.New("no configuration loader, use WithConfigLoader")
+	}
+
+	loaded, err := s.opts.loader()
+	if err != nil {
+		return nil, err
+	}
+
+	o := *s.opts
+	o.config = loaded
+	cfg := resolveConfig(&o)
+	if err := config.Validate(cfg); err != nil {
+		return nil, err
+	}
+	if changed := restartRequired(s.Config(), cfg); len(changed) > 0 {
+		return nil, fmt.Errorf("%s cannot change without a restart", strings.Join(changed, ", "))
+	}
+
+	services, _, err := newServices(cfg, &o, s.scopeProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	// Warm the new key cache so tokens validated right after the swap do
+	// not wait for a fetch. Failures are retried on demand like at startup.
+	if err := services.JWKSClient.RefreshKeys(ctx); err != nil {
+		slog.Warn("failed to prefetch key sets for the reloaded configuration", "error", err)
+	}
+
+	if err := s.services.Swap(services); err != nil {
+		return nil, err
+	}
+	s.cfg.Store(cfg)
+	return cfg, nil
+}
+
+// restartRequired returns the environment variable names of the settings
+// that differ between current and next but are bound at startup: the
+// listener, the routes and the development authorization server, which
+// issues tokens for the audience configured at startup.
+func restartRequired(current, next *Config) []string {
+	var changed []string
+	check := func(name string, differs bool) {
+		if differs {
+			changed = append(changed, name)
+		}
+	}
+
+	check("SERVER_ADDR", current.Addr != next.Addr)
+	check("SERVER_BASE_URL", current.BaseURL != next.BaseURL)
+	check("SERVER_READ_TIMEOUT", current.ReadTimeout != next.ReadTimeout)
+	check("SERVER_WRITE_TIMEOUT", current.WriteTimeout != next.WriteTimeout)
+	check("SERVER_IDLE_TIMEOUT", current.IdleTimeout != next.IdleTimeout)
+	check("OAUTH_PROTECTED_RESOURCES", !slices.Equal(resourceIdentifiers(current), resourceIdentifiers(next)))
+	check("OAUTH_AS_METADATA_PATH", current.ASMetadataPath != next.ASMetadataPath)
+	check("OAUTH_RESOURCE_JWKS_PATH", current.ResourceMetadata.JWKSPath != next.ResourceMetadata.JWKSPath)
+	// The resource key set is routed only when metadata is signed
+	check("OAUTH_RESOURCE_SIGNING_KEY_FILE",
+		(current.ResourceMetadata.SigningKeyFile == "") != (next.ResourceMetadata.SigningKeyFile == ""))
+	check("OAUTH_DEV_SERVER", current.DevServer != next.DevServer)
+	check("OAUTH_AUDIENCE", current.DevServer.Enabled && current.Audience != next.Audience)
+	check("MCP_SESSION_TTL", current.SessionTTL != next.SessionTTL)
+	check("MCP_PAGE_SIZE", current.PageSize != next.PageSize)
+
+	return changed
+}
+
+// resourceIdentifiers returns the identifiers of the additional protected
+// resources, which determine their metadata routes.
+func resourceIdentifiers(cfg *Config) []string {
+	identifiers := make([]string, len(cfg.ProtectedResources))
+	for i, resource := range cfg.ProtectedResources {
+		identifiers[i] = resource.Resource
+	}
+	return identifiers
+}
+
+// recordReload counts a reload outcome and records its time and error.
+func recordReload(err error) {
+	now := new(expvar.Int)
+	now.Set(time.Now().Unix())
+
+	if err != nil {
+		reloadMetrics.Add("failure", 1)
+		reloadMetrics.Set("last_failure_timestamp", now)
+		lastError := new(expvar.String)
+		lastError.Set(err.Error())
+		reloadMetrics.Set("last_error", lastError)
+		return
+	}
+	reloadMetrics.Add("success", 1)
+	reloadMetrics.Set("last_success_timestamp", now)
+}
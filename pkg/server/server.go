@@ -0,0 +1,300 @@
+// Package server embeds the OAuth 2.1 protected MCP server in other Go
+// programs. It wires token validation, RFC 9728 metadata and the MCP
+// endpoint exactly like cmd/server, and lets the program register its own
+// tools, resources and middleware.
+//
+// A server either runs on its own listener:
+//
+//	srv, err := server.New(
+//		server.WithBaseURL("https://api.example.com"),
+//		server.WithAuthorizationServers("https://auth.example.com"),
+//		server.WithAudience("https://api.example.com"),
+//		server.WithTool(myTool),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = srv.Run(ctx)
+//
+// or is mounted at the root of another mux through Handler, since its
+// routes include the well-known metadata locations.
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/config"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport"
+	pkgoauth "github.com/jamesprial/mcp-oauth-2.1/pkg/oauth"
+)
+
+// Server is an MCP server protected by OAuth 2.1 access tokens.
+type Server struct {
+	opts            *options
+	cfg             atomic.Pointer[Config]
+	scopeProviders  []oauth.ScopeProvider
+	services        *oauth.ReloadableServices
+	reloadMu        sync.Mutex
+	tools           ToolRegistry
+	resources       ResourceRegistry
+	prompts         PromptRegistry
+	sessions        []mcp.SessionManager
+	httpServer      transport.Server
+	handler         http.Handler
+	shutdownTimeout time.Duration
+}
+
+// New builds a server from the options. The configuration is validated
+// with the same rules as LoadConfig after the options are applied.
+func New(opts ...Option) (*Server, error) {
+	o := &options{
+		name:            DefaultName,
+		version:         DefaultVersion,
+		shutdownTimeout: DefaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cfg := resolveConfig(o)
+	if err := config.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	// Wire MCP components
+	mcpHandler, tools, resources, prompts := mcp.NewMCPServices(&mcp.Config{
+		ServerName:    o.name,
+		ServerVersion: o.version,
+		PageSize:      cfg.PageSize,
+	})
+	for _, tool := range o.tools {
+		if err := tools.RegisterTool(tool.Definition().Name, tool); err != nil {
+			return nil, fmt.Errorf("failed to register tool %q: %w", tool.Definition().Name, err)
+		}
+	}
+	for _, provider := range o.resources {
+		if err := resources.RegisterResource(provider.Definition().URI, provider); err != nil {
+			return nil, fmt.Errorf("failed to register resource %q: %w", provider.Definition().URI, err)
+		}
+	}
+	for _, provider := range o.resourceTemplates {
+		if err := resources.RegisterResourceTemplate(provider.Definition().URITemplate, provider); err != nil {
+			return nil, fmt.Errorf("failed to register resource template %q: %w", provider.Definition().URITemplate, err)
+		}
+	}
+	for _, prompt := range o.prompts {
+		if err := prompts.RegisterPrompt(prompt.Definition().Name, prompt); err != nil {
+			return nil, fmt.Errorf("failed to register prompt %q: %w", prompt.Definition().Name, err)
+		}
+	}
+
+	// Advertise the scopes the server enforces: those declared by tools,
+	// resources and prompts, the authentication default and the admin scope
+	// of /admin/jwks
+	scopeProviders := []oauth.ScopeProvider{
+		tools,
+		resources,
+		prompts,
+		oauth.StaticScopes(append(transport.DefaultScopes(), pkgoauth.ScopeAdmin)),
+	}
+
+	// Wire OAuth components behind views that follow configuration reloads
+	services, oauthCfg, err := newServices(cfg, o, scopeProviders)
+	if err != nil {
+		return nil, err
+	}
+	reloadable := oauth.NewReloadableServices(services)
+	metadataService := reloadable.MetadataService()
+	jwksClient := reloadable.JWKSClient()
+
+	// Wire transport layer
+	transportCfg := &transport.Config{
+		ServerConfig:    cfg,
+		OAuthValidator:  reloadable.TokenValidator(),
+		MetadataService: metadataService,
+		MCPHandler:      mcpHandler,
+		Metrics:         metricsHandler(),
+		Middleware:      o.middleware,
+	}
+	if inspector, ok := jwksClient.(oauth.JWKSInspector); ok {
+		transportCfg.JWKSInspector = inspector
+	}
+	if cfg.ASMetadataPath != "" {
+		if directory, ok := jwksClient.(oauth.AuthorizationServerDirectory); ok {
+			transportCfg.AuthorizationServerDirectory = directory
+			transportCfg.AuthorizationServersPath = cfg.ASMetadataPath
+		}
+	}
+	if cfg.DevServer.Enabled {
+		devServer, err := newDevAuthorizationServer(cfg, metadataService)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dev authorization server: %w", err)
+		}
+		transportCfg.DevAuthorizationServer = devServer
+		transportCfg.DevAuthorizationServerPath = cfg.DevServer.Path
+	}
+	if oauthCfg.ResourceMetadata.SigningKey != nil {
+		if keySet, ok := metadataService.(oauth.ResourceKeySet); ok {
+			transportCfg.ResourceKeySet = keySet
+			transportCfg.ResourceJWKSPath = cfg.ResourceMetadata.JWKSPath
+		}
+	}
+
+	// Every protected resource keeps its own sessions
+	sessions := []mcp.SessionManager{mcp.NewSessionManager(cfg.SessionTTL)}
+	transportCfg.Sessions = sessions[0]
+	resourceValidators := reloadable.ResourceTokenValidators()
+	for i, service := range reloadable.ResourceMetadataServices() {
+		resourceSessions := mcp.NewSessionManager(cfg.SessionTTL)
+		sessions = append(sessions, resourceSessions)
+		transportCfg.ProtectedResources = append(transportCfg.ProtectedResources, transport.ProtectedResource{
+			MetadataService: service,
+			TokenValidator:  resourceValidators[i],
+			Sessions:        resourceSessions,
+		})
+	}
+
+	httpServer, router, err := transport.NewTransportServices(transportCfg)
+	if err != nil {
+		closeSessions(sessions)
+		return nil, fmt.Errorf("failed to create transport services: %w", err)
+	}
+
+	slog.Info("server initialized",
+		"server_name", o.name,
+		"metadata_url", metadataService.GetMetadataURL(),
+		"additional_resources", len(services.ResourceMetadataServices),
+	)
+
+	srv := &Server{
+		opts:            o,
+		scopeProviders:  scopeProviders,
+		services:        reloadable,
+		tools:           tools,
+		resources:       resources,
+		prompts:         prompts,
+		sessions:        sessions,
+		httpServer:      httpServer,
+		handler:         router,
+		shutdownTimeout: o.shutdownTimeout,
+	}
+	srv.cfg.Store(cfg)
+	return srv, nil
+}
+
+// resolveConfig copies the configuration selected by the options and
+// applies their overrides.
+func resolveConfig(o *options) *Config {
+	cfg := DefaultConfig()
+	if o.config != nil {
+		copied := *o.config
+		cfg = &copied
+	}
+	cfg.AuthorizationServers = slices.Clone(cfg.AuthorizationServers)
+	cfg.ScopesSupported = slices.Clone(cfg.ScopesSupported)
+
+	if o.baseURL != "" {
+		cfg.BaseURL = o.baseURL
+	}
+	if len(o.authorizationServers) > 0 {
+		cfg.AuthorizationServers = slices.Clone(o.authorizationServers)
+	}
+	if o.audience != "" {
+		cfg.Audience = o.audience
+	}
+	for _, scope := range o.scopes {
+		if !slices.Contains(cfg.ScopesSupported, scope) {
+			cfg.ScopesSupported = append(cfg.ScopesSupported, scope)
+		}
+	}
+
+	// The development authorization server issues tokens like any trusted server
+	if issuer := cfg.DevServerIssuer(); issuer != "" && !slices.Contains(cfg.AuthorizationServers, issuer) {
+		cfg.AuthorizationServers = append(cfg.AuthorizationServers, issuer)
+	}
+
+	return cfg
+}
+
+// Config returns the validated configuration the server runs with, which
+// Reload replaces. It must not be modified.
+func (s *Server) Config() *Config {
+	return s.cfg.Load()
+}
+
+// Tools returns the tool registry. Tools may be registered and unregistered
+// at any time; their scopes are advertised as soon as they are registered,
+// and connected clients are told that the tool list changed.
+func (s *Server) Tools() ToolRegistry {
+	return s.tools
+}
+
+// Resources returns the resource registry. Its NotifyUpdated tells the
+// clients subscribed to a resource that its content changed.
+func (s *Server) Resources() ResourceRegistry {
+	return s.resources
+}
+
+// Prompts returns the prompt registry. Prompts registered after a client
+// initialized its session are offered to it, but the prompts capability is
+// only advertised to sessions initialized while prompts were registered.
+func (s *Server) Prompts() PromptRegistry {
+	return s.prompts
+}
+
+// Handler returns the HTTP handler serving every route of the server. It
+// must be mounted at the root path of the host named by the base URL.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Run serves on the configured address until ctx is cancelled, then shuts
+// down gracefully within the shutdown timeout. It returns nil after a clean
+// shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	serverErrCh := make(chan error, 1)
+	go func() {
+		slog.Info("starting server", "addr", s.Config().Addr)
+		serverErrCh <- s.httpServer.Start()
+	}()
+
+	select {
+	case err := <-serverErrCh:
+		return err
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, stopping server gracefully...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	// Open session streams only end with their session, so terminate the
+	// sessions to let Shutdown drain the connections.
+	closeSessions(s.sessions)
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	return <-serverErrCh
+}
+
+// closeSessions terminates the sessions of every protected resource.
+func closeSessions(sessions []mcp.SessionManager) {
+	for _, manager := range sessions {
+		manager.Close()
+	}
+}
+
+// Addr returns the address the server listens on once Run has started,
+// which resolves a ":0" port.
+func (s *Server) Addr() string {
+	return s.httpServer.Addr()
+}
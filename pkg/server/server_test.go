@@ -0,0 +1,700 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamesprial/mcp-oauth-2.1/pkg/oauthtest"
+)
+
+const testBaseURL = "http://localhost:8080"
+
+// whoamiTool returns the subject of the caller's access token.
+type whoamiTool struct{}
+
+func (whoamiTool) Execute(ctx context.Context, args map[string]any) (any, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return "anonymous", nil
+	}
+	return claims.Subject, nil
+}
+
+func (whoamiTool) Definition() ToolDefinition {
+	return ToolDefinition{
+		Name:        "whoami",
+		Description: "Returns the caller",
+		InputSchema: map[string]any{"type": "object"},
+	}
+}
+
+func (whoamiTool) RequiredScopes() []string {
+	return []string{"whoami:read"}
+}
+
+// postMCP sends a JSON-RPC request to the /mcp endpoint of ts, in the
+// session sessionID if set. Sessions use the latest protocol version.
+func postMCP(t *testing.T, ts *httptest.Server, token, sessionID, body string) (*http.Response, map[string]any) {
+	t.Helper()
+	return postMCPPath(t, ts, "/mcp", token, sessionID, body)
+}
+
+// postMCPPath is postMCP for the MCP endpoint at path.
+func postMCPPath(t *testing.T, ts *httptest.Server, path, token, sessionID, body string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		req.Header.Set("MCP-Protocol-Version", "2025-06-18")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp, decoded
+}
+
+func TestServer_Handler(t *testing.T) {
+	t.Parallel()
+
+	as := oauthtest.NewAuthorizationServer(t)
+
+	var middlewareCalls int
+	srv, err := New(
+		WithBaseURL(testBaseURL),
+		WithAuthorizationServers(as.Issuer()),
+		WithAudience(testBaseURL),
+		WithTool(whoamiTool{}),
+		WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				middlewareCalls++
+				next.ServeHTTP(w, r)
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	callWhoami := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami"}}`
+
+	resp, _ := postMCP(t, ts, "", "", callWhoami)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	token := as.Token().Subject("alice").Audience(testBaseURL).Scopes("whoami:read").MustSign(t)
+	resp, _ = postMCP(t, ts, token, "", `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize status = %d, Mcp-Session-Id = %q", resp.StatusCode, sessionID)
+	}
+
+	resp, body := postMCP(t, ts, token, sessionID, callWhoami)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("authenticated status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	result, _ := body["result"].(map[string]any)
+	content, _ := result["content"].([]any)
+	if len(content) != 1 || content[0].(map[string]any)["text"] != "alice" {
+		t.Errorf("tools/call result = %v, want text alice", body)
+	}
+
+	// A token without the tool's scope cannot call it
+	unscoped := as.Token().Subject("alice").Audience(testBaseURL).MustSign(t)
+	_, body = postMCP(t, ts, unscoped, sessionID, callWhoami)
+	if errObj, _ := body["error"].(map[string]any); errObj == nil || errObj["code"] != float64(-32003) {
+		t.Errorf("tools/call without whoami:read = %v, want tool not found", body)
+	}
+
+	if middlewareCalls != 4 {
+		t.Errorf("middleware calls = %d, want 4", middlewareCalls)
+	}
+
+	// Declared tool scopes are advertised in the metadata
+	metadataResp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource")
+	if err != nil {
+		t.Fatalf("GET metadata failed: %v", err)
+	}
+	defer metadataResp.Body.Close()
+
+	var metadata struct {
+		ScopesSupported []string `json:"scopes_supported"`
+	}
+	if err := json.NewDecoder(metadataResp.Body).Decode(&metadata); err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+	if !containsScope(metadata.ScopesSupported, "whoami:read") {
+		t.Errorf("scopes_supported = %v, want whoami:read", metadata.ScopesSupported)
+	}
+}
+
+func TestServer_ProtectedResources(t *testing.T) {
+	t.Parallel()
+
+	primaryAS := oauthtest.NewAuthorizationServer(t)
+	reportsAS := oauthtest.NewAuthorizationServer(t, oauthtest.WithKeys(oauthtest.NewRSAKey(t, "reports-key")))
+	const reportsResource = testBaseURL + "/reports"
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = testBaseURL
+	cfg.AuthorizationServers = []string{primaryAS.Issuer(), reportsAS.Issuer()}
+	cfg.Audience = testBaseURL
+	cfg.ProtectedResources = []ProtectedResourceConfig{{
+		Resource:             reportsResource,
+		AuthorizationServers: []string{reportsAS.Issuer()},
+	}}
+
+	srv, err := New(WithConfig(cfg), WithTool(whoamiTool{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`
+	reportsToken := reportsAS.Token().Subject("alice").Audience(reportsResource).Scopes("mcp:read", "whoami:read").MustSign(t)
+	primaryToken := primaryAS.Token().Subject("alice").Audience(testBaseURL).Scopes("mcp:read", "whoami:read").MustSign(t)
+	wrongAudienceToken := reportsAS.Token().Subject("alice").Audience(testBaseURL).Scopes("mcp:read").MustSign(t)
+	untrustedToken := primaryAS.Token().Subject("alice").Audience(reportsResource).Scopes("mcp:read").MustSign(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "resource token at the resource", path: "/reports", token: reportsToken, wantStatus: http.StatusOK},
+		{name: "primary token at the resource", path: "/reports", token: primaryToken, wantStatus: http.StatusUnauthorized},
+		{name: "primary audience at the resource", path: "/reports", token: wrongAudienceToken, wantStatus: http.StatusUnauthorized},
+		{name: "token from another authorization server", path: "/reports", token: untrustedToken, wantStatus: http.StatusUnauthorized},
+		{name: "resource token at the MCP endpoint", path: "/mcp", token: reportsToken, wantStatus: http.StatusUnauthorized},
+		{name: "primary token at the MCP endpoint", path: "/mcp", token: primaryToken, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, _ := postMCPPath(t, ts, tt.path, tt.token, "", initialize)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("POST %s status = %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+			}
+
+			wantMetadata := testBaseURL + "/.well-known/oauth-protected-resource"
+			if tt.path == "/reports" {
+				wantMetadata += "/reports"
+			}
+			if challenge := resp.Header.Get("WWW-Authenticate"); tt.wantStatus == http.StatusUnauthorized &&
+				!strings.Contains(challenge, `resource_metadata="`+wantMetadata+`"`) {
+				t.Errorf("WWW-Authenticate = %q, want resource_metadata %s", challenge, wantMetadata)
+			}
+		})
+	}
+
+	// A session belongs to the resource that created it
+	resp, _ := postMCPPath(t, ts, "/reports", reportsToken, "", initialize)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		t.Fatal("initialize at /reports returned no Mcp-Session-Id")
+	}
+	callWhoami := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami"}}`
+	if resp, body := postMCPPath(t, ts, "/reports", reportsToken, sessionID, callWhoami); resp.StatusCode != http.StatusOK || body["error"] != nil {
+		t.Errorf("tools/call at /reports status = %d, body = %v", resp.StatusCode, body)
+	}
+	if resp, _ := postMCP(t, ts, primaryToken, sessionID, callWhoami); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("tools/call at /mcp in a /reports session status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	// The resource's metadata names its own authorization server
+	metadataResp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource/reports")
+	if err != nil {
+		t.Fatalf("GET metadata failed: %v", err)
+	}
+	defer metadataResp.Body.Close()
+
+	var metadata struct {
+		Resource             string   `json:"resource"`
+		AuthorizationServers []string `json:"authorization_servers"`
+	}
+	if err := json.NewDecoder(metadataResp.Body).Decode(&metadata); err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+	if metadata.Resource != reportsResource || !slices.Equal(metadata.AuthorizationServers, []string{reportsAS.Issuer()}) {
+		t.Errorf("metadata = %+v, want the reports resource and its authorization server", metadata)
+	}
+}
+
+func TestServer_RegisterAfterNew(t *testing.T) {
+	t.Parallel()
+
+	srv, err := New(
+		WithBaseURL(testBaseURL),
+		WithAuthorizationServers("https://auth.example.com"),
+		WithAudience(testBaseURL),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := srv.Tools().RegisterTool("whoami", whoamiTool{}); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if got := srv.Tools().ListTools(); len(got) != 1 || got[0].Name != "whoami" {
+		t.Errorf("ListTools() = %v, want whoami", got)
+	}
+}
+
+func TestServer_Prompts(t *testing.T) {
+	t.Parallel()
+
+	as := oauthtest.NewAuthorizationServer(t)
+
+	summarize, err := NewTemplatePrompt(PromptDefinition{
+		Name:      "summarize",
+		Arguments: []PromptArgument{{Name: "topic", Required: true}},
+	}, []string{"prompts:read"}, PromptMessageTemplate{Role: "user", Text: "Summarize {{.topic}}."})
+	if err != nil {
+		t.Fatalf("NewTemplatePrompt() error = %v", err)
+	}
+
+	srv, err := New(
+		WithBaseURL(testBaseURL),
+		WithAuthorizationServers(as.Issuer()),
+		WithAudience(testBaseURL),
+		WithPrompt(summarize),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	// listPrompts initializes a session for token and lists its prompts
+	listPrompts := func(token string) []any {
+		t.Helper()
+
+		resp, body := postMCP(t, ts, token, "", `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+		sessionID := resp.Header.Get("Mcp-Session-Id")
+		result, _ := body["result"].(map[string]any)
+		capabilities, _ := result["capabilities"].(map[string]any)
+		if _, ok := capabilities["prompts"]; !ok {
+			t.Errorf("initialize capabilities = %v, want prompts", capabilities)
+		}
+
+		_, body = postMCP(t, ts, token, sessionID, `{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)
+		result, _ = body["result"].(map[string]any)
+		prompts, _ := result["prompts"].([]any)
+		return prompts
+	}
+
+	unscoped := as.Token().Subject("alice").Audience(testBaseURL).MustSign(t)
+	if prompts := listPrompts(unscoped); len(prompts) != 0 {
+		t.Errorf("prompts/list without prompts:read = %v, want none", prompts)
+	}
+
+	scoped := as.Token().Subject("alice").Audience(testBaseURL).Scopes("prompts:read").MustSign(t)
+	if prompts := listPrompts(scoped); len(prompts) != 1 {
+		t.Errorf("prompts/list with prompts:read = %v, want summarize", prompts)
+	}
+
+	if got := srv.Prompts().RequiredScopes(); len(got) != 1 || got[0] != "prompts:read" {
+		t.Errorf("Prompts().RequiredScopes() = %v, want [prompts:read]", got)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		errContains string
+	}{
+		{
+			name:        "missing base URL",
+			opts:        []Option{WithAuthorizationServers("https://auth.example.com"), WithAudience(testBaseURL)},
+			errContains: "SERVER_BASE_URL",
+		},
+		{
+			name:        "missing authorization servers",
+			opts:        []Option{WithBaseURL(testBaseURL), WithAudience(testBaseURL)},
+			errContains: "OAUTH_AUTHORIZATION_SERVERS",
+		},
+		{
+			name: "duplicate tool",
+			opts: []Option{
+				WithBaseURL(testBaseURL),
+				WithAuthorizationServers("https://auth.example.com"),
+				WithAudience(testBaseURL),
+				WithTool(whoamiTool{}),
+				WithTool(whoamiTool{}),
+			},
+			errContains: "whoami",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("New() error = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestNew_CopiesConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = testBaseURL
+	cfg.AuthorizationServers = []string{"https://auth.example.com"}
+	cfg.Audience = testBaseURL
+
+	srv, err := New(WithConfig(cfg), WithAudience("https://other.example.com"), WithScopes("extra"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if cfg.Audience != testBaseURL || len(cfg.ScopesSupported) != 0 {
+		t.Errorf("New() modified the caller's config: %v", cfg)
+	}
+	if srv.Config().Audience != "https://other.example.com" {
+		t.Errorf("Config().Audience = %q, want the WithAudience override", srv.Config().Audience)
+	}
+	if !containsScope(srv.Config().ScopesSupported, "extra") {
+		t.Errorf("Config().ScopesSupported = %v, want extra", srv.Config().ScopesSupported)
+	}
+}
+
+func TestServer_SignedMetadataJWKSURI(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "metadata-key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	// The resource lives below a path, the key set at the root
+	cfg := DefaultConfig()
+	cfg.BaseURL = testBaseURL + "/mcp"
+	cfg.Audience = cfg.BaseURL
+	cfg.AuthorizationServers = []string{"https://auth.example.com"}
+	cfg.ResourceMetadata.SigningKeyFile = keyFile
+
+	srv, err := New(WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource/mcp")
+	if err != nil {
+		t.Fatalf("GET metadata failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var metadata struct {
+		JWKSURI        string `json:"jwks_uri"`
+		SignedMetadata string `json:"signed_metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+	if want := testBaseURL + "/.well-known/jwks.json"; metadata.JWKSURI != want {
+		t.Errorf("jwks_uri = %q, want %q", metadata.JWKSURI, want)
+	}
+	if metadata.SignedMetadata == "" {
+		t.Error("signed_metadata is empty")
+	}
+
+	// The advertised location serves the key set
+	jwksResp, err := http.Get(ts.URL + strings.TrimPrefix(metadata.JWKSURI, testBaseURL))
+	if err != nil {
+		t.Fatalf("GET jwks_uri failed: %v", err)
+	}
+	defer jwksResp.Body.Close()
+	if jwksResp.StatusCode != http.StatusOK {
+		t.Errorf("GET jwks_uri status = %d, want %d", jwksResp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestServer_Run(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultConfig()
+	cfg.Addr = "127.0.0.1:0"
+
+	srv, err := New(
+		WithConfig(cfg),
+		WithBaseURL(testBaseURL),
+		WithAuthorizationServers("https://auth.example.com"),
+		WithAudience(testBaseURL),
+		WithShutdownTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+
+	// Wait for the listener to resolve the port
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Addr() == cfg.Addr && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, err := http.Get("http://" + srv.Addr() + "/health")
+	if err != nil {
+		t.Fatalf("GET /health failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /health status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v, want nil after shutdown", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return after the context was cancelled")
+	}
+}
+
+// containsScope reports whether scopes contains scope.
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServer_Reload(t *testing.T) {
+	t.Parallel()
+
+	as := oauthtest.NewAuthorizationServer(t)
+
+	var mu sync.Mutex
+	next := DefaultConfig()
+	next.BaseURL = testBaseURL
+	next.AuthorizationServers = []string{as.Issuer()}
+	next.Audience = testBaseURL
+	load := func() (*Config, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		copied := *next
+		return &copied, nil
+	}
+	update := func(change func(cfg *Config)) {
+		mu.Lock()
+		defer mu.Unlock()
+		change(next)
+	}
+
+	initial, _ := load()
+	srv, err := New(WithConfig(initial), WithConfigLoader(load), WithTool(whoamiTool{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	// initialize needs no session, so each request stands alone
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`
+	oldAudience := as.Token().Audience(testBaseURL).MustSign(t)
+	newAudience := as.Token().Audience("http://localhost:8080/v2").MustSign(t)
+
+	if resp, _ := postMCP(t, ts, oldAudience, "", initialize); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status before reload = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	update(func(cfg *Config) {
+		cfg.Audience = "http://localhost:8080/v2"
+		cfg.ScopesSupported = []string{"reports:read"}
+	})
+	if err := srv.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if resp, _ := postMCP(t, ts, oldAudience, "", initialize); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("old audience status after reload = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if resp, _ := postMCP(t, ts, newAudience, "", initialize); resp.StatusCode != http.StatusOK {
+		t.Errorf("new audience status after reload = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if srv.Config().Audience != "http://localhost:8080/v2" {
+		t.Errorf("Config().Audience = %q, want the reloaded audience", srv.Config().Audience)
+	}
+
+	metadataResp, err := http.Get(ts.URL + "/.well-known/oauth-protected-resource")
+	if err != nil {
+		t.Fatalf("GET metadata failed: %v", err)
+	}
+	defer metadataResp.Body.Close()
+	var metadata struct {
+		ScopesSupported []string `json:"scopes_supported"`
+	}
+	if err := json.NewDecoder(metadataResp.Body).Decode(&metadata); err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+	if !containsScope(metadata.ScopesSupported, "reports:read") || !containsScope(metadata.ScopesSupported, "whoami:read") {
+		t.Errorf("scopes_supported = %v, want reloaded and tool scopes", metadata.ScopesSupported)
+	}
+
+	// Rejected configurations keep the current one running
+	rejected := []struct {
+		change      func(cfg *Config)
+		errContains string
+	}{
+		{
+			change:      func(cfg *Config) { cfg.AuthorizationServers = nil },
+			errContains: "OAUTH_AUTHORIZATION_SERVERS",
+		},
+		{
+			change:      func(cfg *Config) { cfg.Addr = ":9999" },
+			errContains: "SERVER_ADDR cannot change without a restart",
+		},
+	}
+	for _, tt := range rejected {
+		update(func(cfg *Config) {
+			cfg.AuthorizationServers = []string{as.Issuer()}
+			cfg.Addr = initial.Addr
+			tt.change(cfg)
+		})
+		if err := srv.Reload(context.Background()); err == nil || !strings.Contains(err.Error(), tt.errContains) {
+			t.Errorf("Reload() error = %v, want error containing %q", err, tt.errContains)
+		}
+		if resp, _ := postMCP(t, ts, newAudience, "", initialize); resp.StatusCode != http.StatusOK {
+			t.Errorf("status after rejected reload = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	// The outcomes are published at /admin/metrics
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/admin/metrics", nil)
+	req.Header.Set("Authorization", "Bearer "+as.Token().Audience("http://localhost:8080/v2").Scopes("mcp:read", "mcp:admin").MustSign(t))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /admin/metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var metrics struct {
+		Cmdline       []string `json:"cmdline"`
+		ConfigReloads struct {
+			Success   int64  `json:"success"`
+			Failure   int64  `json:"failure"`
+			LastError string `json:"last_error"`
+		} `json:"config_reloads"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		t.Fatalf("failed to decode metrics: %v", err)
+	}
+	if metrics.ConfigReloads.Success < 1 || metrics.ConfigReloads.Failure < 2 || metrics.ConfigReloads.LastError == "" {
+		t.Errorf("config_reloads = %+v, want successes and failures", metrics.ConfigReloads)
+	}
+	if metrics.Cmdline != nil {
+		t.Errorf("metrics include cmdline %v, want only config_reloads", metrics.Cmdline)
+	}
+}
+
+func TestServer_ReloadWithoutLoader(t *testing.T) {
+	t.Parallel()
+
+	srv, err := New(
+		WithBaseURL(testBaseURL),
+		WithAuthorizationServers("https://auth.example.com"),
+		WithAudience(testBaseURL),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := srv.Reload(context.Background()); err == nil || !strings.Contains(err.Error(), "WithConfigLoader") {
+		t.Errorf("Reload() error = %v, want error mentioning WithConfigLoader", err)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	t.Parallel()
+
+	current := DefaultConfig()
+	current.Audience = testBaseURL
+
+	tests := []struct {
+		name   string
+		dev    bool
+		change func(cfg *Config)
+		want   []string
+	}{
+		{name: "unchanged", change: func(cfg *Config) {}},
+		{name: "audience", change: func(cfg *Config) { cfg.Audience = testBaseURL + "/v2" }},
+		{name: "audience with the dev server", dev: true, change: func(cfg *Config) { cfg.Audience = testBaseURL + "/v2" }, want: []string{"OAUTH_AUDIENCE"}},
+		{name: "dev server", change: func(cfg *Config) { cfg.DevServer.Enabled = true }, want: []string{"OAUTH_DEV_SERVER"}},
+		{name: "listener", change: func(cfg *Config) { cfg.Addr = ":9999" }, want: []string{"SERVER_ADDR"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			before := *current
+			before.DevServer.Enabled = tt.dev
+			after := before
+			tt.change(&after)
+
+			if got := restartRequired(&before, &after); !slices.Equal(got, tt.want) {
+				t.Errorf("restartRequired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
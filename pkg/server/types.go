@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"flag"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/config"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/mcp"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport"
+)
+
+// Re-export the types needed to configure a server and implement tools,
+// resources and prompts, so programs outside this module never import internal packages.
+
+// Config holds the complete server configuration.
+type Config = config.Config
+
+// HTTPClientConfig configures the outbound HTTP client used for
+// authorization server discovery and JWKS fetches.
+type HTTPClientConfig = config.HTTPClientConfig
+
+// ProtectedResourceConfig describes an additional protected resource.
+type ProtectedResourceConfig = config.ProtectedResourceConfig
+
+// ResourceMetadataConfig holds the optional RFC 9728 metadata parameters.
+type ResourceMetadataConfig = config.ResourceMetadataConfig
+
+// DevServerConfig configures the embedded development authorization server.
+type DevServerConfig = config.DevServerConfig
+
+// Tool is an executable MCP tool.
+type Tool = mcp.Tool
+
+// ScopedTool is a Tool that declares the OAuth scopes a caller needs.
+type ScopedTool = mcp.ScopedTool
+
+// ToolDefinition describes a tool for client discovery.
+type ToolDefinition = mcp.ToolDefinition
+
+// ToolRegistry manages the tools of a server.
+type ToolRegistry = mcp.ToolRegistry
+
+// ToolsCallResult is the complete result of a tool call, which tools may
+// return from Execute.
+type ToolsCallResult = mcp.ToolsCallResult
+
+// Content is a content block of a tool result or prompt message.
+type Content = mcp.Content
+
+// ResourceContent is the content of a resource embedded in a tool result.
+type ResourceContent = mcp.ResourceContent
+
+// ResourceProvider provides access to an MCP resource.
+type ResourceProvider = mcp.ResourceProvider
+
+// ScopedResourceProvider is a ResourceProvider that declares the OAuth
+// scopes a caller needs.
+type ScopedResourceProvider = mcp.ScopedResourceProvider
+
+// Resource is the content of an MCP resource.
+type Resource = mcp.Resource
+
+// ResourceDefinition describes a resource for client discovery.
+type ResourceDefinition = mcp.ResourceDefinition
+
+// ResourceTemplateProvider provides access to the resources matching an
+// RFC 6570 URI template.
+type ResourceTemplateProvider = mcp.ResourceTemplateProvider
+
+// ScopedResourceTemplateProvider is a ResourceTemplateProvider that declares
+// the OAuth scopes a caller needs.
+type ScopedResourceTemplateProvider = mcp.ScopedResourceTemplateProvider
+
+// ResourceTemplateDefinition describes a resource template for client discovery.
+type ResourceTemplateDefinition = mcp.ResourceTemplateDefinition
+
+// ResourceUpdateSource is implemented by resource providers that signal
+// changes to their content, which are notified to subscribed clients.
+type ResourceUpdateSource = mcp.ResourceUpdateSource
+
+// ErrResourceNotFound is returned, possibly wrapped, by a
+// ResourceTemplateProvider when no resource exists at a matching URI.
+var ErrResourceNotFound = mcp.ErrResourceNotFound
+
+// ResourceRegistry manages the resources of a server.
+type ResourceRegistry = mcp.ResourceRegistry
+
+// Prompt is a reusable prompt template offered to clients.
+type Prompt = mcp.Prompt
+
+// ScopedPrompt is a Prompt that declares the OAuth scopes a caller needs.
+type ScopedPrompt = mcp.ScopedPrompt
+
+// PromptDefinition describes a prompt for client discovery.
+type PromptDefinition = mcp.PromptDefinition
+
+// PromptArgument describes an argument of a prompt.
+type PromptArgument = mcp.PromptArgument
+
+// ArgumentType is the type a prompt argument is converted to.
+type ArgumentType = mcp.ArgumentType
+
+// Prompt argument types.
+const (
+	ArgumentString  = mcp.ArgumentString
+	ArgumentNumber  = mcp.ArgumentNumber
+	ArgumentInteger = mcp.ArgumentInteger
+	ArgumentBoolean = mcp.ArgumentBoolean
+)
+
+// PromptResult is the rendered messages of a prompt.
+type PromptResult = mcp.PromptResult
+
+// PromptMessage is a message of a rendered prompt.
+type PromptMessage = mcp.PromptMessage
+
+// PromptMessageTemplate is a message of a prompt created by
+// NewTemplatePrompt.
+type PromptMessageTemplate = mcp.PromptMessageTemplate
+
+// PromptRegistry manages the prompts of a server.
+type PromptRegistry = mcp.PromptRegistry
+
+// Session is the MCP session of a request, holding the negotiated protocol
+// version and the client's capabilities.
+type Session = mcp.Session
+
+// Features describes the behaviour that differs between protocol
+// revisions, as negotiated for a session.
+type Features = mcp.Features
+
+// Middleware wraps an http.Handler.
+type Middleware = transport.Middleware
+
+// TokenValidator validates access tokens.
+type TokenValidator = oauth.TokenValidator
+
+// TokenClaims are the validated claims of an access token.
+type TokenClaims = oauth.TokenClaims
+
+// JWKSInspector exposes the per-issuer key set state of the JWKS client.
+type JWKSInspector = oauth.JWKSInspector
+
+// JWKSIssuerStatus is the key set state of one authorization server.
+type JWKSIssuerStatus = oauth.JWKSIssuerStatus
+
+// DefaultConfig returns a configuration holding the defaults applied by
+// LoadConfig. BaseURL, AuthorizationServers and Audience must be set.
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// LoadConfig reads and validates the configuration from the SERVER_*,
+// OAUTH_* and MCP_* environment variables.
+func LoadConfig() (*Config, error) {
+	return config.Load()
+}
+
+// LoadConfigFile reads and validates the configuration from a YAML or JSON
+// file, overridden by environment variables.
+func LoadConfigFile(path string) (*Config, error) {
+	return config.LoadFile(path)
+}
+
+// Flags are the configuration command-line flags registered by
+// RegisterFlags.
+type Flags = config.Flags
+
+// RegisterFlags defines the -config flag and the configuration override
+// flags on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	return config.RegisterFlags(fs)
+}
+
+// LoadConfigFlags reads and validates the configuration from the -config
+// file, environment variables and flags, as cmd/server does. fs must be
+// parsed first.
+func LoadConfigFlags(flags *Flags) (*Config, error) {
+	return config.LoadFlags(flags)
+}
+
+// ClaimsFromContext returns the claims of the access token that
+// authenticated the request. Tools and resources receive it in the context
+// passed to Execute and Read.
+func ClaimsFromContext(ctx context.Context) (*TokenClaims, bool) {
+	return transport.ClaimsFromContext(ctx)
+}
+
+// SessionFromContext returns the MCP session of the request a tool or
+// resource is handling.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	return mcp.SessionFromContext(ctx)
+}
+
+// Notify sends a notification to the client while a tool or resource
+// handles a request. It is delivered on the event stream answering the
+// request, or on the session's standalone stream.
+func Notify(ctx context.Context, method string, params any) error {
+	return mcp.Notify(ctx, method, params)
+}
+
+// ReportProgress sends a progress notification for the request being
+// handled if the client asked for progress. Total is zero when unknown.
+func ReportProgress(ctx context.Context, progress, total float64, message string) error {
+	return mcp.ReportProgress(ctx, progress, total, message)
+}
+
+// NewTemplatePrompt creates a prompt rendering each message template with
+// the prompt's arguments. Callers need all of scopes to use the prompt.
+func NewTemplatePrompt(definition PromptDefinition, scopes []string, messages ...PromptMessageTemplate) (ScopedPrompt, error) {
+	return mcp.NewTemplatePrompt(definition, scopes, messages...)
+}
+
+// NewTextContent returns a text content block.
+func NewTextContent(text string) Content {
+	return mcp.NewTextContent(text)
+}
+
+// NewImageContent returns an image content block of the given MIME type.
+func NewImageContent(data []byte, mimeType string) Content {
+	return mcp.NewImageContent(data, mimeType)
+}
+
+// NewAudioContent returns an audio content block of the given MIME type.
+func NewAudioContent(data []byte, mimeType string) Content {
+	return mcp.NewAudioContent(data, mimeType)
+}
+
+// NewResourceContent returns a content block embedding a resource.
+func NewResourceContent(resource ResourceContent) Content {
+	return mcp.NewResourceContent(resource)
+}
+
+// NewResourceLinkContent returns a content block linking to a resource.
+func NewResourceLinkContent(definition ResourceDefinition) Content {
+	return mcp.NewResourceLinkContent(definition)
+}
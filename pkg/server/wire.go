@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/jamesprial/mcp-oauth-2.1/internal/config"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/oauth"
+	"github.com/jamesprial/mcp-oauth-2.1/internal/transport"
+)
+
+// NewJWKSInspector builds the JWKS client a server with cfg would use, for
+// diagnosing key set fetches without starting the server.
+func NewJWKSInspector(cfg *Config) (JWKSInspector, error) {
+	oauthCfg, err := newOAuthConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure oauth: %w", err)
+	}
+
+	inspector, ok := oauth.NewJWKSClient(oauthCfg).(oauth.JWKSInspector)
+	if !ok {
+		return nil, fmt.Errorf("jwks client does not support inspection")
+	}
+	return inspector, nil
+}
+
+// newServices builds the OAuth services for cfg. The validator of
+// WithTokenValidator replaces the JWKS-based one of the primary resource.
+// Invalid metadata is rejected rather than served.
+func newServices(cfg *config.Config, o *options, scopeProviders []oauth.ScopeProvider) (*oauth.Services, *oauth.Config, error) {
+	oauthCfg, err := newOAuthConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure oauth: %w", err)
+	}
+	oauthCfg.ResourceMetadata.ScopeProviders = scopeProviders
+
+	tokenValidator, metadataService, _, jwksClient := oauth.NewOAuthServices(oauthCfg)
+	if o.validator != nil {
+		tokenValidator = o.validator
+	}
+
+	resourceMetadataServices, resourceValidators := newResourceServices(cfg, oauthCfg, jwksClient)
+	services := &oauth.Services{
+		TokenValidator:           tokenValidator,
+		MetadataService:          metadataService,
+		ResourceMetadataServices: resourceMetadataServices,
+		ResourceTokenValidators:  resourceValidators,
+		JWKSClient:               jwksClient,
+	}
+
+	for _, service := range append([]oauth.MetadataService{metadataService}, services.ResourceMetadataServices...) {
+		resourceMetadata, err := service.GetMetadata(context.Background())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build protected resource metadata: %w", err)
+		}
+		if err := oauth.ValidateMetadata(resourceMetadata); err != nil {
+			return nil, nil, fmt.Errorf("invalid protected resource metadata for %s: %w", resourceMetadata.Resource, err)
+		}
+	}
+
+	return services, oauthCfg, nil
+}
+
+// newOAuthConfig builds the OAuth wiring configuration, loading the CA bundles,
+// client certificates and proxy settings referenced by cfg.
+func newOAuthConfig(cfg *config.Config) (*oauth.Config, error) {
+	oauthCfg := &oauth.Config{
+		BaseURL:              cfg.BaseURL,
+		AuthorizationServers: cfg.AuthorizationServers,
+		Audience:             cfg.Audience,
+		ScopesSupported:      cfg.ScopesSupported,
+		JWKSCacheTTL:         cfg.JWKSCacheTTL,
+		ClockSkew:            cfg.ClockSkew,
+		RequireX5C:           cfg.JWKSRequireX5C,
+		JWKSCacheFile:        cfg.JWKSCacheFile,
+		JWKSCacheKey:         []byte(cfg.JWKSCacheKey),
+		JWKSCacheMaxStale:    cfg.JWKSCacheMaxStale,
+		ResourceMetadata: oauth.ResourceMetadataOptions{
+			ResourceName:                          cfg.ResourceMetadata.Name,
+			ResourceDocumentation:                 cfg.ResourceMetadata.DocumentationURL,
+			ResourcePolicyURI:                     cfg.ResourceMetadata.PolicyURI,
+			ResourceTOSURI:                        cfg.ResourceMetadata.TOSURI,
+			JWKSURI:                               cfg.ResourceMetadata.JWKSURI,
+			ResourceSigningAlgValuesSupported:     cfg.ResourceMetadata.SigningAlgValuesSupported,
+			AuthorizationDetailsTypesSupported:    cfg.ResourceMetadata.AuthorizationDetailsTypesSupported,
+			TLSClientCertificateBoundAccessTokens: cfg.ResourceMetadata.TLSClientCertificateBoundAccessTokens,
+			DPoPSigningAlgValuesSupported:         cfg.ResourceMetadata.DPoPSigningAlgValuesSupported,
+			DPoPBoundAccessTokensRequired:         cfg.ResourceMetadata.DPoPBoundAccessTokensRequired,
+			Extensions:                            cfg.ResourceMetadata.Extensions,
+		},
+	}
+
+	if cfg.JWKSX5CCAFile != "" {
+		roots, err := oauth.LoadCertPool(cfg.JWKSX5CCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load x5c CA bundle: %w", err)
+		}
+		oauthCfg.X5CRoots = roots
+	}
+
+	if cfg.ResourceMetadata.SigningKeyFile != "" {
+		signingKey, err := oauth.LoadMetadataSigningKey(cfg.ResourceMetadata.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load metadata signing key: %w", err)
+		}
+		oauthCfg.ResourceMetadata.SigningKey = signingKey
+
+		// Reference the key set this server publishes unless another is configured
+		oauthCfg.ResourceMetadata.JWKSURI = cfg.ResourceJWKSURI()
+
+		slog.Info("protected resource metadata signing enabled",
+			"kid", signingKey.KeyID,
+			"alg", signingKey.Algorithm,
+			"jwks_uri", oauthCfg.ResourceMetadata.JWKSURI,
+		)
+	}
+
+	if cfg.JWKSCacheFile != "" && cfg.JWKSCacheKey == "" {
+		slog.Warn("JWKS cache file is not authenticated, set OAUTH_JWKS_CACHE_KEY",
+			"path", cfg.JWKSCacheFile,
+		)
+	}
+
+	httpCfg, err := outboundHTTPConfig(cfg.OutboundHTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure outbound HTTP client: %w", err)
+	}
+	oauthCfg.HTTPClient = httpCfg
+
+	if len(cfg.AuthorizationServerHTTP) > 0 {
+		oauthCfg.AuthorizationServerHTTP = make(map[string]*oauth.HTTPClientConfig, len(cfg.AuthorizationServerHTTP))
+		for serverURL, serverHTTP := range cfg.AuthorizationServerHTTP {
+			httpCfg, err := outboundHTTPConfig(serverHTTP)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure HTTP client for %s: %w", serverURL, err)
+			}
+			oauthCfg.AuthorizationServerHTTP[serverURL] = httpCfg
+		}
+	}
+
+	return oauthCfg, nil
+}
+
+// newDevAuthorizationServer builds the embedded development authorization
+// server. It issues tokens for the primary resource's audience and accepts
+// the scopes the resource advertises.
+func newDevAuthorizationServer(cfg *config.Config, metadataService oauth.MetadataService) (http.Handler, error) {
+	resourceMetadata, err := metadataService.GetMetadata(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build protected resource metadata: %w", err)
+	}
+
+	devCfg := &oauth.DevServerConfig{
+		Issuer:          cfg.DevServerIssuer(),
+		Audience:        cfg.Audience,
+		User:            cfg.DevServer.User,
+		ScopesSupported: resourceMetadata.ScopesSupported,
+		DefaultScopes:   transport.DefaultScopes(),
+		AccessTokenTTL:  cfg.DevServer.TokenTTL,
+	}
+	if cfg.DevServer.SigningKeyFile != "" {
+		signingKey, err := oauth.LoadMetadataSigningKey(cfg.DevServer.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dev authorization server signing key: %w", err)
+		}
+		devCfg.SigningKey = signingKey
+	}
+
+	handler, err := oauth.NewDevAuthorizationServer(devCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Warn("development authorization server enabled: every authorization request is approved, do not use in production",
+		"issuer", devCfg.Issuer,
+		"user", devCfg.User,
+	)
+
+	return handler, nil
+}
+
+// newResourceServices builds the metadata service and token validator of each
+// additional protected resource. Unset values are inherited from the primary
+// resource. Each validator only accepts tokens whose audience is the
+// resource identifier; a resource naming its own authorization servers gets
+// a JWKS client trusting only their keys, others share jwksClient.
+func newResourceServices(cfg *config.Config, oauthCfg *oauth.Config, jwksClient oauth.JWKSClient) ([]oauth.MetadataService, []oauth.TokenValidator) {
+	services := make([]oauth.MetadataService, 0, len(cfg.ProtectedResources))
+	validators := make([]oauth.TokenValidator, 0, len(cfg.ProtectedResources))
+	for _, resource := range cfg.ProtectedResources {
+		slog.Info("hosting additional protected resource",
+			"resource", resource.Resource,
+		)
+
+		resourceCfg := *oauthCfg
+		resourceCfg.BaseURL = resource.Resource
+		resourceCfg.Audience = resource.Resource
+		if len(resource.ScopesSupported) > 0 {
+			resourceCfg.ScopesSupported = resource.ScopesSupported
+		}
+		if resource.Name != "" {
+			resourceCfg.ResourceMetadata.ResourceName = resource.Name
+		}
+
+		resourceJWKS := jwksClient
+		if len(resource.AuthorizationServers) > 0 {
+			resourceCfg.AuthorizationServers = resource.AuthorizationServers
+			// The primary client owns the key set cache file
+			resourceCfg.JWKSCacheFile = ""
+			resourceJWKS = oauth.NewJWKSClient(&resourceCfg)
+		}
+
+		services = append(services, oauth.NewMetadataService(&resourceCfg))
+		validators = append(validators, oauth.NewTokenValidator(&resourceCfg, resourceJWKS))
+	}
+	return services, validators
+}
+
+// outboundHTTPConfig loads the CA bundle, client certificate and proxy
+// settings referenced by an HTTP client configuration.
+func outboundHTTPConfig(c config.HTTPClientConfig) (*oauth.HTTPClientConfig, error) {
+	httpCfg := &oauth.HTTPClientConfig{
+		Timeout:              c.Timeout,
+		MaxBodyBytes:         c.MaxBodyBytes,
+		MaxRedirects:         c.MaxRedirects,
+		AllowPrivateNetworks: c.AllowPrivateNetworks,
+		UserAgent:            c.UserAgent,
+	}
+
+	if c.CAFile != "" {
+		roots, err := oauth.LoadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		httpCfg.RootCAs = roots
+	}
+
+	if c.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		httpCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch c.ProxyURL {
+	case "":
+	case "direct":
+		httpCfg.DisableProxy = true
+	default:
+		proxy, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		httpCfg.Proxy = proxy
+	}
+
+	return httpCfg, nil
+}